@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"math/bits"
+	"time"
+)
+
+const doorkeeperHashes = 4
+
+// doorkeeper is a Bloom filter of the keys offered to a full cache during the
+// current window. A new key is only admitted once the filter has seen it, so
+// keys written once per window never displace established entries.
+type doorkeeper struct {
+	keys   int
+	window int64
+	bits   []uint64
+	mask   uint64
+	until  int64
+}
+
+// newDoorkeeper sizes the filter at about 10 bits per key for keys distinct
+// keys per window, a false positive rate of around one percent.
+func newDoorkeeper(keys int, window time.Duration) *doorkeeper {
+	if keys < 64 {
+		keys = 64
+	}
+	n := uint64(1) << bits.Len64(uint64(keys)*10-1)
+	return &doorkeeper{
+		keys:   keys,
+		window: int64(window),
+		bits:   make([]uint64, n/64),
+		mask:   n - 1,
+	}
+}
+
+func (d *doorkeeper) clone() *doorkeeper {
+	return newDoorkeeper(d.keys, time.Duration(d.window))
+}
+
+// seen records hash and reports whether it had been recorded before in the
+// current window.
+func (d *doorkeeper) seen(hash uint64, now int64) bool {
+	if now >= d.until {
+		clear(d.bits)
+		d.until = now + d.window
+	}
+	// Key hashes may come from a user function, so spread them first.
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	h1, h2 := hash, hash>>32|1
+	seen := true
+	for i := range uint64(doorkeeperHashes) {
+		b := (h1 + i*h2) & d.mask
+		if d.bits[b/64]&(1<<(b%64)) == 0 {
+			seen = false
+			d.bits[b/64] |= 1 << (b % 64)
+		}
+	}
+	return seen
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheAdmissionFilter(t *testing.T) {
+	cache := NewCache[string, int](time.Minute,
+		WithMaxEntries[string, int](2),
+		WithAdmissionFilter[string, int](1000, time.Minute),
+	)
+	defer cache.StopCleanup()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("once", 3)
+	_, found := cache.Get("once")
+	assert.False(t, found, "Expected keys seen once not to be admitted to a full cache")
+	for _, key := range []string{"a", "b"} {
+		_, found := cache.Get(key)
+		assert.True(t, found, key)
+	}
+
+	assert.Equal(t, uint64(1), cache.Stats().Rejections)
+	assert.Zero(t, cache.Stats().Evictions, "Expected rejected keys not to count as evictions")
+
+	cache.Set("twice", 4)
+	cache.Set("twice", 4)
+	_, found = cache.Get("twice")
+	assert.True(t, found, "Expected keys seen twice to be admitted")
+	_, found = cache.Get("a")
+	assert.False(t, found)
+
+	cache.Set("b", 5)
+	value, _ := cache.Get("b")
+	assert.Equal(t, 5, value, "Expected cached keys to be updated freely")
+}
+
+func TestDoorkeeperWindow(t *testing.T) {
+	d := newDoorkeeper(100, time.Second)
+	assert.False(t, d.seen(42, 0))
+	assert.True(t, d.seen(42, int64(time.Millisecond)))
+	assert.False(t, d.seen(7, int64(time.Millisecond)))
+	assert.False(t, d.seen(42, int64(time.Second)), "Expected the filter to forget keys after the window")
+}
+
+func TestCacheAdmissionFilterSilent(t *testing.T) {
+	cache := NewCache[string, int](time.Minute,
+		WithMaxEntries[string, int](1),
+		WithAdmissionFilter[string, int](1000, time.Minute),
+	)
+	defer cache.StopCleanup()
+	cache.Set("a", 1)
+
+	var sets []string
+	cache.OnSet(func(key string, _ int) { sets = append(sets, key) }, HookSync)
+	events, cancel := cache.Subscribe(nil, SubscribeConfig{})
+	defer cancel()
+	cache.Set("once", 2)
+	assert.Empty(t, sets, "Expected rejected writes not to call OnSet")
+	select {
+	case ev := <-events:
+		t.Fatalf("Expected no event for a rejected write, got %v", ev)
+	default:
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Reads on a hit must not allocate, whatever the store and the bookkeeping
+// options; these tests keep it that way.
+
+func TestCacheGetDoesNotAllocate(t *testing.T) {
+	key := strconv.Itoa(12345) // not a constant, so converting it would allocate
+	for name, opts := range map[string][]Option[string, []byte]{
+		"default":    nil,
+		"syncmap":    {WithStore[string, []byte](NewSyncMapStore[string, []byte])},
+		"sharded":    {WithStore[string, []byte](func() Store[string, []byte] { return NewShardedMapStore[string, []byte](8) })},
+		"shards":     {WithExpiryShards[string, []byte](4)},
+		"exact time": {WithExactTime[string, []byte]()},
+		"stale":      {WithStaleOnError[string, []byte](time.Minute)},
+		"tracking":   {WithAccessTracking[string, []byte]()},
+		"sampling":   {WithAccessSampling[string, []byte](8)},
+		"ttl":        {WithTTLAnalysis[string, []byte](1)},
+		"lru":        {WithMaxEntries[string, []byte](10)},
+		"clock":      {WithMaxEntries[string, []byte](10), WithEvictionPolicy[string, []byte](EvictClock)},
+		"slru":       {WithMaxEntries[string, []byte](10), WithEvictionPolicy[string, []byte](EvictSLRU)},
+		"sampled":    {WithMaxEntries[string, []byte](10), WithEvictionPolicy[string, []byte](EvictSampled)},
+		"validate":   {WithValidateOnRead[string, []byte](), WithValidator[string, []byte](func([]byte) error { return nil })},
+	} {
+		cache := NewCache[string, []byte](time.Minute, opts...)
+		cache.Set(key, []byte("value"))
+		assert.NoError(t, cache.SetWithTTL("explicit", []byte("value"), time.Minute))
+
+		assert.Zero(t, testing.AllocsPerRun(100, func() { cache.Get(key) }), name)
+		assert.Zero(t, testing.AllocsPerRun(100, func() { cache.Get("explicit") }), name)
+		assert.Zero(t, testing.AllocsPerRun(100, func() { cache.Get("missing") }), name)
+		cache.StopCleanup()
+	}
+}
+
+func TestCacheReadsDoNotAllocate(t *testing.T) {
+	cache := NewCacheComparable[[2]int, any](time.Minute, func(k [2]int) uintptr { return uintptr(k[0] ^ k[1]) })
+	defer cache.StopCleanup()
+	key := [2]int{1, 2}
+	cache.Set(key, 42)
+	loader := func([2]int) (any, error) { return nil, nil }
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() { cache.Get(key) }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { cache.GetOrLoad(key, loader) }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { cache.GetVersioned(key) }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { cache.Namespace("ns").Get(key) }))
+}
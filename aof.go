@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type FsyncPolicy uint8
+
+const (
+	FsyncEverySecond FsyncPolicy = iota
+	FsyncAlways
+	FsyncNever
+)
+
+type AOFConfig struct {
+	Fsync FsyncPolicy
+	// CompactInterval controls how often the log is rewritten from the
+	// current cache contents. Zero disables periodic compaction; the log is
+	// still compacted once on startup after replay.
+	CompactInterval time.Duration
+}
+
+const (
+	aofSet uint8 = iota + 1
+	aofDelete
+	aofClear
+)
+
+type aofRecord[T comparable] struct {
+	Op       uint8
+	Key      T
+	Value    []byte
+	Deadline int64
+}
+
+// appendLog is an append-only log of Set/Delete/Clear operations. The file is
+// always written by a single gob encoder: replay is followed by a compaction
+// that starts a fresh file, so appends never mix encoder streams.
+type appendLog[T comparable, V any] struct {
+	path string
+	cfg  AOFConfig
+	mu   sync.Mutex
+	f    *os.File
+	// w is what enc writes to: f, or f sealed by WithEncryption.
+	w       io.Writer
+	enc     *gob.Encoder
+	dirty   bool
+	lastErr atomic.Pointer[error]
+	// failing reports whether the latest write or sync failed.
+	failing atomic.Bool
+}
+
+func (a *appendLog[T, V]) record(err error) {
+	a.failing.Store(err != nil)
+	if err != nil {
+		a.lastErr.Store(&err)
+	}
+}
+
+func (a *appendLog[T, V]) append(rec aofRecord[T]) {
+	if a.enc == nil {
+		return
+	}
+	if err := a.enc.Encode(rec); err != nil {
+		a.record(err)
+		return
+	}
+	if a.cfg.Fsync == FsyncAlways {
+		a.record(a.f.Sync())
+		return
+	}
+	a.dirty = true
+}
+
+// logged applies fn and appends an op record for key to the log and the
+// replication stream as one step, so their order matches the order in which
+// operations hit the cache. value and ttl, zero meaning the cache's TTL, are
+// only recorded for aofSet. The operation is then handed to the Replicator,
+// if one is attached.
+func (c *Cache[T, V]) logged(op uint8, key T, value V, ttl time.Duration, fn func()) {
+	c.loggedIf(op, key, value, ttl, func() bool {
+		fn()
+		return true
+	})
+}
+
+// loggedIf is logged for operations that may turn out to change nothing:
+// the op is only recorded and replicated if fn reports that it applied.
+func (c *Cache[T, V]) loggedIf(op uint8, key T, value V, ttl time.Duration, fn func() bool) bool {
+	var recs []aofRecord[T]
+	if c.aof != nil || c.stream != nil {
+		if rec, ok := c.aofRecord(op, key, value, ttl); ok {
+			recs = []aofRecord[T]{rec}
+		}
+	}
+	if !c.logAll(recs, fn) {
+		return false
+	}
+	c.replicate(op, key)
+	return true
+}
+
+// aofRecord builds the record of an operation, reporting false if the value
+// cannot be encoded.
+func (c *Cache[T, V]) aofRecord(op uint8, key T, value V, ttl time.Duration) (aofRecord[T], bool) {
+	rec := aofRecord[T]{Op: op, Key: key}
+	if op != aofSet {
+		return rec, true
+	}
+	data, err := c.valueCodec().Encode(value)
+	if err != nil {
+		c.stats.codecErrors.Add(1)
+		if c.aof != nil {
+			c.aof.record(err)
+		}
+		return rec, false
+	}
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+	rec.Value, rec.Deadline = data, c.wallNow().Add(ttl).UnixNano()
+	return rec, true
+}
+
+// logAll applies fn and, if it reports that it applied, appends recs to the
+// log and the replication stream under the same locks. It returns what fn
+// returned.
+func (c *Cache[T, V]) logAll(recs []aofRecord[T], fn func() bool) bool {
+	if c.aof == nil && c.stream == nil {
+		return fn()
+	}
+	if c.aof != nil {
+		c.aof.mu.Lock()
+		defer c.aof.mu.Unlock()
+	}
+	if c.stream != nil {
+		c.stream.mu.Lock()
+		defer c.stream.mu.Unlock()
+	}
+	if !fn() {
+		return false
+	}
+	for _, rec := range recs {
+		if c.aof != nil {
+			c.aof.append(rec)
+		}
+		if c.stream != nil {
+			c.stream.broadcast(rec)
+		}
+	}
+	return true
+}
+
+func (c *Cache[T, V]) replayAOF() error {
+	f, err := os.Open(c.aof.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := gob.NewDecoder(c.unsealed(f))
+	codec := c.valueCodec()
+	now := c.wallNow()
+	for {
+		var rec aofRecord[T]
+		if err := dec.Decode(&rec); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				// A torn final record is expected after a crash.
+				return nil
+			}
+			return err
+		}
+		if err := c.apply(rec, codec, now); err != nil {
+			return err
+		}
+	}
+}
+
+// apply replays a logged operation.
+func (c *Cache[T, V]) apply(rec aofRecord[T], codec Codec[V], now time.Time) error {
+	switch rec.Op {
+	case aofSet:
+		value, err := codec.Decode(rec.Value)
+		if err != nil {
+			return err
+		}
+		c.restore(rec.Key, value, time.Unix(0, rec.Deadline).Sub(now))
+	case aofDelete:
+		c.remove(rec.Key, nil)
+	case aofClear:
+		c.clear()
+	}
+	return nil
+}
+
+// compactAOF rewrites the log from the current cache contents and switches
+// appends over to the new file.
+func (c *Cache[T, V]) compactAOF() error {
+	a := c.aof
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.CreateTemp(filepath.Dir(a.path), filepath.Base(a.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	w := c.sealed(f)
+	enc := gob.NewEncoder(w)
+	codec := c.valueCodec()
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		value, ok := c.value(item)
+		if !ok {
+			return true
+		}
+		var data []byte
+		if data, err = codec.Encode(value); err != nil {
+			return false
+		}
+		err = enc.Encode(aofRecord[T]{
+			Op:       aofSet,
+			Key:      key,
+			Value:    data,
+			Deadline: item.ExpiresAt().UnixNano(),
+		})
+		return err == nil
+	})
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), a.path)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if a.f != nil {
+		a.f.Close()
+	}
+	a.f, a.w, a.enc, a.dirty = f, w, enc, false
+	return nil
+}
+
+func (c *Cache[T, V]) runAOF() {
+	a := c.aof
+	var syncC, compactC <-chan time.Time
+	if a.cfg.Fsync == FsyncEverySecond {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		syncC = t.C
+	}
+	if a.cfg.CompactInterval > 0 {
+		t := time.NewTicker(a.cfg.CompactInterval)
+		defer t.Stop()
+		compactC = t.C
+	}
+	for {
+		select {
+		case <-syncC:
+			a.sync()
+		case <-compactC:
+			a.record(c.compactAOF())
+		case <-c.stopCleanup:
+			a.mu.Lock()
+			if a.f != nil {
+				a.record(endSealed(a.w))
+				a.record(a.f.Sync())
+				a.record(a.f.Close())
+				a.f, a.w, a.enc = nil, nil, nil
+			}
+			a.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (a *appendLog[T, V]) sync() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.dirty && a.f != nil {
+		a.record(a.f.Sync())
+		a.dirty = false
+	}
+}
+
+func (c *Cache[T, V]) openAOF() {
+	if err := c.replayAOF(); err != nil {
+		c.aof.record(err)
+	}
+	if err := c.compactAOF(); err != nil {
+		c.aof.record(err)
+	}
+	c.background(c.runAOF)
+}
+
+// LastAOFError returns the most recent error from the append-only log, or nil.
+func (c *Cache[T, V]) LastAOFError() error {
+	if c.aof == nil {
+		return nil
+	}
+	if err := c.aof.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheAOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	src := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{Fsync: FsyncAlways}))
+	src.Set(1, "test1")
+	src.Set(2, "test2")
+	src.Set(1, "test1b")
+	src.Delete(2)
+	assert.NoError(t, src.LastAOFError())
+
+	dst := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{Fsync: FsyncNever}))
+	defer dst.StopCleanup()
+	value, found := dst.Get(1)
+	assert.True(t, found, "Expected set to be replayed")
+	assert.Equal(t, "test1b", value)
+	_, found = dst.Get(2)
+	assert.False(t, found, "Expected delete to be replayed")
+	assert.NoError(t, dst.LastAOFError())
+	src.StopCleanup()
+}
+
+func TestCacheAOFClearAndTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	src := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{Fsync: FsyncAlways}))
+	src.Set(1, "test1")
+	src.Clear()
+	src.Set(2, "test2")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(path, info.Size()-1))
+
+	dst := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{}))
+	defer dst.StopCleanup()
+	_, found := dst.Get(1)
+	assert.False(t, found, "Expected clear to be replayed")
+	_, found = dst.Get(2)
+	assert.False(t, found, "Expected torn record to be ignored")
+	assert.NoError(t, dst.LastAOFError())
+	src.StopCleanup()
+}
+
+func TestCacheAOFCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	src := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{
+		Fsync:           FsyncAlways,
+		CompactInterval: 10 * time.Millisecond,
+	}))
+	for i := 0; i < 100; i++ {
+		src.Set(1, "churn")
+	}
+	before, err := os.Stat(path)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	after, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size(), "Expected compaction to shrink the log")
+
+	src.Set(2, "after")
+	src.StopCleanup()
+	time.Sleep(10 * time.Millisecond)
+
+	dst := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{}))
+	defer dst.StopCleanup()
+	_, found := dst.Get(2)
+	assert.True(t, found, "Expected writes after compaction to be replayed")
+}
+
+func TestCacheAOFSoftDeleteAndGeneration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+	open := func() *Cache[int, string] {
+		return NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{Fsync: FsyncAlways}))
+	}
+
+	src := open()
+	src.Set(1, "one")
+	src.Set(2, "two")
+	src.SoftDelete(1, time.Minute)
+	src.SoftDelete(2, time.Minute)
+	src.Undelete(2)
+	src.StopCleanup()
+
+	dst := open()
+	_, found := dst.Get(1)
+	assert.False(t, found, "Expected SoftDelete to be replayed")
+	value, found := dst.Get(2)
+	assert.True(t, found, "Expected Undelete to be replayed")
+	assert.Equal(t, "two", value)
+
+	g := dst.BeginGeneration()
+	g.Set(3, "three")
+	assert.NoError(t, dst.CommitGeneration(g))
+	assert.ErrorIs(t, dst.CommitGeneration(g), ErrGenerationConflict)
+	dst.StopCleanup()
+
+	dst = open()
+	defer dst.StopCleanup()
+	assert.Equal(t, 1, dst.Len(), "Expected the committed generation to replace the contents")
+	value, _ = dst.Get(3)
+	assert.Equal(t, "three", value)
+	assert.NoError(t, dst.LastAOFError())
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("cache: not found")
+	ErrNoBackend  = errors.New("cache: no backend configured")
+	ErrInvalidTTL = errors.New("cache: ttl must be positive")
+)
+
+// Backend is the system of record a cache can front. Load returns ErrNotFound
+// for keys the backend does not hold.
+type Backend[T comparable, V any] interface {
+	Load(key T) (V, error)
+	Store(key T, value V) error
+	Delete(key T) error
+}
+
+// Fetch returns the cached value for key, loading it from the backend on a miss.
+func (c *Cache[T, V]) Fetch(key T) (V, error) {
+	if c.backend == nil {
+		var zero V
+		return zero, ErrNoBackend
+	}
+	return c.GetOrLoad(key, c.backend.Load)
+}
+
+// TrySet is Set that reports write-through failures. When the backend rejects
+// the write the cache is left untouched.
+func (c *Cache[T, V]) TrySet(key T, value V) error {
+	if ops := c.middleware.ops.Load(); ops != nil {
+		return ops.Set(key, value)
+	}
+	return c.trySet(key, value)
+}
+
+func (c *Cache[T, V]) trySet(key T, value V) error {
+	return c.trySetEntry(key, value, 0, PriorityNormal)
+}
+
+// SetWithTTL is TrySet with an entry-specific ttl instead of the cache's.
+// Unlike entries with the default TTL, such entries stop being served as soon
+// as they expire rather than when the cleanup routine removes them.
+func (c *Cache[T, V]) SetWithTTL(key T, value V, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return c.trySetEntry(key, value, ttl, PriorityNormal)
+}
+
+// trySetEntry stores value under key, with the cache's TTL if ttl is zero.
+func (c *Cache[T, V]) trySetEntry(key T, value V, ttl time.Duration, priority Priority) error {
+	key = c.canonical(key)
+	if c.buffered(bufferedWrite[T, V]{key: key, value: value, ttl: ttl, priority: priority}) {
+		return nil
+	}
+	return c.applySet(key, value, ttl, priority, nil)
+}
+
+// applySet writes value through and stores it. HookSync hooks are left in
+// pending, or run before it returns if pending is nil.
+func (c *Cache[T, V]) applySet(key T, value V, ttl time.Duration, priority Priority, pending *pendingHooks) error {
+	if pending == nil {
+		var own pendingHooks
+		pending = &own
+		defer own.run()
+	}
+	if c.writeThrough {
+		if err := c.backend.Store(key, value); err != nil {
+			c.stats.backendErrors.Add(1)
+			return err
+		}
+	}
+	c.logged(aofSet, key, value, ttl, func() {
+		c.set(key, value, SourceSet, ttl, priority, pending)
+	})
+	if c.writeBehind != nil {
+		c.writeBehind.enqueue(key, writeOp[V]{value: value})
+	}
+	return nil
+}
+
+// applySetIf is applySet for a write that only lands if the entry under key
+// is still old, as storeIf does. It reports whether it landed. As with
+// applySet, a write through to the backend happens first.
+func (c *Cache[T, V]) applySetIf(key T, value V, old CachedItem[V], pending *pendingHooks) bool {
+	if pending == nil {
+		var own pendingHooks
+		pending = &own
+		defer own.run()
+	}
+	if c.writeThrough {
+		if err := c.backend.Store(key, value); err != nil {
+			c.stats.backendErrors.Add(1)
+			return false
+		}
+	}
+	ok := c.loggedIf(aofSet, key, value, 0, func() bool {
+		_, ok := c.storeIf(key, old, c.newItem(value, SourceSet), pending)
+		return ok
+	})
+	if ok && c.writeBehind != nil {
+		c.writeBehind.enqueue(key, writeOp[V]{value: value})
+	}
+	return ok
+}
+
+// remove deletes key from the store, fetching the old item only when a
+// subscriber wants to hear about it, and then the entries depending on it.
+func (c *Cache[T, V]) remove(key T, pending *pendingHooks) {
+	defer c.invalidateDependents(key, pending)
+	c.writes.Add(1)
+	defer c.writes.Add(1)
+	defer c.forget(key)
+	c.trace(TraceDelete, key)
+	if c.spiller != nil {
+		c.unspill(key)
+	}
+	if !c.watched() {
+		c.items().Del(key)
+		return
+	}
+	if item, ok := c.items().GetAndDel(key); ok {
+		c.notify(EventDelete, key, item, pending)
+	}
+}
+
+// removeIf is remove for a deletion that only lands if the entry under key
+// is still old, reporting whether it did.
+func (c *Cache[T, V]) removeIf(key T, old CachedItem[V], pending *pendingHooks) bool {
+	c.writes.Add(1)
+	ok := c.items().CompareAndDelete(key, old.version)
+	c.writes.Add(1)
+	if !ok {
+		return false
+	}
+	c.trace(TraceDelete, key)
+	if c.spiller != nil {
+		c.unspill(key)
+	}
+	c.forget(key)
+	c.notify(EventDelete, key, old, pending)
+	c.invalidateDependents(key, pending)
+	return true
+}
+
+// TryDelete is Delete that reports write-through failures. The entry is
+// removed from the cache even if the backend fails, so stale data is never
+// served.
+func (c *Cache[T, V]) TryDelete(key T) error {
+	if ops := c.middleware.ops.Load(); ops != nil {
+		return ops.Delete(key)
+	}
+	return c.tryDelete(key)
+}
+
+func (c *Cache[T, V]) tryDelete(key T) error {
+	key = c.canonical(key)
+	if c.buffered(bufferedWrite[T, V]{key: key, del: true}) {
+		return nil
+	}
+	return c.applyDelete(key, nil)
+}
+
+// applyDelete is applySet for a deletion.
+func (c *Cache[T, V]) applyDelete(key T, pending *pendingHooks) error {
+	if pending == nil {
+		var own pendingHooks
+		pending = &own
+		defer own.run()
+	}
+	c.logged(aofDelete, key, *new(V), 0, func() {
+		c.remove(key, pending)
+	})
+	if c.writeThrough {
+		if err := c.backend.Delete(key); err != nil {
+			c.stats.backendErrors.Add(1)
+			return err
+		}
+	}
+	if c.writeBehind != nil {
+		c.writeBehind.enqueue(key, writeOp[V]{del: true})
+	}
+	return nil
+}
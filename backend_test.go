@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapBackend[T comparable, V any] struct {
+	mu   sync.Mutex
+	data map[T]V
+	err  error
+}
+
+func newMapBackend[T comparable, V any]() *mapBackend[T, V] {
+	return &mapBackend[T, V]{data: make(map[T]V)}
+}
+
+func (b *mapBackend[T, V]) Load(key T) (V, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.data[key]
+	if !ok {
+		return v, ErrNotFound
+	}
+	return v, nil
+}
+
+func (b *mapBackend[T, V]) Store(key T, value V) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	b.data[key] = value
+	return nil
+}
+
+func (b *mapBackend[T, V]) Delete(key T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	delete(b.data, key)
+	return nil
+}
+
+func (b *mapBackend[T, V]) get(key T) (V, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.data[key]
+	return v, ok
+}
+
+func TestCacheWriteThrough(t *testing.T) {
+	backend := newMapBackend[int, string]()
+	cache := NewCache[int, string](time.Minute, WithWriteThrough[int, string](backend))
+
+	cache.Set(1, "test1")
+	value, ok := backend.get(1)
+	assert.True(t, ok, "Expected Set to reach the backend")
+	assert.Equal(t, "test1", value)
+
+	cache.Delete(1)
+	_, ok = backend.get(1)
+	assert.False(t, ok, "Expected Delete to reach the backend")
+}
+
+func TestCacheWriteThroughError(t *testing.T) {
+	backend := newMapBackend[int, string]()
+	backend.err = errors.New("backend down")
+	cache := NewCache[int, string](time.Minute, WithWriteThrough[int, string](backend))
+
+	assert.ErrorIs(t, cache.TrySet(1, "test1"), backend.err)
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected failed write not to be cached")
+	assert.Equal(t, uint64(1), cache.Stats().BackendErrors)
+}
+
+func TestCacheFetch(t *testing.T) {
+	backend := newMapBackend[int, string]()
+	backend.data[1] = "stored"
+	cache := NewCache[int, string](time.Minute, WithBackend[int, string](backend))
+
+	value, err := cache.Fetch(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "stored", value)
+
+	_, err = cache.Fetch(2)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewCache[int, string](time.Minute).Fetch(1)
+	assert.ErrorIs(t, err, ErrNoBackend)
+}
+
+func TestCacheSetWithTTL(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithExactTime[string, int]())
+	defer cache.StopCleanup()
+
+	assert.ErrorIs(t, cache.SetWithTTL("k", 1, 0), ErrInvalidTTL)
+	assert.NoError(t, cache.SetWithTTL("k", 1, 20*time.Millisecond))
+	cache.Set("default", 2)
+
+	value, found := cache.Get("k")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	time.Sleep(40 * time.Millisecond)
+	_, found = cache.Get("k")
+	assert.False(t, found, "Expected the entry TTL to be enforced on read")
+	_, found = cache.Get("default")
+	assert.True(t, found)
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+type loadEpoch struct {
+	pending int
+	closed  bool
+	done    chan struct{}
+	prev    *loadEpoch
+}
+
+func (e *loadEpoch) finished() bool {
+	return e.closed && e.pending == 0
+}
+
+// loadBarrier tracks in-flight loads per clear epoch so that a load started
+// before a Clear can neither repopulate the cache nor go unnoticed by
+// ClearAndWait.
+type loadBarrier struct {
+	mu      sync.Mutex
+	current *loadEpoch
+}
+
+func (b *loadBarrier) begin() *loadEpoch {
+	b.mu.Lock()
+	if b.current == nil {
+		b.current = &loadEpoch{done: make(chan struct{})}
+	}
+	e := b.current
+	e.pending++
+	b.mu.Unlock()
+	return e
+}
+
+// end finishes a load started in epoch e. commit runs under the barrier lock
+// only if no Clear happened since the load began, and the HookSync calls it
+// leaves in pending run once the lock is released.
+func (b *loadBarrier) end(e *loadEpoch, commit func(pending *pendingHooks)) {
+	var pending pendingHooks
+	defer pending.run()
+	b.mu.Lock()
+	if e == b.current && commit != nil {
+		commit(&pending)
+	}
+	e.pending--
+	if e.pending == 0 && e.closed {
+		close(e.done)
+	}
+	b.mu.Unlock()
+}
+
+// advance starts a new epoch and returns the previous one, which is done once
+// every load started before the call has finished.
+func (b *loadBarrier) advance() *loadEpoch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	old := b.current
+	if old == nil {
+		old = &loadEpoch{done: make(chan struct{})}
+	}
+	b.current = &loadEpoch{done: make(chan struct{}), prev: old}
+	old.closed = true
+	if old.pending == 0 {
+		close(old.done)
+	}
+	p := old.prev
+	for p != nil && p.finished() {
+		p = p.prev
+	}
+	old.prev = p
+	return old
+}
+
+func (b *loadBarrier) wait(ctx context.Context, e *loadEpoch) error {
+	for ; e != nil; e = b.previous(e) {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *loadBarrier) previous(e *loadEpoch) *loadEpoch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return e.prev
+}
+
+// ClearAndWait clears the cache and blocks until every GetOrLoad call started
+// before the clear has returned, or ctx is done.
+func (c *Cache[T, V]) ClearAndWait(ctx context.Context) error {
+	var epoch *loadEpoch
+	c.logged(aofClear, *new(T), *new(V), 0, func() {
+		epoch = c.clear()
+	})
+	return c.loads.wait(ctx, epoch)
+}
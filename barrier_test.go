@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheClearDiscardsInFlightLoad(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loaded := make(chan struct{})
+
+	go func() {
+		defer close(loaded)
+		value, err := cache.GetOrLoad(1, func(int) (string, error) {
+			close(started)
+			<-release
+			return "stale", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "stale", value)
+	}()
+
+	<-started
+	cache.Clear()
+	close(release)
+	<-loaded
+
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected load started before clear not to repopulate the cache")
+}
+
+func TestCacheClearAndWait(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go cache.GetOrLoad(1, func(int) (string, error) {
+		close(started)
+		<-release
+		return "stale", nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cache.ClearAndWait(ctx), context.DeadlineExceeded)
+
+	waited := make(chan error)
+	go func() {
+		waited <- cache.ClearAndWait(context.Background())
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("Expected ClearAndWait to block on a load started before an earlier clear")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(t, <-waited)
+
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected not to find key 1 after clear")
+}
@@ -0,0 +1,69 @@
+package benchmarks
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+const opsPerWorkload = 1 << 20
+
+var value = make([]byte, 64)
+
+func BenchmarkCaches(b *testing.B) {
+	for _, w := range Workloads {
+		ops := w.Ops(opsPerWorkload, 1)
+		for _, impl := range Implementations {
+			b.Run(w.Name+"/"+impl.Name, func(b *testing.B) {
+				c := impl.New(w.Keys / 2)
+				defer c.Close()
+				for _, key := range keys(w.Keys / 2) {
+					c.Set(key, value)
+				}
+				settle(c)
+				var next, hits atomic.Int64
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := int(next.Add(opsPerWorkload / 16))
+					n := int64(0)
+					for pb.Next() {
+						op := ops[i&(opsPerWorkload-1)]
+						if op.Write {
+							c.Set(op.Key, value)
+						} else if c.Get(op.Key) {
+							n++
+						}
+						i++
+					}
+					hits.Add(n)
+				})
+				b.ReportMetric(float64(hits.Load())/float64(b.N), "hits/op")
+			})
+		}
+	}
+}
+
+func TestImplementations(t *testing.T) {
+	for _, impl := range Implementations {
+		t.Run(impl.Name, func(t *testing.T) {
+			c := impl.New(100)
+			defer c.Close()
+			c.Set("k", value)
+			settle(c)
+			if !c.Get("k") {
+				t.Fatal("expected a hit after Set")
+			}
+		})
+	}
+}
+
+func TestWorkloadsDeterministic(t *testing.T) {
+	for _, w := range Workloads {
+		a, b := w.Ops(1000, 7), w.Ops(1000, 7)
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("%s: ops differ at %d", w.Name, i)
+			}
+		}
+	}
+}
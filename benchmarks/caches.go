@@ -0,0 +1,144 @@
+// Package benchmarks compares MemoryCache with popular Go caches on
+// standardized workloads. It is a separate module so that the comparison
+// libraries never become dependencies of the cache itself.
+//
+// Run it with
+//
+//	go test -bench . -benchmem
+package benchmarks
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/jellydator/ttlcache/v3"
+	gocache "github.com/patrickmn/go-cache"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+const ttl = 10 * time.Minute
+
+// Cache is the subset of operations the workloads need.
+type Cache interface {
+	Get(key string) bool
+	Set(key string, value []byte)
+	Close()
+}
+
+// Implementation names a cache under test. New returns one sized for
+// capacity entries.
+type Implementation struct {
+	Name string
+	New  func(capacity int) Cache
+}
+
+var Implementations = []Implementation{
+	{"memorycache", newMemoryCache},
+	{"memorycache-lru", newMemoryCacheLRU},
+	{"go-cache", newGoCache},
+	{"ristretto", newRistretto},
+	{"ttlcache", newTTLCache},
+}
+
+type memoryCache struct{ c *cache.Cache[string, []byte] }
+
+func newMemoryCache(capacity int) Cache {
+	return memoryCache{cache.NewCache[string, []byte](ttl, cache.WithInitialCapacity[string, []byte](capacity))}
+}
+
+// newMemoryCacheLRU bounds the cache like ristretto and ttlcache are bounded.
+func newMemoryCacheLRU(capacity int) Cache {
+	return memoryCache{cache.NewCache[string, []byte](ttl,
+		cache.WithInitialCapacity[string, []byte](capacity),
+		cache.WithMaxEntries[string, []byte](capacity),
+	)}
+}
+
+func (m memoryCache) Get(key string) bool {
+	_, ok := m.c.Get(key)
+	return ok
+}
+
+func (m memoryCache) Set(key string, value []byte) { m.c.Set(key, value) }
+func (m memoryCache) Close()                       { m.c.StopCleanup() }
+
+type goCache struct{ c *gocache.Cache }
+
+func newGoCache(int) Cache {
+	return goCache{gocache.New(ttl, time.Minute)}
+}
+
+func (g goCache) Get(key string) bool {
+	_, ok := g.c.Get(key)
+	return ok
+}
+
+func (g goCache) Set(key string, value []byte) { g.c.Set(key, value, gocache.DefaultExpiration) }
+func (g goCache) Close()                       {}
+
+type ristrettoCache struct {
+	c *ristretto.Cache[string, []byte]
+}
+
+func newRistretto(capacity int) Cache {
+	c, err := ristretto.NewCache(&ristretto.Config[string, []byte]{
+		NumCounters: int64(capacity) * 10,
+		MaxCost:     int64(capacity),
+		BufferItems: 64,
+		// Count entries like the other bounded caches do.
+		IgnoreInternalCost: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ristrettoCache{c}
+}
+
+func (r ristrettoCache) Get(key string) bool {
+	_, ok := r.c.Get(key)
+	return ok
+}
+
+func (r ristrettoCache) Set(key string, value []byte) { r.c.SetWithTTL(key, value, 1, ttl) }
+func (r ristrettoCache) Close()                       { r.c.Close() }
+
+// Wait blocks until buffered writes are applied.
+func (r ristrettoCache) Wait() { r.c.Wait() }
+
+type ttlCache struct {
+	c *ttlcache.Cache[string, []byte]
+}
+
+func newTTLCache(capacity int) Cache {
+	c := ttlcache.New[string, []byte](
+		ttlcache.WithTTL[string, []byte](ttl),
+		ttlcache.WithCapacity[string, []byte](uint64(capacity)),
+	)
+	go c.Start()
+	return ttlCache{c}
+}
+
+func (t ttlCache) Get(key string) bool {
+	return t.c.Get(key) != nil
+}
+
+func (t ttlCache) Set(key string, value []byte) { t.c.Set(key, value, ttlcache.DefaultTTL) }
+func (t ttlCache) Close()                       { t.c.Stop() }
+
+// settle waits for caches that apply writes asynchronously.
+func settle(c Cache) {
+	if w, ok := c.(interface{ Wait() }); ok {
+		w.Wait()
+	}
+}
+
+// keys returns n distinct keys.
+func keys(n int) []string {
+	ks := make([]string, n)
+	for i := range ks {
+		ks[i] = "key:" + strconv.Itoa(i)
+	}
+	return ks
+}
@@ -0,0 +1,23 @@
+module github.com/NikoMalik/MemoryCache/benchmarks
+
+go 1.24.0
+
+require (
+	github.com/NikoMalik/MemoryCache v0.0.0
+	github.com/dgraph-io/ristretto/v2 v2.4.2
+	github.com/jellydator/ttlcache/v3 v3.4.1
+	github.com/patrickmn/go-cache v2.1.0+incompatible
+)
+
+require (
+	github.com/alphadose/haxmap v1.4.0 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/exp v0.0.0-20221031165847-c99f073a8326 // indirect
+	golang.org/x/sync v0.16.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+)
+
+replace github.com/NikoMalik/MemoryCache => ../
@@ -0,0 +1,49 @@
+package benchmarks
+
+import "math/rand"
+
+// Workload is a standardized access pattern over a fixed key space.
+type Workload struct {
+	Name string
+	// Keys is the number of distinct keys; caches are sized for Keys/2 so
+	// that bounded ones have to evict.
+	Keys int
+	// Writes is the percentage of operations that are writes.
+	Writes int
+	// Zipf skews key popularity following a Zipf distribution instead of
+	// picking keys uniformly.
+	Zipf bool
+}
+
+var Workloads = []Workload{
+	{Name: "read-heavy", Keys: 1 << 16, Writes: 10},
+	{Name: "write-heavy", Keys: 1 << 16, Writes: 90},
+	{Name: "zipfian", Keys: 1 << 16, Writes: 10, Zipf: true},
+}
+
+// Op is one step of a workload.
+type Op struct {
+	Key   string
+	Write bool
+}
+
+// Ops returns n operations of w, generated deterministically from seed.
+func (w Workload) Ops(n int, seed int64) []Op {
+	r := rand.New(rand.NewSource(seed))
+	ks := keys(w.Keys)
+	var zipf *rand.Zipf
+	if w.Zipf {
+		zipf = rand.NewZipf(r, 1.01, 1, uint64(w.Keys-1))
+	}
+	ops := make([]Op, n)
+	for i := range ops {
+		k := 0
+		if zipf != nil {
+			k = int(zipf.Uint64())
+		} else {
+			k = r.Intn(w.Keys)
+		}
+		ops[i] = Op{Key: ks[k], Write: r.Intn(100) < w.Writes}
+	}
+	return ops
+}
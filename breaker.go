@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by GetOrLoad and Fetch instead of calling the
+// loader while the circuit breaker for the key's group is open.
+var ErrCircuitOpen = errors.New("cache: circuit open")
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 10 * time.Second
+)
+
+// BreakerConfig configures WithCircuitBreaker.
+type BreakerConfig[T comparable] struct {
+	// Group maps a key to the circuit it belongs to, typically the backend
+	// that serves it. By default all keys share one circuit.
+	Group func(T) string
+	// Threshold is the number of consecutive loader failures that opens a
+	// circuit. Defaults to 5.
+	Threshold int
+	// Cooldown is how long a circuit stays open before a single probe load
+	// is let through to test the backend. Defaults to 10 seconds.
+	Cooldown time.Duration
+}
+
+type breakerState uint8
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breaker struct {
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	until    time.Time
+}
+
+type breakers[T comparable] struct {
+	cfg BreakerConfig[T]
+	mu  sync.Mutex
+	m   map[string]*breaker
+}
+
+func newBreakers[T comparable](cfg BreakerConfig[T]) *breakers[T] {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultBreakerThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultBreakerCooldown
+	}
+	return &breakers[T]{cfg: cfg, m: make(map[string]*breaker)}
+}
+
+func (bs *breakers[T]) get(key T) *breaker {
+	var group string
+	if bs.cfg.Group != nil {
+		group = bs.cfg.Group(key)
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.m[group]
+	if !ok {
+		b = &breaker{}
+		bs.m[group] = b
+	}
+	return b
+}
+
+// allow reports whether a load may proceed. Once the cooldown has passed,
+// only the first caller is let through as a probe; the rest keep failing
+// fast until the probe reports back.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if now.Before(b.until) {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	}
+	return true
+}
+
+func (b *breaker) record(err error, now time.Time, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= threshold {
+		b.state, b.until = breakerOpen, now.Add(cooldown)
+	}
+}
+
+// load calls loader for key through the key's circuit breaker, if any,
+// retrying failures as configured by WithLoaderRetry.
+func (c *Cache[T, V]) load(key T, loader func(T) (V, error)) (V, error) {
+	attempt := func() (V, error) { return loader(key) }
+	if c.breakers == nil {
+		return retry(c.retry, attempt)
+	}
+	b := c.breakers.get(key)
+	if !b.allow(c.wallNow()) {
+		var zero V
+		return zero, ErrCircuitOpen
+	}
+	value, err := retry(c.retry, attempt)
+	b.record(err, c.wallNow(), c.breakers.cfg.Threshold, c.breakers.cfg.Cooldown)
+	return value, err
+}
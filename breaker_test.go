@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheCircuitBreaker(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithCircuitBreaker[string, int](BreakerConfig[string]{
+		Group:     func(key string) string { return strings.Split(key, ":")[0] },
+		Threshold: 2,
+		Cooldown:  30 * time.Millisecond,
+	}))
+	defer cache.StopCleanup()
+
+	calls := 0
+	healthy := false
+	loader := func(string) (int, error) {
+		calls++
+		if !healthy {
+			return 0, errors.New("down")
+		}
+		return 1, nil
+	}
+	for range 2 {
+		_, err := cache.GetOrLoad("users:1", loader)
+		assert.EqualError(t, err, "down")
+	}
+	_, err := cache.GetOrLoad("users:2", loader)
+	assert.ErrorIs(t, err, ErrCircuitOpen, "Expected the group to fail fast once tripped")
+	assert.Equal(t, 2, calls)
+
+	_, err = cache.GetOrLoad("orders:1", loader)
+	assert.EqualError(t, err, "down", "Expected other groups to be unaffected")
+
+	time.Sleep(40 * time.Millisecond)
+	_, err = cache.GetOrLoad("users:1", loader)
+	assert.EqualError(t, err, "down", "Expected a probe after the cooldown")
+	_, err = cache.GetOrLoad("users:1", loader)
+	assert.ErrorIs(t, err, ErrCircuitOpen, "Expected a failed probe to reopen the circuit")
+
+	time.Sleep(40 * time.Millisecond)
+	healthy = true
+	value, err := cache.GetOrLoad("users:1", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	_, err = cache.GetOrLoad("users:2", loader)
+	assert.NoError(t, err, "Expected a successful probe to close the circuit")
+}
+
+func TestBreakerHalfOpenSingleProbe(t *testing.T) {
+	b := &breaker{}
+	now := time.Now()
+	b.record(errors.New("down"), now, 1, time.Second)
+	assert.False(t, b.allow(now))
+	later := now.Add(2 * time.Second)
+	assert.True(t, b.allow(later))
+	assert.False(t, b.allow(later), "Expected only one probe while half-open")
+	b.record(nil, later, 1, time.Second)
+	assert.True(t, b.allow(later))
+}
+
+func TestCacheCircuitBreakerStale(t *testing.T) {
+	cache := NewCache[string, int](10*time.Millisecond,
+		WithCircuitBreaker[string, int](BreakerConfig[string]{Threshold: 1, Cooldown: time.Minute}),
+		WithStaleOnError[string, int](time.Minute), WithExactTime[string, int]())
+	defer cache.StopCleanup()
+
+	cache.GetOrLoad("k", func(string) (int, error) { return 1, nil })
+	time.Sleep(30 * time.Millisecond)
+	cache.GetOrLoad("other", func(string) (int, error) { return 0, errors.New("down") })
+	value, err := cache.GetOrLoad("k", func(string) (int, error) {
+		t.Fatal("Expected no load while the circuit is open")
+		return 0, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value, "Expected a stale value instead of a fast failure")
+}
@@ -0,0 +1,39 @@
+// Package natsbus implements cache.Bus over NATS core pub/sub.
+package natsbus
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Bus publishes and receives messages on a single NATS subject.
+type Bus struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// New returns a Bus using subject on conn.
+func New(conn *nats.Conn, subject string) *Bus {
+	return &Bus{conn: conn, subject: subject}
+}
+
+func (b *Bus) Publish(_ context.Context, msg []byte) error {
+	return b.conn.Publish(b.subject, msg)
+}
+
+func (b *Bus) Subscribe(handle func(msg []byte)) (func(), error) {
+	sub, err := b.conn.Subscribe(b.subject, func(m *nats.Msg) {
+		handle(m.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Make sure the server has registered the subscription before
+	// returning, so no later message is missed.
+	if err := b.conn.Flush(); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	return func() { sub.Unsubscribe() }, nil
+}
@@ -0,0 +1,45 @@
+package natsbus
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/nats-io/nats-server/v2/server"
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	opts := natstest.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	srv := natstest.RunServer(&opts)
+	defer srv.Shutdown()
+
+	newCache := func() (*cache.Cache[string, int], *cache.Replicator[string, int]) {
+		conn, err := nats.Connect(srv.ClientURL())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(conn.Close)
+		c := cache.NewCache[string, int](time.Minute)
+		t.Cleanup(c.StopCleanup)
+		r, err := cache.NewReplicator(c, New(conn, "invalidations"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { r.Close() })
+		return c, r
+	}
+	a, _ := newCache()
+	b, rb := newCache()
+
+	a.Set("k", 1)
+	b.Set("k", 2)
+	assert.Eventually(t, func() bool {
+		_, found := a.Get("k")
+		return !found
+	}, time.Second, time.Millisecond, "Expected a write on b to evict a's copy")
+	assert.NoError(t, rb.LastError())
+}
@@ -0,0 +1,48 @@
+// Package redisbus implements cache.Bus over Redis pub/sub.
+package redisbus
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Bus publishes and receives messages on a single Redis pub/sub channel.
+type Bus struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// New returns a Bus using channel on client.
+func New(client redis.UniversalClient, channel string) *Bus {
+	return &Bus{client: client, channel: channel}
+}
+
+func (b *Bus) Publish(ctx context.Context, msg []byte) error {
+	return b.client.Publish(ctx, b.channel, msg).Err()
+}
+
+// Subscribe starts receiving messages. go-redis reconnects a subscription
+// transparently; messages published while disconnected are lost, as is
+// inherent to Redis pub/sub.
+func (b *Bus) Subscribe(handle func(msg []byte)) (func(), error) {
+	ctx := context.Background()
+	sub := b.client.Subscribe(ctx, b.channel)
+	// Wait for the subscription to be confirmed so no message published
+	// after Subscribe returns is missed.
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for m := range sub.Channel() {
+			handle([]byte(m.Payload))
+		}
+	}()
+	return func() {
+		sub.Close()
+		<-done
+	}, nil
+}
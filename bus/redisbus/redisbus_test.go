@@ -0,0 +1,37 @@
+package redisbus
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	srv := miniredis.RunT(t)
+	newCache := func() (*cache.Cache[string, int], *cache.Replicator[string, int]) {
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+		t.Cleanup(func() { client.Close() })
+		c := cache.NewCache[string, int](time.Minute)
+		t.Cleanup(c.StopCleanup)
+		r, err := cache.NewReplicator(c, New(client, "invalidations"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { r.Close() })
+		return c, r
+	}
+	a, _ := newCache()
+	b, rb := newCache()
+
+	a.Set("k", 1)
+	b.Set("k", 2)
+	assert.Eventually(t, func() bool {
+		_, found := a.Get("k")
+		return !found
+	}, time.Second, time.Millisecond, "Expected a write on b to evict a's copy")
+	assert.NoError(t, rb.LastError())
+}
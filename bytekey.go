@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"hash/maphash"
+	"unsafe"
+)
+
+// GetBytes looks up a []byte key in a string-keyed cache without converting
+// it to a string first. The key is only borrowed for the store lookup, so it
+// may be reused by the caller afterwards. Features that keep the key, such
+// as middleware, canonicalizers, tracing, TTL analysis, validation on read
+// and recalling spilled entries, are given a copy, and then the lookup
+// allocates.
+func GetBytes[V any](c *Cache[string, V], key []byte) (V, bool) {
+	if c.middleware.ops.Load() != nil || c.canonicalize != nil || c.tracer != nil || c.reuse != nil || c.validateOnRead {
+		return c.Get(string(key))
+	}
+	borrowed := unsafe.String(unsafe.SliceData(key), len(key))
+	item, ok := c.items().Get(borrowed)
+	if !ok && c.spiller != nil {
+		return c.get(string(key))
+	}
+	var value V
+	if ok && !c.expired(borrowed, item, c.now()) {
+		value, ok = c.value(item)
+	} else {
+		ok = false
+	}
+	if ok {
+		c.touch(borrowed, item)
+	}
+	c.recordRead(borrowed, ok)
+	return value, ok
+}
+
+// GetBytes is like Get for a []byte key and does not allocate.
+func (c *StringCache[V]) GetBytes(key []byte) (V, bool) {
+	s := &c.shards[maphash.Bytes(c.seed, key)%stringShards]
+	s.mu.RLock()
+	e, ok := s.items[string(key)]
+	s.mu.RUnlock()
+	return e.value, ok
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBytes(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("route", 1)
+
+	key := []byte("route")
+	value, found := GetBytes(cache, key)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	copy(key, "other")
+	_, found = cache.Get("route")
+	assert.True(t, found, "Expected the borrowed key not to be retained")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		GetBytes(cache, key)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestStringCacheGetBytes(t *testing.T) {
+	cache := NewStringCache[int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("route", 1)
+
+	key := []byte("route")
+	value, found := cache.GetBytes(key)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		cache.GetBytes(key)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestGetBytesRetained(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithTTLAnalysis[string, int](1))
+	defer cache.StopCleanup()
+	cache.Set("route", 1)
+
+	key := []byte("route")
+	_, found := GetBytes(cache, key)
+	assert.True(t, found)
+	copy(key, "other")
+	_, found = GetBytes(cache, []byte("route"))
+	assert.True(t, found)
+	assert.Greater(t, cache.EstimateHitRatio(time.Minute), 0.0, "Expected the analysis to record a copy of the key")
+}
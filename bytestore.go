@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+const (
+	arenaChunkSize = 1 << 20
+	blobHeaderSize = 8
+)
+
+type blobRef struct {
+	chunk uint32
+	off   uint32
+	n     uint32
+	seq   uint32
+}
+
+type arenaChunk struct {
+	buf       []byte
+	used      int
+	live      int
+	lastAlloc uint64
+}
+
+// byteArena stores encoded values in large pointer-free chunks so that the
+// garbage collector sees a handful of byte slices instead of every value's
+// object graph. Each blob is prefixed with a sequence number; a reader whose
+// reference no longer matches the stored sequence treats the value as gone,
+// which makes recycling a chunk safe without tracking individual frees.
+type byteArena[V any] struct {
+	codec   Codec[V]
+	mu      sync.RWMutex
+	chunks  []*arenaChunk
+	free    []uint32
+	current uint32
+	seq     uint32
+	epoch   uint64
+	// mapped is the file backing the chunks with WithMmapStorage, which
+	// fixes the number of chunks and appends a trailer to every blob.
+	mapped *mappedFile
+}
+
+var errArenaFull = errors.New("cache: byte arena is full")
+
+func newByteArena[V any](codec Codec[V]) *byteArena[V] {
+	a := &byteArena[V]{codec: codec}
+	a.chunks = []*arenaChunk{{buf: make([]byte, arenaChunkSize)}}
+	return a
+}
+
+// put encodes value into the arena, followed by trailer. The sequence number
+// is written last, so a blob torn by a crash reads as the end of its chunk.
+func (a *byteArena[V]) put(value V, trailer []byte) (blobRef, error) {
+	data, err := a.codec.Encode(value)
+	if err != nil {
+		return blobRef{}, err
+	}
+	need := blobHeaderSize + len(data) + len(trailer)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.chunks == nil {
+		return blobRef{}, errArenaFull
+	}
+	a.seq++
+	if a.seq == 0 || a.seq == deadSeq {
+		a.seq = 1
+	}
+	ch := a.chunks[a.current]
+	if ch.used+need > len(ch.buf) {
+		idx, err := a.allocChunk(need)
+		if err != nil {
+			return blobRef{}, err
+		}
+		a.current = idx
+		ch = a.chunks[a.current]
+	}
+	ref := blobRef{chunk: a.current, off: uint32(ch.used), n: uint32(len(data)), seq: a.seq}
+	binary.LittleEndian.PutUint32(ch.buf[ch.used+4:], uint32(len(data)))
+	copy(ch.buf[ch.used+blobHeaderSize:], data)
+	copy(ch.buf[ch.used+blobHeaderSize+len(data):], trailer)
+	binary.LittleEndian.PutUint32(ch.buf[ch.used:], a.seq)
+	ch.used += need
+	ch.lastAlloc = a.epoch
+	return ref, nil
+}
+
+func (a *byteArena[V]) allocChunk(need int) (uint32, error) {
+	size := arenaChunkSize
+	if need > size {
+		size = need
+	}
+	for i, idx := range a.free {
+		if len(a.chunks[idx].buf) >= size {
+			a.free = append(a.free[:i], a.free[i+1:]...)
+			a.chunks[idx].used = 0
+			return idx, nil
+		}
+	}
+	if a.mapped != nil {
+		return 0, errArenaFull
+	}
+	a.chunks = append(a.chunks, &arenaChunk{buf: make([]byte, size)})
+	return uint32(len(a.chunks) - 1), nil
+}
+
+func (a *byteArena[V]) get(ref blobRef) (V, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var zero V
+	if int(ref.chunk) >= len(a.chunks) {
+		return zero, false
+	}
+	ch := a.chunks[ref.chunk]
+	end := int(ref.off) + blobHeaderSize + int(ref.n)
+	if end > ch.used || binary.LittleEndian.Uint32(ch.buf[ref.off:]) != ref.seq {
+		return zero, false
+	}
+	value, err := a.codec.Decode(ch.buf[int(ref.off)+blobHeaderSize : end])
+	if err != nil {
+		return zero, false
+	}
+	return value, true
+}
+
+// reclaim recycles every chunk that holds no blob referenced by a live item.
+// live is called with a mark function for each live reference.
+func (a *byteArena[V]) reclaim(live func(mark func(blobRef))) {
+	a.mu.Lock()
+	a.epoch++
+	epoch := a.epoch
+	for _, ch := range a.chunks {
+		ch.live = 0
+	}
+	a.mu.Unlock()
+
+	counts := make(map[uint32]int)
+	var refs map[blobRef]struct{}
+	if a.mapped != nil {
+		refs = make(map[blobRef]struct{})
+	}
+	live(func(ref blobRef) {
+		counts[ref.chunk]++
+		if refs != nil {
+			refs[ref] = struct{}{}
+		}
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for idx, ch := range a.chunks {
+		i := uint32(idx)
+		if refs != nil && counts[i] > 0 && ch.lastAlloc < epoch {
+			a.buryDead(i, refs)
+		}
+		if i == a.current || ch.lastAlloc >= epoch || counts[i] > 0 || ch.used == 0 {
+			continue
+		}
+		// Invalidate the headers so stale references miss rather than
+		// decoding data written after reuse.
+		clear(ch.buf[:ch.used])
+		ch.used = 0
+		a.free = append(a.free, i)
+	}
+}
+
+func (c *Cache[T, V]) newItem(value V, src Source) CachedItem[V] {
+	now := c.now()
+	item := CachedItem[V]{
+		expires: now + int64(c.ttl),
+		created: now,
+		source:  c.source(src),
+	}
+	if c.tracking {
+		item.access = new(accessStats)
+	}
+	if c.arena == nil || c.arena.mapped != nil {
+		// Mapped blobs carry their key, so store writes them.
+		item.Value = value
+		return item
+	}
+	ref, err := c.arena.put(value, nil)
+	if err != nil {
+		c.stats.codecErrors.Add(1)
+		item.Value = value
+		return item
+	}
+	item.blob = ref
+	return item
+}
+
+// value returns the value held by item, decoding it from the arena when byte
+// storage is enabled.
+func (c *Cache[T, V]) value(item CachedItem[V]) (V, bool) {
+	if item.blob.seq == 0 {
+		return item.Value, true
+	}
+	return c.arena.get(item.blob)
+}
+
+func (c *Cache[T, V]) reclaimArena() {
+	if c.arena == nil {
+		return
+	}
+	c.arena.reclaim(func(mark func(blobRef)) {
+		c.items().ForEach(func(_ T, item CachedItem[V]) bool {
+			if item.blob.seq != 0 {
+				mark(item.blob)
+			}
+			return true
+		})
+		c.trash.mu.Lock()
+		for _, e := range c.trash.entries {
+			if e.item.blob.seq != 0 {
+				mark(e.item.blob)
+			}
+		}
+		c.trash.mu.Unlock()
+	})
+}
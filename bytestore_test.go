@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type byteValue struct {
+	Name string
+	Tags []string
+}
+
+func TestCacheByteStorage(t *testing.T) {
+	cache := NewCache[int, byteValue](time.Minute, WithByteStorage[int, byteValue](GobCodec[byteValue]{}))
+	defer cache.StopCleanup()
+
+	want := byteValue{Name: "test", Tags: []string{"a", "b"}}
+	cache.Set(1, want)
+
+	value, found := cache.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, want, value)
+
+	item, _ := cache.items().Get(1)
+	assert.Equal(t, byteValue{}, item.Value, "Expected value to live in the arena")
+
+	value.Tags[0] = "changed"
+	again, _ := cache.Get(1)
+	assert.Equal(t, "a", again.Tags[0], "Expected reads to decode independent copies")
+}
+
+func TestCacheByteStorageReclaim(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithByteStorage[int, string](JSONCodec[string]{}))
+	defer cache.StopCleanup()
+
+	big := strings.Repeat("x", arenaChunkSize/4)
+	for i := 0; i < 20; i++ {
+		cache.Set(1, big)
+	}
+	grown := len(cache.arena.chunks)
+	assert.Greater(t, grown, 1)
+
+	cache.reclaimArena()
+	for i := 0; i < 20; i++ {
+		cache.Set(1, big)
+		cache.reclaimArena()
+	}
+	assert.Equal(t, grown, len(cache.arena.chunks), "Expected unreferenced chunks to be reused")
+
+	value, found := cache.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, big, value)
+}
+
+func TestCacheByteStorageStaleReference(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithByteStorage[int, string](JSONCodec[string]{}))
+	defer cache.StopCleanup()
+
+	big := strings.Repeat("x", arenaChunkSize/2)
+	cache.Set(1, big)
+	stale, _ := cache.items().Get(1)
+	cache.Delete(1)
+	cache.Set(2, big)
+	cache.Set(2, big)
+	cache.reclaimArena()
+
+	_, ok := cache.value(stale)
+	assert.False(t, ok, "Expected reference into a recycled chunk to miss")
+}
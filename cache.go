@@ -1,15 +1,19 @@
 package cache
 
 import (
+	"errors"
+	"io/fs"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
-
-	"github.com/alphadose/haxmap"
 )
 
 const (
 	iter0       = 1 << 3
 	elementNum0 = 1 << 10
+
+	defaultStoreShards = 64
 )
 
 type Signed interface {
@@ -40,81 +44,427 @@ type hashable interface {
 	Integer | Float | Complex | ~string | uintptr | ~unsafe.Pointer
 }
 
+// Hashable is the constraint on the key types accepted by NewCache, for
+// generic code that creates caches.
+type Hashable interface {
+	hashable
+}
+
 type CachedItem[V any] struct {
-	Value       V
-	CreatedTime time.Time
+	Value   V
+	expires int64
+	delta   time.Duration
+	source  Source
+	// explicit marks entries with their own TTL, which lookups check
+	// rather than leaving expiry entirely to the cleanup routine.
+	explicit bool
+	priority Priority
+	// version is assigned from Cache.versions whenever the item is stored.
+	version uint64
+	created int64
+	// access is shared by all copies of the item and only set when access
+	// tracking is enabled.
+	access *accessStats
+	blob   blobRef
+}
+
+func (i CachedItem[V]) ExpiresAt() time.Time {
+	return wallTime(i.expires)
 }
 
-type Cache[T hashable, V any] struct {
-	cache       *haxmap.Map[T, *CachedItem[V]]
+type Cache[T comparable, V any] struct {
+	data        atomic.Pointer[Store[T, V]]
 	ttl         time.Duration
 	stopCleanup chan struct{}
+	stopOnce    sync.Once
+	workers     sync.WaitGroup
+	flight      flightGroup[T, V]
+	loads       loadBarrier
+	beta        float64
+
+	canonicalize   func(T) T
+	validator      func(V) error
+	validateOnRead bool
+	stats          counters
+	backend        Backend[T, V]
+	writeThrough   bool
+	provenance     bool
+	writeBehind    *writeBehind[T, V]
+	gens           generations
+	trash          trash[T, V]
+	snapshot       *snapshotter
+	aof            *appendLog[T, V]
+	codec          Codec[V]
+	arena          *byteArena[V]
+	mmapErr        error
+	exactTime      bool
+	// coarse is set when the cache holds a reference on the coarse clock.
+	coarse       bool
+	expiry       []*expiryIndex[T]
+	hasher       keyHasher[T]
+	storeFactory func(capacity int) Store[T, V]
+	capacity     int
+	namespaces   namespaces[T, V]
+	events       subscribers[T, V]
+	hooks        hooks[T, V]
+	waiters      waiters[T]
+	middleware   middlewares[T, V]
+	replicator   atomic.Pointer[Replicator[T, V]]
+	stream       *stream[T]
+	// writes is bumped before and after every change to the store, so a
+	// copy that saw it unchanged is a consistent point-in-time view.
+	writes  atomic.Uint64
+	replErr atomic.Pointer[error]
+	budget  cleanupBudget
+	// schedule makes the janitor interval adaptive; see WithAdaptiveCleanup.
+	schedule cleanupSchedule
+	// versions hands out entry versions; txn serializes Txn commits, and
+	// committing holds the keys of the one being applied, which lookups
+	// wait for.
+	versions   atomic.Uint64
+	txn        sync.Mutex
+	committing atomic.Pointer[txnCommit[T]]
+	tracking   bool
+	sampleRate uint32
+	reuse      *reuseTracker[T]
+	clock      Clock
+	maxStale   time.Duration
+	breakers   *breakers[T]
+	retry      *retryPolicy
+	eviction   *evictor[T]
+	weigher    func(T, V) int64
+	autoWeigh  func(V) int64
+	pins       pins[T]
+	deps       deps[T]
+	paths      *pathIndex[T]
+	indexes    map[string]*valueIndex[T, V]
+	order      *orderedIndex[T]
+	// hashes is built by the first Scan; see hashIndex.
+	hashes atomic.Pointer[hashIndex[T]]
+	// pinnedExpiry lets pinned entries expire; see WithPinnedExpiry.
+	pinnedExpiry bool
+	tracer       *tracer
+	buffer       *writeBuffer[T, V]
+	locks        keyLocks
+	spiller      *spiller[T]
+	sealer       *sealer
+	uploads      *uploader
+	warmup       *warmup[T, V]
+	profiler     *profiler
+	janitor      janitorBeat
+	// following counts Follow calls in progress and synced those of them
+	// that have caught up with the primary.
+	following, synced atomic.Int32
 }
 
-func NewCache[T hashable, V any](ttl time.Duration) *Cache[T, V] {
+func NewCache[T hashable, V any](ttl time.Duration, opts ...Option[T, V]) *Cache[T, V] {
+	return newCache(ttl, newKeyHasher[T](), newHaxmapStore[T, V], opts)
+}
+
+// NewCacheComparable returns a Cache for any comparable key type, such as
+// structs, arrays and bools, which NewCache does not accept. hash must return
+// equal values for equal keys; it spreads keys over expiry shards and the
+// default store, which is a sharded Go map.
+func NewCacheComparable[K comparable, V any](ttl time.Duration, hash func(K) uintptr, opts ...Option[K, V]) *Cache[K, V] {
+	hasher := hasherFunc(hash)
+	newStore := func(capacity int) Store[K, V] {
+		return newShardedMapStore[K, V](defaultStoreShards, hasher, capacity)
+	}
+	return newCache(ttl, hasher, newStore, opts)
+}
+
+func newCache[T comparable, V any](ttl time.Duration, hasher keyHasher[T], newStore func(capacity int) Store[T, V], opts []Option[T, V]) *Cache[T, V] {
 	c := &Cache[T, V]{
-		cache:       haxmap.New[T, *CachedItem[V]](iter0 * elementNum0),
-		ttl:         ttl,
-		stopCleanup: make(chan struct{}),
+		ttl:          ttl,
+		stopCleanup:  make(chan struct{}),
+		hasher:       hasher,
+		storeFactory: newStore,
+	}
+	c.stats.init()
+	c.expiry = []*expiryIndex[T]{nil}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.eviction != nil && !c.eviction.limited() {
+		c.eviction = nil
+	}
+	if c.coarse = c.clock == nil && !c.exactTime; c.coarse {
+		acquireCoarse()
+	}
+	c.setItems(c.newStore())
+	for i := range c.expiry {
+		c.expiry[i] = newExpiryIndex[T](ttl, c.nanotime())
+		c.background(func() { c.startCleanupRoutine(i) })
+	}
+	if c.arena != nil && c.arena.mapped != nil {
+		c.restoreMapped()
+	}
+	if c.writeBehind != nil {
+		c.background(func() { c.writeBehind.run(c.stopCleanup) })
+	}
+	if c.snapshot != nil {
+		if err := c.LoadFile(c.snapshot.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.snapshot.record(err)
+		}
+		c.background(c.runSnapshots)
+	}
+	if c.uploads != nil {
+		c.background(c.runUploads)
+	}
+	if c.warmup != nil {
+		c.background(c.runWarmup)
+	}
+	if c.aof != nil {
+		c.openAOF()
+	}
+	if c.stream != nil {
+		c.background(c.serveReplicas)
+	}
+	if c.buffer != nil {
+		c.buffer.apply = c.applyBuffered
+		c.background(func() { c.buffer.run(c.stopCleanup) })
+	}
+	if c.tracer != nil {
+		c.tracer.start(c.wallNow(), c.nanotime())
+		c.background(c.runTrace)
 	}
-	go c.startCleanupRoutine()
 	return c
 }
 
+func (c *Cache[T, V]) newStore() Store[T, V] {
+	return c.storeFactory(c.capacity)
+}
+
+// Grow reserves room for another n entries so that filling the cache with a
+// known working set does not resize the underlying store repeatedly. It is a
+// no-op for stores that do not implement Grower.
+func (c *Cache[T, V]) Grow(n int) {
+	if g, ok := c.items().(Grower); ok && n > 0 {
+		g.Grow(n)
+	}
+}
+
+func (c *Cache[T, V]) items() Store[T, V] {
+	return *c.data.Load()
+}
+
+func (c *Cache[T, V]) setItems(s Store[T, V]) {
+	c.data.Store(&s)
+}
+
+// Compact releases memory held on to after a Clear or a mass expiry by
+// resizing the store, the expiry index and the byte arena to fit the entries
+// that remain. It is safe to call concurrently with other operations.
+func (c *Cache[T, V]) Compact() {
+	if s, ok := c.items().(Compacter); ok {
+		s.Compact()
+	}
+	for _, x := range c.expiry {
+		x.compact()
+	}
+	c.reclaimArena()
+}
+
+func (c *Cache[T, V]) canonical(key T) T {
+	if c.canonicalize == nil {
+		return key
+	}
+	return c.canonicalize(key)
+}
+
 func (c *Cache[T, V]) Set(key T, value V) {
+	_ = c.TrySet(key, value)
+}
 
-	c.cache.Set(key, &CachedItem[V]{
-		Value:       value,
-		CreatedTime: time.Now(),
-	})
+func (c *Cache[T, V]) set(key T, value V, src Source, ttl time.Duration, priority Priority, pending *pendingHooks) {
+	item := c.newItem(value, src)
+	item.priority = priority
+	if ttl > 0 {
+		item.expires, item.explicit = c.now()+int64(ttl), true
+	}
+	c.store(key, item, pending)
 }
 
+// Get returns the value stored under key. Lookups do not allocate, hit or
+// miss, unless byte storage has to decode the value.
 func (c *Cache[T, V]) Get(key T) (V, bool) {
-	val, ok := c.cache.Get(key)
-	if !ok {
+	if ops := c.middleware.ops.Load(); ops != nil {
+		return ops.Get(key)
+	}
+	return c.get(key)
+}
 
-		var zero V
-		return zero, false
+func (c *Cache[T, V]) get(key T) (V, bool) {
+	key = c.canonical(key)
+	c.analyze(key)
+	item, value, ok := c.lookup(key)
+	if ok {
+		c.touch(key, item)
+	}
+	c.recordRead(key, ok)
+	return value, ok
+}
+
+// expired reports whether lookups must stop serving item. Entries with the
+// default TTL are otherwise served until the cleanup routine removes them.
+func (c *Cache[T, V]) expired(key T, item CachedItem[V], now int64) bool {
+	return (item.explicit || c.maxStale > 0) && item.expires <= now && !c.immortal(key)
+}
+
+// lookup returns the item stored under an already canonical key together
+// with its decoded value, dropping entries the validator rejects.
+func (c *Cache[T, V]) lookup(key T) (CachedItem[V], V, bool) {
+	var zero V
+	if tc := c.committing.Load(); tc != nil {
+		tc.wait(key)
+	}
+	item, ok := c.items().Get(key)
+	if !ok && c.spiller != nil {
+		item, ok = c.recall(key)
+	}
+	if !ok {
+		// Stores may hand back a removed item along with false.
+		return CachedItem[V]{}, zero, false
+	}
+	if c.expired(key, item, c.now()) {
+		return item, zero, false
 	}
-	item := val
+	value, ok := c.value(item)
+	if !ok || !c.validCached(key, value) {
+		return item, zero, false
+	}
+	return item, value, true
+}
 
-	return item.Value, true
+// Len returns the number of entries in the cache, including expired entries
+// the cleanup routine has not removed yet.
+func (c *Cache[T, V]) Len() int {
+	return c.items().Len()
+}
+
+// Range calls fn for every entry until fn returns false. Entries set or
+// deleted concurrently may or may not be visited.
+func (c *Cache[T, V]) Range(fn func(key T, value V) bool) {
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		value, ok := c.value(item)
+		if !ok {
+			return true
+		}
+		return fn(key, value)
+	})
 }
 
 func (c *Cache[T, V]) Delete(key T) {
-	c.cache.Del(key)
+	_ = c.TryDelete(key)
 }
 
+// Clear removes every entry. It takes constant time however many entries
+// there are.
 func (c *Cache[T, V]) Clear() {
-	c.cache.ForEach(func(key T, value *CachedItem[V]) bool {
-		c.cache.Del(key)
-		return true
+	c.flushBuffer()
+	c.logged(aofClear, *new(T), *new(V), 0, func() {
+		c.clear()
 	})
 }
 
-func (c *Cache[T, V]) startCleanupRoutine() {
-	ticker := time.NewTicker(c.ttl)
-	defer ticker.Stop()
+func (c *Cache[T, V]) clear() *loadEpoch {
+	epoch := c.loads.advance()
+	c.emptyTrash()
+	c.resetExpiry()
+	c.clearDeps()
+	if c.spiller != nil {
+		c.spiller.record(c.spiller.store.Clear())
+	}
+	// Swapping in an empty store keeps Clear constant-time however large
+	// the cache; the old store is left to the garbage collector. A write
+	// racing with Clear that lands in the old store is repeated in the new
+	// one by store.
+	c.writes.Add(1)
+	c.setItems(c.newStore())
+	c.writes.Add(1)
+	c.retrack(c.items())
+	c.notify(EventClear, *new(T), CachedItem[V]{}, nil)
+	return epoch
+}
+
+// startCleanupRoutine runs the janitor for one expiry shard. The first shard's
+// janitor also handles cache-wide housekeeping. With WithAdaptiveCleanup the
+// ticker is replaced whenever a sweep moves the interval.
+func (c *Cache[T, V]) startCleanupRoutine(shard int) {
+	interval := c.ttl
+	if c.schedule.adaptive() {
+		interval = c.schedule.start(interval)
+	}
+	ticker := c.newTicker(interval)
+	defer func() { ticker.Stop() }()
+	if shard == 0 {
+		c.janitor.beat(c.nanotime(), interval)
+	}
+	prev := 0
 	for {
 		select {
-		case <-ticker.C:
-			c.cleanup()
+		case <-ticker.C():
+			var expired int
+			var more bool
+			c.labelled("sweep", func() {
+				if shard == 0 {
+					expired, more = c.cleanup()
+				} else {
+					expired, more = c.expireShard(c.expiry[shard], c.nanotime())
+				}
+			})
+			if c.schedule.adaptive() {
+				next := c.schedule.next(interval, expired, prev, more)
+				prev = expired
+				if next != interval {
+					interval = next
+					ticker.Stop()
+					ticker = c.newTicker(interval)
+				}
+			}
+			if shard == 0 {
+				c.janitor.beat(c.nanotime(), interval)
+			}
 		case <-c.stopCleanup:
 			return
 		}
 	}
 }
 
-func (c *Cache[T, V]) cleanup() {
-	now := time.Now()
-	c.cache.ForEach(func(key T, value *CachedItem[V]) bool {
-		if now.Sub(value.CreatedTime) > c.ttl {
-			c.cache.Del(key)
-		}
-		return true
-	})
+func (c *Cache[T, V]) cleanup() (expired int, more bool) {
+	c.purgeTrash(c.wallNow())
+	defer c.reclaimArena()
+	return c.expireShard(c.expiry[0], c.nanotime())
+}
+
+// background runs fn on its own goroutine; StopCleanup waits for it to return.
+func (c *Cache[T, V]) background(fn func()) {
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		c.labelled("background", fn)
+	}()
 }
 
+// StopCleanup stops the cleanup routine and every other background worker,
+// waiting for final flushes and snapshots to complete. Calling it more than
+// once has no further effect.
 func (c *Cache[T, V]) StopCleanup() {
-	close(c.stopCleanup)
+	c.stopOnce.Do(func() {
+		c.stopNamespaces()
+		close(c.stopCleanup)
+		if c.coarse {
+			releaseCoarse()
+		}
+	})
+	c.workers.Wait()
+	c.flushBuffer()
+	if c.arena != nil && c.arena.mapped != nil && c.arena.chunks != nil {
+		c.reclaimArena()
+		c.arena.mapped.record(c.arena.close())
+	}
+	if c.spiller != nil {
+		c.spiller.record(c.spiller.store.Close())
+	}
+	c.closeSubscriptions()
 }
@@ -1,7 +1,10 @@
 package cache
 
 import (
+	"math"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -61,6 +64,33 @@ func TestCacheClear(t *testing.T) {
 	assert.False(t, found, "Expected not to find key 2 after clear")
 }
 
+type countingStore[T comparable, V any] struct {
+	Store[T, V]
+	dels *atomic.Int64
+}
+
+func (s countingStore[T, V]) Del(key T) {
+	s.dels.Add(1)
+	s.Store.Del(key)
+}
+
+func TestCacheClearConstantTime(t *testing.T) {
+	var dels atomic.Int64
+	cache := NewCache[int, int](time.Minute, WithStore[int, int](func() Store[int, int] {
+		return countingStore[int, int]{NewSyncMapStore[int, int](), &dels}
+	}))
+	defer cache.StopCleanup()
+
+	for i := range 1000 {
+		cache.Set(i, i)
+	}
+	cache.Clear()
+	assert.Zero(t, dels.Load(), "Expected Clear not to delete entries one by one")
+	assert.Empty(t, cache.Snapshot())
+	cache.Set(1, 1)
+	assert.Len(t, cache.Snapshot(), 1)
+}
+
 func TestCacheTTLExpiration(t *testing.T) {
 	cache := NewCache[int, string](50 * time.Millisecond)
 
@@ -335,3 +365,113 @@ func TestCacheConcurrentSetAndDeleteAsync(t *testing.T) {
 		t.Errorf("Expected not to find key, but found")
 	}
 }
+
+type routeKey struct {
+	Method string
+	Path   string
+	Auth   bool
+}
+
+func TestCacheComparableKeys(t *testing.T) {
+	hash := func(k routeKey) uintptr {
+		return uintptr(len(k.Method)*31 + len(k.Path))
+	}
+	cache := NewCacheComparable[routeKey, string](50*time.Millisecond, hash, WithExpiryShards[routeKey, string](4))
+	defer cache.StopCleanup()
+
+	cache.Set(routeKey{"GET", "/a", false}, "public")
+	cache.Set(routeKey{"GET", "/a", true}, "private")
+
+	value, found := cache.Get(routeKey{"GET", "/a", true})
+	assert.True(t, found)
+	assert.Equal(t, "private", value, "Expected colliding hashes to keep keys distinct")
+
+	cache.Delete(routeKey{"GET", "/a", true})
+	value, found = cache.Get(routeKey{"GET", "/a", false})
+	assert.True(t, found)
+	assert.Equal(t, "public", value)
+
+	time.Sleep(150 * time.Millisecond)
+	_, found = cache.Get(routeKey{"GET", "/a", false})
+	assert.False(t, found, "Expected comparable keys to expire")
+}
+
+func TestCacheCompact(t *testing.T) {
+	cache := NewCache[int, int](time.Minute, WithStore[int, int](func() Store[int, int] {
+		return NewShardedMapStore[int, int](4)
+	}))
+	defer cache.StopCleanup()
+
+	for i := 0; i < 10000; i++ {
+		cache.Set(i, i)
+	}
+	cache.Clear()
+	cache.Set(1, 1)
+	cache.Compact()
+
+	value, found := cache.Get(1)
+	assert.True(t, found, "Expected Compact to keep live entries")
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 1, cache.items().Len())
+}
+
+func BenchmarkCacheSetOverwrite(b *testing.B) {
+	cache := NewCache[int, string](time.Minute)
+	defer cache.StopCleanup()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Set(i&1023, "value")
+	}
+}
+
+func BenchmarkCacheGet(b *testing.B) {
+	cache := NewCache[int, string](time.Minute)
+	defer cache.StopCleanup()
+	for i := 0; i < 1024; i++ {
+		cache.Set(i, "value")
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(i & 1023)
+	}
+}
+
+func TestCacheClearRacingWrites(t *testing.T) {
+	cache := NewCache[int, int](time.Minute, WithMaxEntries[int, int](1<<13), WithOrderedKeys[int, int]())
+	defer cache.StopCleanup()
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 20000 {
+				cache.Set(g<<20|i%1000, i)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for cleared := false; !cleared; {
+		select {
+		case <-done:
+			cleared = true
+		default:
+			cache.Clear()
+			runtime.Gosched()
+		}
+	}
+
+	ordered := 0
+	RangeBetween(cache, 0, math.MaxInt, func(int, int) bool {
+		ordered++
+		return true
+	})
+	assert.Equal(t, cache.Len(), cache.eviction.len(), "Expected the eviction policy to track exactly the stored keys")
+	assert.Equal(t, cache.Len(), ordered, "Expected the ordered index to hold exactly the stored keys")
+}
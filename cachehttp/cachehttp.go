@@ -0,0 +1,163 @@
+// Package cachehttp exposes a cache over HTTP for inspection and
+// administration.
+package cachehttp
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+const defaultLimit = 100
+
+// Page is a page of keys returned by the keys endpoint. Next is the cursor
+// for the following page and is empty on the last one.
+type Page struct {
+	Keys []string `json:"keys"`
+	Next string   `json:"next,omitempty"`
+}
+
+// Entry is the JSON form of a cache entry.
+type Entry[V any] struct {
+	Key       string       `json:"key"`
+	Value     V            `json:"value"`
+	ExpiresAt time.Time    `json:"expires"`
+	TTL       string       `json:"ttl"`
+	Source    cache.Source `json:"source"`
+}
+
+// Stats is the JSON form of the stats endpoint.
+type Stats struct {
+	cache.Stats
+	Len int `json:"len"`
+}
+
+// Memory is the JSON form of the memory endpoint.
+type Memory struct {
+	Bytes int64 `json:"bytes"`
+}
+
+//go:embed dashboard.html
+var dashboard []byte
+
+// Handler returns an http.Handler serving JSON endpoints over c:
+//
+//	GET    /                            HTML dashboard over the endpoints below
+//	GET    /keys?after=&limit=&prefix=  list keys in order, paginated
+//	GET    /keys/{key}                  get an entry with its remaining TTL
+//	DELETE /keys/{key}                  delete an entry
+//	POST   /clear?namespace=            clear a namespace, or the whole cache
+//	GET    /stats                       dump stats
+//	GET    /top?n=                      the most read keys, with WithAccessTracking
+//	GET    /memory                      the cache's MemoryEstimate
+//
+// The dashboard charts the hit ratio and entry count while it is open, shows
+// the top keys and memory estimate, and searches keys by prefix.
+// Mount it under a prefix with http.StripPrefix. The handler performs no
+// authentication of its own.
+func Handler[V any](c *cache.Cache[string, V]) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		q := r.URL.Query()
+		writeJSON(w, listKeys(c, q.Get("after"), q.Get("prefix"), limit))
+	})
+	mux.HandleFunc("GET /keys/{key}", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+		e, ok := c.GetEntry(key)
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, Entry[V]{
+			Key:       key,
+			Value:     e.Value,
+			ExpiresAt: e.ExpiresAt,
+			TTL:       time.Until(e.ExpiresAt).Round(time.Millisecond).String(),
+			Source:    e.Source,
+		})
+	})
+	mux.HandleFunc("DELETE /keys/{key}", func(w http.ResponseWriter, r *http.Request) {
+		if err := c.TryDelete(r.PathValue("key")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /clear", func(w http.ResponseWriter, r *http.Request) {
+		if ns := r.URL.Query().Get("namespace"); ns != "" {
+			c.Namespace(ns).Clear()
+		} else {
+			c.Clear()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, Stats{Stats: c.Stats(), Len: c.Len()})
+	})
+	mux.HandleFunc("GET /top", func(w http.ResponseWriter, r *http.Request) {
+		n := 10
+		if s := r.URL.Query().Get("n"); s != "" {
+			var err error
+			if n, err = strconv.Atoi(s); err != nil || n <= 0 {
+				http.Error(w, "invalid n", http.StatusBadRequest)
+				return
+			}
+		}
+		top := c.TopKeys(n)
+		if top == nil {
+			top = []cache.KeyStat[string]{}
+		}
+		writeJSON(w, top)
+	})
+	mux.HandleFunc("GET /memory", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, Memory{Bytes: c.MemoryEstimate()})
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(dashboard)
+	})
+	return mux
+}
+
+// listKeys returns up to limit keys starting with prefix and greater than
+// after in sorted order, so that pages stay stable while the cache changes
+// underneath.
+func listKeys[V any](c *cache.Cache[string, V], after, prefix string, limit int) Page {
+	var keys []string
+	c.Range(func(key string, _ V) bool {
+		if key > after && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	slices.Sort(keys)
+	p := Page{Keys: keys}
+	if len(keys) > limit {
+		p.Keys = keys[:limit]
+		p.Next = p.Keys[limit-1]
+	}
+	if p.Keys == nil {
+		p.Keys = []string{}
+	}
+	return p
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
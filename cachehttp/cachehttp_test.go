@@ -0,0 +1,108 @@
+package cachehttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/stretchr/testify/assert"
+)
+
+func do(t *testing.T, h http.Handler, method, target string, out any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	if out != nil && rec.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	}
+	return rec.Code
+}
+
+func TestHandlerKeys(t *testing.T) {
+	c := cache.NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	for _, k := range []string{"d", "a", "c", "b", "e"} {
+		c.Set(k, 1)
+	}
+	h := Handler(c)
+
+	var page Page
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/keys?limit=2", &page))
+	assert.Equal(t, Page{Keys: []string{"a", "b"}, Next: "b"}, page)
+
+	page = Page{}
+	do(t, h, "GET", "/keys?limit=2&after=d", &page)
+	assert.Equal(t, Page{Keys: []string{"e"}}, page, "Expected the last page to have no cursor")
+
+	assert.Equal(t, http.StatusBadRequest, do(t, h, "GET", "/keys?limit=x", nil))
+}
+
+func TestHandlerEntry(t *testing.T) {
+	c := cache.NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	c.Set("k", 42)
+	h := Handler(c)
+
+	var e Entry[int]
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/keys/k", &e))
+	assert.Equal(t, "k", e.Key)
+	assert.Equal(t, 42, e.Value)
+	ttl, err := time.ParseDuration(e.TTL)
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	assert.Equal(t, http.StatusNoContent, do(t, h, "DELETE", "/keys/k", nil))
+	assert.Equal(t, http.StatusNotFound, do(t, h, "GET", "/keys/k", nil))
+}
+
+func TestHandlerClearAndStats(t *testing.T) {
+	c := cache.NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	c.Set("k", 1)
+	c.Namespace("tenant").Set("k", 1)
+	h := Handler(c)
+
+	assert.Equal(t, http.StatusNoContent, do(t, h, "POST", "/clear?namespace=tenant", nil))
+	_, found := c.Namespace("tenant").Get("k")
+	assert.False(t, found)
+
+	var stats Stats
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/stats", &stats))
+	assert.Equal(t, 1, stats.Len, "Expected clearing a namespace to leave the parent alone")
+
+	do(t, h, "POST", "/clear", nil)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestHandlerDashboard(t *testing.T) {
+	c := cache.NewCache[string, string](time.Minute, cache.WithAccessTracking[string, string]())
+	defer c.StopCleanup()
+	c.Set("user:1", "ann")
+	c.Set("user:2", "bob")
+	c.Set("order:1", "x")
+	c.Get("user:2")
+	h := Handler(c)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rec.Body.String(), "<title>Cache dashboard</title>")
+
+	var page Page
+	do(t, h, "GET", "/keys?prefix=user:", &page)
+	assert.Equal(t, []string{"user:1", "user:2"}, page.Keys)
+
+	var top []cache.KeyStat[string]
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/top?n=1", &top))
+	assert.Len(t, top, 1)
+	assert.Equal(t, "user:2", top[0].Key)
+	assert.Equal(t, http.StatusBadRequest, do(t, h, "GET", "/top?n=0", nil))
+
+	var mem Memory
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/memory", &mem))
+	assert.Positive(t, mem.Bytes)
+}
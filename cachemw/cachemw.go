@@ -0,0 +1,199 @@
+// Package cachemw provides net/http middleware that caches rendered
+// responses.
+package cachemw
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+const (
+	defaultTTL     = time.Minute
+	defaultMaxBody = 1 << 20
+)
+
+// Response is a cached response.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type Options struct {
+	// TTL is how long a response is served from the cache. Defaults to a
+	// minute.
+	TTL time.Duration
+	// Vary lists request headers whose values are part of the cache key,
+	// such as Accept-Encoding or Accept-Language.
+	Vary []string
+	// MaxBodySize bounds the size of a cached response body; larger
+	// responses are sent but not cached. Defaults to 1 MiB.
+	MaxBodySize int
+	// Cache holds the responses. Supplying one allows it to be cleared or
+	// shared between handlers; by default each Handler creates its own.
+	Cache *cache.Cache[string, *Response]
+}
+
+// Handler caches the responses of next to GET and HEAD requests, keyed by
+// method, host, URL, the Vary headers of the options and those named by the
+// response's own Vary header. Only 200 responses are cached, and never those
+// that set cookies, are marked no-store or private, or vary on *. Responses
+// to requests carrying Authorization or Cookie headers are only cached, and
+// such requests only served from the cache, when marked public. Responses
+// carry an X-Cache header of HIT or MISS.
+func Handler(next http.Handler, opts Options) http.Handler {
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBody
+	}
+	if opts.Cache == nil {
+		opts.Cache = cache.NewCache[string, *Response](opts.TTL)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		credentialed := r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+		base := cacheKey(r, opts.Vary)
+		key := base
+		if variants, ok := opts.Cache.Get(base); ok && variants.Status == 0 {
+			key = variantKey(base, r, variants.Header.Values("Vary"))
+		}
+		if resp, ok := opts.Cache.Get(key); ok && resp.Status != 0 && (!credentialed || public(resp.Header)) {
+			serve(w, resp)
+			return
+		}
+		w.Header().Set("X-Cache", "MISS")
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: opts.MaxBodySize}
+		next.ServeHTTP(rec, r)
+		if !rec.cacheable() || credentialed && !public(rec.Header()) {
+			return
+		}
+		header := w.Header().Clone()
+		header.Del("X-Cache")
+		resp := &Response{Status: rec.status, Header: header, Body: rec.body.Bytes()}
+		vary := varyNames(header)
+		if len(vary) == 0 {
+			_ = opts.Cache.SetWithTTL(base, resp, opts.TTL)
+			return
+		}
+		// Remember the response's Vary headers under the base key, as a
+		// Response without a status, so lookups can find the variant.
+		_ = opts.Cache.SetWithTTL(base, &Response{Header: http.Header{"Vary": vary}}, opts.TTL)
+		_ = opts.Cache.SetWithTTL(variantKey(base, r, vary), resp, opts.TTL)
+	})
+}
+
+func cacheKey(r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(r.Host)
+	b.WriteString(r.URL.RequestURI())
+	writeVary(&b, r, vary)
+	return b.String()
+}
+
+// variantKey extends base with the request headers a response varies on.
+func variantKey(base string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	b.WriteByte(0)
+	writeVary(&b, r, vary)
+	return b.String()
+}
+
+func writeVary(b *strings.Builder, r *http.Request, vary []string) {
+	for _, name := range vary {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+}
+
+// varyNames returns the header names listed by the Vary header of h.
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+func public(h http.Header) bool {
+	return strings.Contains(strings.ToLower(h.Get("Cache-Control")), "public")
+}
+
+func serve(w http.ResponseWriter, resp *Response) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = v
+	}
+	h.Set("X-Cache", "HIT")
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// recorder passes a response through while keeping a copy of it, until the
+// body grows past limit.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	limit       int
+	overflow    bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if r.body.Len()+len(p) > r.limit {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *recorder) cacheable() bool {
+	if r.status != http.StatusOK || r.overflow {
+		return false
+	}
+	h := r.Header()
+	if h.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, name := range varyNames(h) {
+		if name == "*" {
+			return false
+		}
+	}
+	cc := strings.ToLower(h.Get("Cache-Control"))
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
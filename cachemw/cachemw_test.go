@@ -0,0 +1,118 @@
+package cachemw
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func do(h http.Handler, method, target string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandlerCaches(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%s %d", r.Header.Get("Accept-Language"), calls)
+	}), Options{TTL: time.Minute, Vary: []string{"Accept-Language"}})
+
+	w := do(h, http.MethodGet, "/page")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, " 1", w.Body.String())
+	w = do(h, http.MethodGet, "/page")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, " 1", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+
+	w = do(h, http.MethodGet, "/page", "Accept-Language", "de")
+	assert.Equal(t, "de 2", w.Body.String(), "Expected Vary headers to be part of the key")
+	w = do(h, http.MethodGet, "/page?x=1")
+	assert.Equal(t, " 3", w.Body.String())
+	do(h, http.MethodPost, "/page")
+	assert.Equal(t, 4, calls, "Expected POST to bypass the cache")
+}
+
+func TestHandlerSkipsUncacheable(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/error":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		case "/cookie":
+			http.SetCookie(w, &http.Cookie{Name: "s", Value: "1"})
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", 10)))
+			w.Write([]byte(strings.Repeat("x", 10)))
+		}
+	}), Options{MaxBodySize: 16})
+
+	for _, path := range []string{"/error", "/cookie", "/private", "/big"} {
+		do(h, http.MethodGet, path)
+		w := do(h, http.MethodGet, path)
+		assert.Equal(t, "MISS", w.Header().Get("X-Cache"), path)
+	}
+	assert.Equal(t, 8, calls)
+	assert.Len(t, do(h, http.MethodGet, "/big").Body.String(), 20, "Expected large bodies to be sent in full")
+}
+
+func TestHandlerCredentials(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		fmt.Fprintf(w, "%s %d", r.Header.Get("Authorization"), calls)
+	}), Options{})
+
+	do(h, http.MethodGet, "/me", "Authorization", "alice")
+	w := do(h, http.MethodGet, "/me", "Authorization", "bob")
+	assert.Equal(t, "bob 2", w.Body.String(), "Expected authorized responses not to be cached")
+	do(h, http.MethodGet, "/page")
+	w = do(h, http.MethodGet, "/page", "Cookie", "session=alice")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"), "Expected requests with cookies to skip responses not marked public")
+
+	do(h, http.MethodGet, "/public", "Authorization", "alice")
+	w = do(h, http.MethodGet, "/public", "Cookie", "session=bob")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "alice 5", w.Body.String())
+}
+
+func TestHandlerResponseVary(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Vary", "Accept-Encoding")
+		if r.URL.Path == "/any" {
+			w.Header().Set("Vary", "*")
+		}
+		fmt.Fprintf(w, "%s %d", r.Header.Get("Accept-Encoding"), calls)
+	}), Options{})
+
+	do(h, http.MethodGet, "/page", "Accept-Encoding", "gzip")
+	w := do(h, http.MethodGet, "/page", "Accept-Encoding", "gzip")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"))
+	assert.Equal(t, "gzip 1", w.Body.String())
+	w = do(h, http.MethodGet, "/page", "Accept-Encoding", "br")
+	assert.Equal(t, "br 2", w.Body.String(), "Expected the response's Vary headers to be part of the key")
+
+	do(h, http.MethodGet, "/any")
+	w = do(h, http.MethodGet, "/any")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"), "Expected Vary: * responses not to be cached")
+}
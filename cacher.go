@@ -0,0 +1,28 @@
+package cache
+
+// Getter is the read half of a cache. Code that only looks values up should
+// depend on it, so tests can pass a map-backed fake or a NopCache.
+type Getter[T comparable, V any] interface {
+	Get(key T) (V, bool)
+}
+
+// Setter is the write half of a cache.
+type Setter[T comparable, V any] interface {
+	Set(key T, value V)
+}
+
+// Cacher is the common interface implemented by the caches in this package.
+type Cacher[T comparable, V any] interface {
+	Getter[T, V]
+	Setter[T, V]
+	Delete(key T)
+	Clear()
+}
+
+var (
+	_ Cacher[int, int]    = (*Cache[int, int])(nil)
+	_ Cacher[int, int]    = (*LocalCache[int, int])(nil)
+	_ Cacher[string, int] = (*StringCache[int])(nil)
+	_ Cacher[int, int]    = (*FallbackChain[int, int])(nil)
+	_ Getter[int, int]    = ReadOnlyCache[int, int](nil)
+)
@@ -0,0 +1,83 @@
+// Package cachetest provides a model-based test harness for cache.Cacher
+// implementations.
+package cachetest
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+type Config struct {
+	// Ops is the number of random operations to run. Defaults to 10000.
+	Ops int
+	// Keys bounds the key space so that operations collide. Defaults to 64.
+	Keys int
+	// Seed makes a run reproducible. Zero picks a random seed, which is
+	// logged on failure.
+	Seed int64
+	// TTL is the expiration configured on the cache under test. Entries
+	// older than TTL may be reported either present or absent, since
+	// implementations are allowed to expire lazily.
+	TTL time.Duration
+	// Slack widens the window in which an entry may be reported either way
+	// to TTL-Slack, for implementations that read a coarse clock.
+	Slack time.Duration
+}
+
+type modelEntry[V any] struct {
+	value   V
+	written time.Time
+}
+
+// Check drives c with random Get/Set/Delete/Clear operations and compares
+// every observable result against a reference map+TTL model. keyOf and
+// valueOf map small integers onto the key and value types under test.
+func Check[K comparable, V any](t testing.TB, c cache.Cacher[K, V], keyOf func(int) K, valueOf func(int) V, cfg Config) {
+	t.Helper()
+	if cfg.Ops <= 0 {
+		cfg.Ops = 10000
+	}
+	if cfg.Keys <= 0 {
+		cfg.Keys = 64
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(cfg.Seed))
+	model := make(map[K]modelEntry[V])
+
+	for i := 0; i < cfg.Ops; i++ {
+		key := keyOf(r.Intn(cfg.Keys))
+		switch op := r.Intn(100); {
+		case op < 45:
+			got, ok := c.Get(key)
+			want, exists := model[key]
+			if exists && cfg.TTL > 0 && time.Since(want.written) >= cfg.TTL-cfg.Slack {
+				if ok && !reflect.DeepEqual(got, want.value) {
+					t.Fatalf("seed %d op %d: Get(%v) on expired entry = %v, want %v or miss", cfg.Seed, i, key, got, want.value)
+				}
+				continue
+			}
+			if ok != exists {
+				t.Fatalf("seed %d op %d: Get(%v) found = %v, want %v (age %v)", cfg.Seed, i, key, ok, exists, time.Since(want.written))
+			}
+			if ok && !reflect.DeepEqual(got, want.value) {
+				t.Fatalf("seed %d op %d: Get(%v) = %v, want %v", cfg.Seed, i, key, got, want.value)
+			}
+		case op < 85:
+			value := valueOf(r.Int())
+			c.Set(key, value)
+			model[key] = modelEntry[V]{value: value, written: time.Now()}
+		case op < 99:
+			c.Delete(key)
+			delete(model, key)
+		default:
+			c.Clear()
+			clear(model)
+		}
+	}
+}
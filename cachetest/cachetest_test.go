@@ -0,0 +1,43 @@
+package cachetest
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+func itoa(i int) string { return strconv.Itoa(i) }
+
+func identity(i int) int { return i }
+
+func TestCheckCache(t *testing.T) {
+	c := cache.NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	Check[string, int](t, c, itoa, identity, Config{TTL: time.Minute})
+}
+
+func TestCheckLocalCache(t *testing.T) {
+	c := cache.NewLocalCache[string, int](time.Minute, 0)
+	Check[string, int](t, c, itoa, identity, Config{TTL: time.Minute})
+}
+
+func TestCheckShortTTL(t *testing.T) {
+	c := cache.NewCache[int, int](20*time.Millisecond, cache.WithExactTime[int, int]())
+	defer c.StopCleanup()
+	Check[int, int](t, c, identity, identity, Config{Ops: 20000, TTL: 20 * time.Millisecond, Slack: 10 * time.Millisecond})
+}
+
+func TestCheckStdlibStores(t *testing.T) {
+	for name, newStore := range map[string]func() cache.Store[string, int]{
+		"sharded": func() cache.Store[string, int] { return cache.NewShardedMapStore[string, int](16) },
+		"syncmap": cache.NewSyncMapStore[string, int],
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := cache.NewCache[string, int](time.Minute, cache.WithStore[string, int](newStore))
+			defer c.StopCleanup()
+			Check[string, int](t, c, itoa, identity, Config{TTL: time.Minute})
+		})
+	}
+}
@@ -0,0 +1,302 @@
+// Package cachetransport provides an http.RoundTripper that caches GET
+// responses in memory.
+package cachetransport
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+const (
+	defaultMaxBody    = 1 << 20
+	defaultMaxSize    = 64 << 20
+	defaultRevalidate = time.Hour
+
+	// XFromCache is set on responses served from the cache.
+	XFromCache = "X-From-Cache"
+)
+
+type Config struct {
+	// Base performs the requests that miss. Defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+	// TTL, if set, caches every cacheable response for this long and
+	// ignores the freshness headers sent by the server.
+	TTL time.Duration
+	// Revalidate is how long responses carrying an ETag or Last-Modified
+	// header are kept after they go stale, so they can be revalidated with
+	// a conditional request instead of refetched. Defaults to an hour.
+	Revalidate time.Duration
+	// MaxBodySize bounds the size of a cached body. Larger responses are
+	// passed through uncached. Defaults to 1 MiB.
+	MaxBodySize int64
+	// MaxSize bounds the total size of the cached responses, counting
+	// their URLs, headers and bodies. The least recently used ones are
+	// evicted to make room. Defaults to 64 MiB.
+	MaxSize int64
+}
+
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+	// vary holds the request headers named by the response's Vary header,
+	// which later requests must match to be served this response.
+	vary http.Header
+}
+
+// Transport caches successful responses to GET requests keyed by URL. It
+// honors Cache-Control max-age, no-cache and no-store and the Expires header,
+// and revalidates stale responses using ETag and Last-Modified. One variant
+// is kept per URL: a request whose headers differ from the cached response's
+// in a header named by its Vary header is a miss, and responses with Vary: *
+// are not cached. Requests carrying credentials in an Authorization or
+// Cookie header bypass the cache, so that one caller's responses are never
+// served to another.
+type Transport struct {
+	base  http.RoundTripper
+	cfg   Config
+	cache *cache.Cache[string, *cachedResponse]
+}
+
+func New(cfg Config) *Transport {
+	if cfg.Base == nil {
+		cfg.Base = http.DefaultTransport
+	}
+	if cfg.Revalidate <= 0 {
+		cfg.Revalidate = defaultRevalidate
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxBody
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSize
+	}
+	return &Transport{
+		base: cfg.Base,
+		cfg:  cfg,
+		cache: cache.NewCache[string, *cachedResponse](time.Minute,
+			cache.WithMaxCost[string, *cachedResponse](cfg.MaxSize),
+			cache.WithWeigher(func(key string, r *cachedResponse) int64 {
+				return int64(len(key)) + r.size()
+			})),
+	}
+}
+
+// Close stops the cache's cleanup routine.
+func (t *Transport) Close() {
+	t.cache.StopCleanup()
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" ||
+		req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+		return t.base.RoundTrip(req)
+	}
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return t.base.RoundTrip(req)
+	}
+	key := req.URL.String()
+	cached, ok := t.cache.Get(key)
+	ok = ok && cached.matches(req)
+	if _, noCache := reqCC["no-cache"]; noCache {
+		ok = ok && cached.validator()
+	} else if ok && time.Now().Before(cached.expires) {
+		return cached.response(req), nil
+	}
+
+	outreq := req
+	if ok {
+		outreq = req.Clone(req.Context())
+		if etag := cached.header.Get("ETag"); etag != "" {
+			outreq.Header.Set("If-None-Match", etag)
+		}
+		if lm := cached.header.Get("Last-Modified"); lm != "" {
+			outreq.Header.Set("If-Modified-Since", lm)
+		}
+	}
+	resp, err := t.base.RoundTrip(outreq)
+	if err != nil {
+		return nil, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		refreshed := &cachedResponse{status: cached.status, header: cached.header.Clone(), body: cached.body}
+		for k, v := range resp.Header {
+			refreshed.header[k] = v
+		}
+		t.store(key, req, refreshed)
+		return refreshed.response(req), nil
+	}
+	if !cacheable(resp.StatusCode) {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.cfg.MaxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.cfg.MaxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store(key, req, &cachedResponse{status: resp.StatusCode, header: resp.Header.Clone(), body: body})
+	return resp, nil
+}
+
+// store computes the freshness of resp from its headers and caches it if it
+// is either fresh or can be revalidated, recording the headers of req it
+// varies on.
+func (t *Transport) store(key string, req *http.Request, resp *cachedResponse) {
+	cc := parseCacheControl(resp.header)
+	vary, ok := varyHeaders(req, resp.header)
+	if _, noStore := cc["no-store"]; noStore || !ok {
+		t.cache.Delete(key)
+		return
+	}
+	resp.vary = vary
+	fresh := t.cfg.TTL
+	if fresh == 0 {
+		fresh = freshness(resp.header, cc)
+	}
+	resp.expires = time.Now().Add(fresh)
+	keep := fresh
+	if resp.validator() {
+		keep += t.cfg.Revalidate
+	}
+	if keep <= 0 {
+		t.cache.Delete(key)
+		return
+	}
+	_ = t.cache.SetWithTTL(key, resp, keep)
+}
+
+// varyHeaders returns the headers of req named by the Vary header in h,
+// reporting false for Vary: *, which no later request can match.
+func varyHeaders(req *http.Request, h http.Header) (http.Header, bool) {
+	var vary http.Header
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			switch name {
+			case "":
+				continue
+			case "*":
+				return nil, false
+			}
+			if vary == nil {
+				vary = make(http.Header)
+			}
+			vary[name] = req.Header.Values(name)
+		}
+	}
+	return vary, true
+}
+
+// matches reports whether req sends the same values as the request r was
+// cached for in every header r varies on.
+func (r *cachedResponse) matches(req *http.Request) bool {
+	for name, values := range r.vary {
+		if !slices.Equal(req.Header.Values(name), values) {
+			return false
+		}
+	}
+	return true
+}
+
+// size estimates the memory held by r.
+func (r *cachedResponse) size() int64 {
+	n := len(r.body)
+	for _, h := range []http.Header{r.header, r.vary} {
+		for k, vs := range h {
+			n += len(k)
+			for _, v := range vs {
+				n += len(v)
+			}
+		}
+	}
+	return int64(n)
+}
+
+func (r *cachedResponse) validator() bool {
+	return r.header.Get("ETag") != "" || r.header.Get("Last-Modified") != ""
+}
+
+func (r *cachedResponse) response(req *http.Request) *http.Response {
+	header := r.header.Clone()
+	header.Set(XFromCache, "1")
+	return &http.Response{
+		Status:        strconv.Itoa(r.status) + " " + http.StatusText(r.status),
+		StatusCode:    r.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
+
+func cacheable(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// freshness returns how long a response stays fresh according to its
+// Cache-Control and Expires headers.
+func freshness(h http.Header, cc map[string]string) time.Duration {
+	if _, ok := cc["no-cache"]; ok {
+		return 0
+	}
+	if v, ok := cc["max-age"]; ok {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if v := h.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		return expires.Sub(date)
+	}
+	return 0
+}
+
+func parseCacheControl(h http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
@@ -0,0 +1,215 @@
+package cachetransport
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, client *http.Client, url string) (string, *http.Response) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body), resp
+}
+
+func TestTransportMaxAge(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		fmt.Fprint(w, n)
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	body, resp := get(t, client, srv.URL+"/fresh")
+	assert.Equal(t, "1", body)
+	assert.Empty(t, resp.Header.Get(XFromCache))
+	body, resp = get(t, client, srv.URL+"/fresh")
+	assert.Equal(t, "1", body, "Expected a fresh response to be served from the cache")
+	assert.Equal(t, "1", resp.Header.Get(XFromCache))
+
+	get(t, client, srv.URL+"/nostore")
+	body, _ = get(t, client, srv.URL+"/nostore")
+	assert.Equal(t, "3", body, "Expected no-store responses not to be cached")
+
+	body, _ = get(t, client, srv.URL+"/plain")
+	assert.Equal(t, "4", body)
+	body, _ = get(t, client, srv.URL+"/plain")
+	assert.Equal(t, "5", body, "Expected responses without freshness information not to be reused")
+
+	resp, err := client.Post(srv.URL+"/fresh", "text/plain", strings.NewReader("x"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(6), hits.Load(), "Expected POST to bypass the cache")
+}
+
+func TestTransportRevalidatesETag(t *testing.T) {
+	var full, conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		fmt.Fprint(w, "payload")
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	get(t, client, srv.URL)
+	body, resp := get(t, client, srv.URL)
+	assert.Equal(t, "payload", body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(XFromCache))
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(1), conditional.Load(), "Expected the stale response to be revalidated")
+}
+
+func TestTransportForcedTTL(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, hits.Add(1))
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport, TTL: time.Minute})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	get(t, client, srv.URL)
+	body, _ := get(t, client, srv.URL)
+	assert.Equal(t, "1", body)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Cache-Control", "no-store")
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(2), hits.Load(), "Expected no-store requests to bypass the cache")
+}
+
+func TestTransportMaxBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport, TTL: time.Minute, MaxBodySize: 4})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	body, _ := get(t, client, srv.URL)
+	assert.Equal(t, "0123456789", body, "Expected oversized bodies to be passed through intact")
+	_, resp := get(t, client, srv.URL)
+	assert.Empty(t, resp.Header.Get(XFromCache))
+}
+
+func TestTransportSkipsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, r.Header.Get("Authorization"), r.Header.Get("Cookie"))
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	for _, h := range [][2]string{{"Authorization", "Bearer alice"}, {"Cookie", "session=alice"}} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set(h[0], h[1])
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		body, resp := get(t, client, srv.URL)
+		assert.Empty(t, body, "Expected a credentialed response not to be served to another caller")
+		assert.Empty(t, resp.Header.Get(XFromCache))
+		tr.cache.Clear()
+	}
+}
+
+func TestTransportVary(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		if r.URL.Path == "/star" {
+			w.Header().Set("Vary", "*")
+		}
+		fmt.Fprint(w, r.Header.Get("Accept-Language"))
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+	getLang := func(path, lang string) (string, *http.Response) {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		req.Header.Set("Accept-Language", lang)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), resp
+	}
+
+	getLang("/", "en")
+	body, resp := getLang("/", "en")
+	assert.Equal(t, "en", body)
+	assert.Equal(t, "1", resp.Header.Get(XFromCache))
+	body, resp = getLang("/", "fr")
+	assert.Equal(t, "fr", body, "Expected a request differing in a Vary header to miss")
+	assert.Empty(t, resp.Header.Get(XFromCache))
+
+	getLang("/star", "en")
+	_, resp = getLang("/star", "en")
+	assert.Empty(t, resp.Header.Get(XFromCache), "Expected Vary: * responses not to be cached")
+	assert.Equal(t, int32(4), hits.Load())
+}
+
+func TestTransportMaxSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", 1000))
+	}))
+	defer srv.Close()
+	tr := New(Config{Base: srv.Client().Transport, TTL: time.Minute, MaxSize: 10000})
+	defer tr.Close()
+	client := &http.Client{Transport: tr}
+
+	for i := range 50 {
+		get(t, client, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+	assert.Less(t, tr.cache.Len(), 10, "Expected the total size to be bounded")
+	_, resp := get(t, client, srv.URL+"/49")
+	assert.Equal(t, "1", resp.Header.Get(XFromCache), "Expected recent responses to be kept")
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// clockBase anchors the process-wide monotonic clock. Deadlines are stored as
+// int64 nanoseconds since clockBase, which is cheaper to store and compare
+// than time.Time and unaffected by wall clock jumps.
+var clockBase = time.Now()
+
+func nanotime() int64 {
+	return int64(time.Since(clockBase))
+}
+
+func wallTime(n int64) time.Time {
+	return clockBase.Add(time.Duration(n))
+}
+
+const coarseResolution = time.Millisecond
+
+// coarse runs the goroutine behind coarseNow. It is reference counted by the
+// caches reading it, so the ticker only runs while one of them is live.
+var coarse struct {
+	mu   sync.Mutex
+	refs int
+	stop chan struct{}
+	done chan struct{}
+}
+
+// coarseClock holds the time published by the coarse goroutine, or 0 while
+// it is not running.
+var coarseClock atomic.Int64
+
+// coarseNow returns nanotime truncated to coarseResolution. It is read from a
+// shared atomic updated by a single process-wide goroutine, which is much
+// cheaper than calling time.Now on every operation. Without a live
+// acquireCoarse it falls back to nanotime.
+func coarseNow() int64 {
+	if now := coarseClock.Load(); now != 0 {
+		return now
+	}
+	return nanotime()
+}
+
+// acquireCoarse starts the coarse goroutine unless it is already running.
+// Every call must be paired with a releaseCoarse.
+func acquireCoarse() {
+	coarse.mu.Lock()
+	defer coarse.mu.Unlock()
+	if coarse.refs++; coarse.refs > 1 {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	coarse.stop, coarse.done = stop, done
+	coarseClock.Store(nanotime())
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(coarseResolution)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				coarseClock.Store(nanotime())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// releaseCoarse drops a reference taken by acquireCoarse, stopping the
+// coarse goroutine when it was the last one.
+func releaseCoarse() {
+	coarse.mu.Lock()
+	defer coarse.mu.Unlock()
+	if coarse.refs--; coarse.refs > 0 {
+		return
+	}
+	close(coarse.stop)
+	<-coarse.done
+	coarseClock.Store(0)
+	coarse.stop, coarse.done = nil, nil
+}
+
+// Clock is the source of time for a Cache. Replacing it with a fake, such as
+// the one in the clocktest package, lets tests drive expiry without sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
+
+func (c *Cache[T, V]) now() int64 {
+	switch {
+	case c.clock != nil:
+		return int64(c.clock.Now().Sub(clockBase))
+	case c.exactTime:
+		return nanotime()
+	}
+	return coarseNow()
+}
+
+// nanotime is now without the coarse clock.
+func (c *Cache[T, V]) nanotime() int64 {
+	if c.clock != nil {
+		return int64(c.clock.Now().Sub(clockBase))
+	}
+	return nanotime()
+}
+
+// wallNow returns the current time for deadlines kept as time.Time.
+func (c *Cache[T, V]) wallNow() time.Time {
+	if c.clock != nil {
+		return c.clock.Now()
+	}
+	return time.Now()
+}
+
+func (c *Cache[T, V]) newTicker(d time.Duration) Ticker {
+	if c.clock != nil {
+		return c.clock.NewTicker(d)
+	}
+	return systemClock{}.NewTicker(d)
+}
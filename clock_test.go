@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoarseClockAdvances(t *testing.T) {
+	acquireCoarse()
+	defer releaseCoarse()
+	start := coarseNow()
+	// The ticker goroutine can be descheduled for a while on a loaded
+	// machine, so wait for it rather than for a fixed time.
+	assert.Eventually(t, func() bool { return coarseNow() > start }, time.Second, time.Millisecond)
+	assert.LessOrEqual(t, coarseNow(), nanotime(), "Expected the coarse clock never to run ahead")
+}
+
+func TestCoarseClockRefCount(t *testing.T) {
+	refs := func() int {
+		coarse.mu.Lock()
+		defer coarse.mu.Unlock()
+		return coarse.refs
+	}
+	base := refs()
+	c := NewCache[int, string](time.Minute)
+	ns := c.Namespace("ns")
+	assert.Equal(t, base+2, refs(), "Expected the cache and its namespace to hold the coarse clock")
+	exact := NewCache[int, string](time.Minute, WithExactTime[int, string]())
+	assert.Equal(t, base+2, refs(), "Expected WithExactTime caches not to start the coarse clock")
+	exact.StopCleanup()
+
+	ns.StopCleanup()
+	c.StopCleanup()
+	c.StopCleanup()
+	assert.Equal(t, base, refs(), "Expected StopCleanup to release the coarse clock once")
+	if base == 0 {
+		assert.Zero(t, coarseClock.Load(), "Expected the coarse clock to stop with its last cache")
+		assert.InDelta(t, nanotime(), coarseNow(), float64(time.Millisecond))
+	}
+}
+
+func TestCacheExactTime(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithExactTime[int, string]())
+	defer cache.StopCleanup()
+
+	before := nanotime()
+	cache.Set(1, "test1")
+	item, _ := cache.items().Get(1)
+	assert.GreaterOrEqual(t, item.expires, before+int64(time.Minute))
+}
+
+func BenchmarkCoarseNow(b *testing.B) {
+	acquireCoarse()
+	defer releaseCoarse()
+	for i := 0; i < b.N; i++ {
+		coarseNow()
+	}
+}
+
+func BenchmarkNanotime(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		nanotime()
+	}
+}
@@ -0,0 +1,108 @@
+// Package clocktest provides a manually advanced cache.Clock for tests.
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// Clock is a cache.Clock that only moves when Advance or Set is called.
+type Clock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	tickers []*ticker
+}
+
+// NewClock returns a Clock reading start.
+func NewClock(start time.Time) *Clock {
+	c := &Clock{now: start}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker that fires as Advance moves the clock past each
+// multiple of d. Like time.Ticker it drops ticks nobody is waiting for.
+func (c *Clock) NewTicker(d time.Duration) cache.Ticker {
+	if d <= 0 {
+		panic("clocktest: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &ticker{clock: c, period: d, next: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	c.changed.Broadcast()
+	return t
+}
+
+// WaitForTickers blocks until at least n tickers are running. A cache creates
+// its tickers on background goroutines, so tests call this before the first
+// Advance that the cleanup routine should observe.
+func (c *Clock) WaitForTickers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.tickers) < n {
+		c.changed.Wait()
+	}
+}
+
+// Advance moves the clock forward by d, firing any tickers that come due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Set moves the clock to t, firing any tickers that come due. Moving it
+// backwards does not fire anything.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+	c.fire()
+}
+
+func (c *Clock) fire() {
+	for _, t := range c.tickers {
+		if t.next.After(c.now) {
+			continue
+		}
+		for !t.next.After(c.now) {
+			t.next = t.next.Add(t.period)
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+	}
+}
+
+type ticker struct {
+	clock  *Clock
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+func (t *ticker) C() <-chan time.Time { return t.ch }
+
+func (t *ticker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.tickers {
+		if other == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
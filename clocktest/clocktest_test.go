@@ -0,0 +1,61 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockTicker(t *testing.T) {
+	start := time.Now()
+	clock := NewClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-ticker.C():
+		t.Fatal("Expected no tick before the period elapsed")
+	default:
+	}
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, start.Add(3999*time.Millisecond), <-ticker.C())
+	select {
+	case <-ticker.C():
+		t.Fatal("Expected missed ticks to be dropped")
+	default:
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	select {
+	case <-ticker.C():
+		t.Fatal("Expected a stopped ticker not to fire")
+	default:
+	}
+}
+
+func TestCacheWithClock(t *testing.T) {
+	clock := NewClock(time.Now())
+	c := cache.NewCache[string, int](time.Minute, cache.WithClock[string, int](clock))
+	defer c.StopCleanup()
+	clock.WaitForTickers(1)
+
+	assert.NoError(t, c.SetWithTTL("short", 1, time.Second))
+	c.Set("default", 2)
+	ttl, _ := c.TTL("default")
+	assert.Equal(t, time.Minute, ttl)
+
+	clock.Advance(2 * time.Second)
+	_, found := c.Get("short")
+	assert.False(t, found, "Expected the entry to expire without sleeping")
+	ttl, _ = c.TTL("default")
+	assert.Equal(t, 58*time.Second, ttl)
+
+	clock.Advance(2 * time.Minute)
+	assert.Eventually(t, func() bool {
+		_, found := c.Get("default")
+		return !found
+	}, time.Second, time.Millisecond, "Expected the cleanup routine to run on the fake clock")
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// client speaks just enough RESP or memcached to get, set and fetch stats.
+type client struct {
+	proto string
+	conn  net.Conn
+	r     *bufio.Reader
+	w     *bufio.Writer
+}
+
+func dial(proto, addr string) (*client, error) {
+	if proto != "resp" && proto != "memcached" {
+		return nil, fmt.Errorf("unsupported -proto %q", proto)
+	}
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &client{proto: proto, conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}, nil
+}
+
+func (c *client) Close() error { return c.conn.Close() }
+
+func (c *client) line() (string, error) {
+	s, err := c.r.ReadString('\n')
+	return strings.TrimRight(s, "\r\n"), err
+}
+
+func (c *client) command(args ...string) error {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return c.w.Flush()
+}
+
+// bulk reads a RESP bulk string reply, reporting false for a null one.
+func (c *client) bulk() ([]byte, bool, error) {
+	head, err := c.line()
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.HasPrefix(head, "$") {
+		return nil, false, fmt.Errorf("unexpected reply %q", head)
+	}
+	n, err := strconv.Atoi(head[1:])
+	if err != nil || n < 0 {
+		return nil, false, err
+	}
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, false, err
+	}
+	return data[:n], true, nil
+}
+
+func (c *client) set(key string, value []byte) error {
+	if c.proto == "resp" {
+		if err := c.command("SET", key, string(value)); err != nil {
+			return err
+		}
+		reply, err := c.line()
+		if err == nil && reply != "+OK" {
+			err = fmt.Errorf("SET: %s", reply)
+		}
+		return err
+	}
+	fmt.Fprintf(c.w, "set %s 0 0 %d\r\n%s\r\n", key, len(value), value)
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	reply, err := c.line()
+	if err == nil && reply != "STORED" {
+		err = fmt.Errorf("set: %s", reply)
+	}
+	return err
+}
+
+func (c *client) get(key string) (bool, error) {
+	if c.proto == "resp" {
+		if err := c.command("GET", key); err != nil {
+			return false, err
+		}
+		_, ok, err := c.bulk()
+		return ok, err
+	}
+	fmt.Fprintf(c.w, "get %s\r\n", key)
+	if err := c.w.Flush(); err != nil {
+		return false, err
+	}
+	hit := false
+	for {
+		reply, err := c.line()
+		if err != nil {
+			return false, err
+		}
+		if reply == "END" {
+			return hit, nil
+		}
+		f := strings.Fields(reply)
+		if len(f) < 4 || f[0] != "VALUE" {
+			return false, fmt.Errorf("get: %s", reply)
+		}
+		n, err := strconv.Atoi(f[3])
+		if err != nil {
+			return false, err
+		}
+		if _, err := c.r.Discard(n + 2); err != nil {
+			return false, err
+		}
+		hit = true
+	}
+}
+
+// stats returns the server's statistics as text.
+func (c *client) stats() (string, error) {
+	if c.proto == "resp" {
+		if err := c.command("INFO"); err != nil {
+			return "", err
+		}
+		data, _, err := c.bulk()
+		return strings.ReplaceAll(string(data), "\r\n", "\n"), err
+	}
+	fmt.Fprint(c.w, "stats\r\n")
+	if err := c.w.Flush(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for {
+		reply, err := c.line()
+		if err != nil {
+			return "", err
+		}
+		if reply == "END" {
+			return b.String(), nil
+		}
+		b.WriteString(strings.TrimPrefix(reply, "STAT ") + "\n")
+	}
+}
+
+type serverFlags struct {
+	proto, addr string
+	fs          *flag.FlagSet
+}
+
+func newServerFlags(name string) *serverFlags {
+	f := &serverFlags{fs: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.fs.StringVar(&f.proto, "proto", "resp", "protocol: resp or memcached")
+	f.fs.StringVar(&f.addr, "addr", "127.0.0.1:6379", "server address")
+	return f
+}
+
+func stats(args []string, out io.Writer) error {
+	f := newServerFlags("stats")
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	c, err := dial(f.proto, f.addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	text, err := c.stats()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, text)
+	return err
+}
+
+func bench(args []string, out io.Writer) error {
+	f := newServerFlags("bench")
+	clients := f.fs.Int("clients", 8, "concurrent connections")
+	requests := f.fs.Int("requests", 100000, "total requests")
+	keyspace := f.fs.Int("keyspace", 10000, "number of distinct keys")
+	size := f.fs.Int("size", 100, "value size in bytes")
+	reads := f.fs.Float64("reads", 0.9, "fraction of requests that are gets")
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if *clients < 1 || *requests < 1 || *keyspace < 1 || *size < 0 {
+		return errors.New("bench: -clients, -requests and -keyspace must be positive")
+	}
+	value := []byte(strings.Repeat("x", *size))
+
+	var mu sync.Mutex
+	var latencies []time.Duration
+	var hits, gets int
+	var firstErr error
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < *clients; i++ {
+		n := *requests / *clients
+		if i < *requests%*clients {
+			n++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lat, h, g, err := benchClient(f, n, *keyspace, *reads, value)
+			mu.Lock()
+			defer mu.Unlock()
+			latencies = append(latencies, lat...)
+			hits += h
+			gets += g
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	if firstErr != nil {
+		return firstErr
+	}
+	slices.Sort(latencies)
+	pct := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	fmt.Fprintf(out, "requests: %d in %s (%.0f/s)\n", len(latencies), elapsed.Round(time.Millisecond), float64(len(latencies))/elapsed.Seconds())
+	if gets > 0 {
+		fmt.Fprintf(out, "get hit ratio: %.3f\n", float64(hits)/float64(gets))
+	}
+	fmt.Fprintf(out, "latency p50: %s p99: %s max: %s\n", pct(0.5), pct(0.99), latencies[len(latencies)-1])
+	return nil
+}
+
+// benchClient sends n requests over one connection and returns their
+// latencies together with the number of get hits and gets.
+func benchClient(f *serverFlags, n, keyspace int, reads float64, value []byte) ([]time.Duration, int, int, error) {
+	c, err := dial(f.proto, f.addr)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer c.Close()
+	latencies := make([]time.Duration, 0, n)
+	hits, gets := 0, 0
+	for range n {
+		key := "bench:" + strconv.Itoa(rand.IntN(keyspace))
+		start := time.Now()
+		if rand.Float64() < reads {
+			hit, err := c.get(key)
+			if err != nil {
+				return latencies, hits, gets, err
+			}
+			gets++
+			if hit {
+				hits++
+			}
+		} else if err := c.set(key, value); err != nil {
+			return latencies, hits, gets, err
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+	return latencies, hits, gets, nil
+}
@@ -0,0 +1,49 @@
+// Command memcachectl inspects and converts cache snapshot files and
+// load-tests and queries caches served by the server package.
+//
+// Usage:
+//
+//	memcachectl inspect [-keys string|int] [-values bytes|json] [-v] FILE
+//	memcachectl convert [-keys string|int] [-values bytes|json] -to jsonl|snapshot IN OUT
+//	memcachectl bench [-proto resp|memcached] [-addr ADDR] [-clients N] [-requests N] [-keyspace N] [-size N] [-reads F]
+//	memcachectl stats [-proto resp|memcached] [-addr ADDR]
+//
+// Snapshot keys are decoded as -keys; values are kept as the bytes the
+// cache's codec produced, shown base64-encoded, or embedded as JSON with
+// -values json for caches using JSONCodec. -encryption-key takes the hex key
+// of a cache configured with WithEncryption.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var errUsage = errors.New("usage: memcachectl inspect|convert|bench|stats [flags] [args]")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "memcachectl:", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "inspect":
+		return inspect(args[1:], out)
+	case "convert":
+		return convert(args[1:], out)
+	case "bench":
+		return bench(args[1:], out)
+	case "stats":
+		return stats(args[1:], out)
+	default:
+		return errUsage
+	}
+}
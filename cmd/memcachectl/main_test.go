@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/NikoMalik/MemoryCache/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectAndConvert(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "cache.snap")
+	src := cache.NewCache[string, map[string]int](time.Hour, cache.WithCodec[string, map[string]int](cache.JSONCodec[map[string]int]{}))
+	src.Set("b", map[string]int{"n": 2})
+	src.Set("a", map[string]int{"n": 1})
+	assert.NoError(t, src.SaveFile(snapshot))
+	src.StopCleanup()
+
+	var out bytes.Buffer
+	assert.NoError(t, run([]string{"inspect", "-v", snapshot}, &out))
+	assert.Contains(t, out.String(), "entries: 2\n")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{"KEY", "TTL", "SIZE"}, strings.Fields(lines[2]))
+	assert.Equal(t, "a", strings.Fields(lines[3])[0], "Expected entries sorted by key")
+
+	jsonl := filepath.Join(dir, "cache.jsonl")
+	out.Reset()
+	assert.NoError(t, run([]string{"convert", "-values", "json", "-to", "jsonl", snapshot, jsonl}, &out))
+	assert.Equal(t, "converted 2 entries\n", out.String())
+	data, err := os.ReadFile(jsonl)
+	assert.NoError(t, err)
+	var first struct {
+		Key   string         `json:"key"`
+		Value map[string]int `json:"value"`
+	}
+	assert.NoError(t, json.Unmarshal(bytes.SplitN(data, []byte("\n"), 2)[0], &first))
+	assert.Equal(t, first.Value["n"], map[string]int{"a": 1, "b": 2}[first.Key], "Expected values embedded as JSON")
+
+	back := filepath.Join(dir, "back.snap")
+	assert.NoError(t, run([]string{"convert", "-values", "json", "-to", "snapshot", jsonl, back}, &out))
+	dst := cache.NewCache[string, map[string]int](time.Hour, cache.WithCodec[string, map[string]int](cache.JSONCodec[map[string]int]{}))
+	defer dst.StopCleanup()
+	assert.NoError(t, dst.LoadFile(back))
+	assert.Equal(t, map[string]map[string]int{"a": {"n": 1}, "b": {"n": 2}}, dst.Snapshot())
+
+	assert.ErrorIs(t, run([]string{"inspect"}, &out), errUsage)
+	assert.Error(t, run([]string{"inspect", "-keys", "float", snapshot}, &out))
+}
+
+func TestBenchAndStats(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	servers := map[string]interface {
+		Serve(net.Listener) error
+		Close() error
+	}{"resp": server.NewRESP(c), "memcached": server.NewMemcached(c)}
+
+	for proto, s := range servers {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		go s.Serve(l)
+		defer s.Close()
+		addr := l.Addr().String()
+
+		var out bytes.Buffer
+		err = run([]string{"bench", "-proto", proto, "-addr", addr, "-clients", "4", "-requests", "400", "-keyspace", "20", "-reads", "0.5"}, &out)
+		assert.NoError(t, err, proto)
+		assert.Contains(t, out.String(), "requests: 400 ", proto)
+		assert.Contains(t, out.String(), "get hit ratio: ", proto)
+
+		out.Reset()
+		assert.NoError(t, run([]string{"stats", "-proto", proto, "-addr", addr}, &out), proto)
+		found := false
+		for sc := bufio.NewScanner(&out); sc.Scan(); {
+			found = found || strings.HasPrefix(sc.Text(), "keyspace_hits:") || strings.HasPrefix(sc.Text(), "get_hits ")
+		}
+		assert.True(t, found, "Expected %s stats to report hits", proto)
+	}
+}
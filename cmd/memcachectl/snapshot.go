@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// rawCodec leaves values as the bytes another cache's codec produced.
+type rawCodec[V ~[]byte] struct{}
+
+func (rawCodec[V]) Encode(value V) ([]byte, error) { return value, nil }
+func (rawCodec[V]) Decode(data []byte) (V, error)  { return V(data), nil }
+
+type snapshotFlags struct {
+	keys, values, key string
+	fs                *flag.FlagSet
+}
+
+func newSnapshotFlags(name string) *snapshotFlags {
+	f := &snapshotFlags{fs: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.fs.StringVar(&f.keys, "keys", "string", "key type: string or int")
+	f.fs.StringVar(&f.values, "values", "bytes", "value encoding: bytes or json")
+	f.fs.StringVar(&f.key, "encryption-key", "", "hex AES key the snapshot was encrypted with")
+	return f
+}
+
+// snapshotTTL is long enough not to expire entries while they are handled;
+// restored entries keep their own deadlines.
+const snapshotTTL = 24 * time.Hour
+
+func newSnapshotCache[K comparable, V ~[]byte](f *snapshotFlags, hash func(K) uintptr) (*cache.Cache[K, V], error) {
+	opts := []cache.Option[K, V]{cache.WithCodec[K, V](rawCodec[V]{})}
+	if f.key != "" {
+		key, err := hex.DecodeString(f.key)
+		if err != nil {
+			return nil, fmt.Errorf("-encryption-key: %w", err)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("-encryption-key: %d bytes, want 16, 24 or 32", len(key))
+		}
+		opts = append(opts, cache.WithEncryption[K, V](key))
+	}
+	return cache.NewCacheComparable[K, V](snapshotTTL, hash, opts...), nil
+}
+
+// withTypes runs the variant of a command for the key and value types
+// selected by f.
+func withTypes(f *snapshotFlags, strBytes, intBytes, strJSON, intJSON func() error) error {
+	switch {
+	case f.keys == "string" && f.values == "bytes":
+		return strBytes()
+	case f.keys == "int" && f.values == "bytes":
+		return intBytes()
+	case f.keys == "string" && f.values == "json":
+		return strJSON()
+	case f.keys == "int" && f.values == "json":
+		return intJSON()
+	}
+	return fmt.Errorf("unsupported -keys %q or -values %q", f.keys, f.values)
+}
+
+func hashString(s string) uintptr {
+	h := uintptr(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h = (h ^ uintptr(s[i])) * 1099511628211
+	}
+	return h
+}
+
+func hashInt(n int64) uintptr {
+	return uintptr(n) * 0x9E3779B97F4A7C15
+}
+
+func inspect(args []string, out io.Writer) error {
+	f := newSnapshotFlags("inspect")
+	verbose := f.fs.Bool("v", false, "list every entry")
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if f.fs.NArg() != 1 {
+		return errUsage
+	}
+	path := f.fs.Arg(0)
+	return withTypes(f,
+		func() error { return inspectFile[string, []byte](f, hashString, path, *verbose, out) },
+		func() error { return inspectFile[int64, []byte](f, hashInt, path, *verbose, out) },
+		func() error { return inspectFile[string, json.RawMessage](f, hashString, path, *verbose, out) },
+		func() error { return inspectFile[int64, json.RawMessage](f, hashInt, path, *verbose, out) },
+	)
+}
+
+func inspectFile[K comparable, V ~[]byte](f *snapshotFlags, hash func(K) uintptr, path string, verbose bool, out io.Writer) error {
+	c, err := newSnapshotCache[K, V](f, hash)
+	if err != nil {
+		return err
+	}
+	defer c.StopCleanup()
+	if err := c.LoadFile(path); err != nil {
+		return err
+	}
+	type row struct {
+		key  string
+		ttl  time.Duration
+		size int
+	}
+	var rows []row
+	total := 0
+	c.Range(func(key K, value V) bool {
+		ttl, _ := c.TTL(key)
+		rows = append(rows, row{key: fmt.Sprint(key), ttl: ttl, size: len(value)})
+		total += len(value)
+		return true
+	})
+	sort.Slice(rows, func(i, j int) bool { return rows[i].key < rows[j].key })
+	fmt.Fprintf(out, "entries: %d\nvalue bytes: %d\n", len(rows), total)
+	if !verbose {
+		return nil
+	}
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tTTL\tSIZE")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", r.key, r.ttl.Round(time.Second), r.size)
+	}
+	return tw.Flush()
+}
+
+func convert(args []string, out io.Writer) error {
+	f := newSnapshotFlags("convert")
+	to := f.fs.String("to", "jsonl", "output format: jsonl or snapshot")
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if f.fs.NArg() != 2 || *to != "jsonl" && *to != "snapshot" {
+		return errUsage
+	}
+	in, dst := f.fs.Arg(0), f.fs.Arg(1)
+	toJSONL := *to == "jsonl"
+	return withTypes(f,
+		func() error { return convertFile[string, []byte](f, hashString, in, dst, toJSONL, out) },
+		func() error { return convertFile[int64, []byte](f, hashInt, in, dst, toJSONL, out) },
+		func() error { return convertFile[string, json.RawMessage](f, hashString, in, dst, toJSONL, out) },
+		func() error { return convertFile[int64, json.RawMessage](f, hashInt, in, dst, toJSONL, out) },
+	)
+}
+
+// convertFile turns the snapshot in into JSON Lines at dst, or the reverse.
+func convertFile[K comparable, V ~[]byte](f *snapshotFlags, hash func(K) uintptr, in, dst string, toJSONL bool, out io.Writer) error {
+	c, err := newSnapshotCache[K, V](f, hash)
+	if err != nil {
+		return err
+	}
+	defer c.StopCleanup()
+	if toJSONL {
+		if err := c.LoadFile(in); err != nil {
+			return err
+		}
+		w, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+		if err := c.ExportJSONL(w); err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	} else {
+		r, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		err = c.ImportJSONL(r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		if err := c.SaveFile(dst); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "converted %d entries\n", c.Len())
+	return nil
+}
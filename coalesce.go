@@ -0,0 +1,51 @@
+package cache
+
+import "time"
+
+// Coalescer collapses bursts of calls for the same key into a single
+// execution, for work such as sending a notification or delivering a webhook
+// that only needs to happen once per burst.
+type Coalescer[T comparable] struct {
+	pending *Cache[T, *time.Timer]
+}
+
+func NewCoalescer[T hashable]() *Coalescer[T] {
+	return &Coalescer[T]{pending: NewCache[T, *time.Timer](time.Minute)}
+}
+
+// Coalesce runs fn window after the first call for key, absorbing every
+// other call for key made in the meantime. It reports whether this call
+// started a new window, so fn is the one that will run. Calls made while fn
+// is running start the next window.
+func (c *Coalescer[T]) Coalesce(key T, window time.Duration, fn func()) bool {
+	t := time.AfterFunc(window, func() {
+		c.pending.Delete(key)
+		fn()
+	})
+	t.Stop()
+	if !c.pending.SetIfVersion(key, t, 0) {
+		return false
+	}
+	// Outlive the window so the cleanup routine cannot drop the entry
+	// before the timer does.
+	c.pending.Expire(key, window+time.Minute)
+	t.Reset(window)
+	return true
+}
+
+// Cancel discards the pending execution for key, reporting whether there was
+// one.
+func (c *Coalescer[T]) Cancel(key T) bool {
+	t, ok := c.pending.Get(key)
+	if !ok || !t.Stop() {
+		return false
+	}
+	c.pending.Delete(key)
+	return true
+}
+
+// Close stops the bookkeeping cache's cleanup routine. Pending executions
+// still run.
+func (c *Coalescer[T]) Close() {
+	c.pending.StopCleanup()
+}
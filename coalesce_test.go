@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalesce(t *testing.T) {
+	c := NewCoalescer[string]()
+	defer c.Close()
+
+	var runs atomic.Int32
+	fn := func() { runs.Add(1) }
+	assert.True(t, c.Coalesce("user1", 30*time.Millisecond, fn))
+	for range 10 {
+		assert.False(t, c.Coalesce("user1", 30*time.Millisecond, fn))
+	}
+	assert.True(t, c.Coalesce("user2", 30*time.Millisecond, fn), "Expected keys to coalesce independently")
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+
+	assert.True(t, c.Coalesce("user1", 30*time.Millisecond, fn), "Expected a new window after the execution")
+	assert.True(t, c.Cancel("user1"))
+	assert.False(t, c.Cancel("user1"))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, int32(2), runs.Load(), "Expected a cancelled execution not to run")
+}
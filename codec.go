@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec serializes values for persistence, byte storage and network
+// transports.
+type Codec[V any] interface {
+	Encode(value V) ([]byte, error)
+	Decode(data []byte) (V, error)
+}
+
+type GobCodec[V any] struct{}
+
+func (GobCodec[V]) Encode(value V) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec[V]) Decode(data []byte) (V, error) {
+	var value V
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
+
+type JSONCodec[V any] struct{}
+
+func (JSONCodec[V]) Encode(value V) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[V]) Decode(data []byte) (V, error) {
+	var value V
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+type MsgpackCodec[V any] struct{}
+
+func (MsgpackCodec[V]) Encode(value V) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+func (MsgpackCodec[V]) Decode(data []byte) (V, error) {
+	var value V
+	err := msgpack.Unmarshal(data, &value)
+	return value, err
+}
+
+func (c *Cache[T, V]) valueCodec() Codec[V] {
+	if c.codec == nil {
+		return GobCodec[V]{}
+	}
+	return c.codec
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type codecValue struct {
+	Name  string
+	Count int
+}
+
+func TestCodecsRoundTrip(t *testing.T) {
+	codecs := map[string]Codec[codecValue]{
+		"gob":     GobCodec[codecValue]{},
+		"json":    JSONCodec[codecValue]{},
+		"msgpack": MsgpackCodec[codecValue]{},
+	}
+	want := codecValue{Name: "test", Count: 3}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Encode(want)
+			assert.NoError(t, err)
+			got, err := codec.Decode(data)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCacheSnapshotWithCodec(t *testing.T) {
+	src := NewCache[string, codecValue](time.Minute, WithCodec[string, codecValue](JSONCodec[codecValue]{}))
+	src.Set("a", codecValue{Name: "a", Count: 1})
+
+	var buf bytes.Buffer
+	assert.NoError(t, src.SaveTo(&buf))
+	assert.Contains(t, buf.String(), `"Name":"a"`, "Expected values to be encoded with the JSON codec")
+
+	dst := NewCache[string, codecValue](time.Minute, WithCodec[string, codecValue](JSONCodec[codecValue]{}))
+	assert.NoError(t, dst.LoadFrom(&buf))
+	value, found := dst.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, codecValue{Name: "a", Count: 1}, value)
+}
@@ -0,0 +1,29 @@
+package cache
+
+import "strings"
+
+// DeleteFunc deletes every entry for which fn returns true and reports how
+// many were deleted. Entries are removed as by Delete, so write-through,
+// write-behind and the append-only log see each deletion. Entries set
+// concurrently may or may not be visited.
+func (c *Cache[T, V]) DeleteFunc(fn func(key T, value V) bool) int {
+	var keys []T
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		if value, ok := c.value(item); ok && fn(key, value) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	for _, key := range keys {
+		_ = c.TryDelete(key)
+	}
+	return len(keys)
+}
+
+// DeletePrefix deletes every entry whose key starts with prefix, such as a
+// whole "session:" key family, and reports how many were deleted.
+func DeletePrefix[K ~string, V any](c *Cache[K, V], prefix string) int {
+	return c.DeleteFunc(func(key K, _ V) bool {
+		return strings.HasPrefix(string(key), prefix)
+	})
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheDeleteFunc(t *testing.T) {
+	cache := NewCache[int, int](time.Minute)
+	defer cache.StopCleanup()
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i*10)
+	}
+
+	n := cache.DeleteFunc(func(_ int, value int) bool { return value >= 50 })
+	assert.Equal(t, 5, n)
+	_, found := cache.Get(7)
+	assert.False(t, found)
+	_, found = cache.Get(4)
+	assert.True(t, found)
+}
+
+func TestDeletePrefix(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("session:1", 1)
+	cache.Set("session:2", 2)
+	cache.Set("user:1", 3)
+
+	assert.Equal(t, 2, DeletePrefix(cache, "session:"))
+	_, found := cache.Get("session:1")
+	assert.False(t, found)
+	_, found = cache.Get("user:1")
+	assert.True(t, found, "Expected keys outside the prefix to survive")
+
+	backend := newMapBackend[string, int]()
+	wt := NewCache[string, int](time.Minute, WithWriteThrough[string, int](backend))
+	defer wt.StopCleanup()
+	wt.Set("session:1", 1)
+	DeletePrefix(wt, "session:")
+	_, err := backend.Load("session:1")
+	assert.ErrorIs(t, err, ErrNotFound, "Expected deletions to reach the backend")
+}
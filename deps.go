@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// deps records which entries were derived from which; see SetWithDeps.
+type deps[T comparable] struct {
+	mu sync.Mutex
+	// dependents maps a key to the keys that depend on it, and on the
+	// reverse, so either side can be dropped without a scan.
+	dependents map[T]map[T]struct{}
+	on         map[T][]T
+	// n mirrors len(on) so that caches without dependencies skip the lock.
+	n atomic.Int32
+}
+
+// SetWithDeps is TrySet for a value derived from the entries under deps, such
+// as a rendered template and the partials it includes. Deleting or expiring
+// any of deps also removes key, and in turn whatever depends on key. The
+// dependencies need not be cached yet, and replace those of an earlier
+// SetWithDeps of key; a plain Set keeps them. Dependencies are kept in memory
+// only, are not inherited by Namespace, and the entries they remove are not
+// deleted from a backend.
+func (c *Cache[T, V]) SetWithDeps(key T, value V, deps ...T) error {
+	if err := c.TrySet(key, value); err != nil {
+		return err
+	}
+	key = c.canonical(key)
+	on := make([]T, 0, len(deps))
+	for _, dep := range deps {
+		if dep = c.canonical(dep); dep != key {
+			on = append(on, dep)
+		}
+	}
+	c.deps.mu.Lock()
+	defer c.deps.mu.Unlock()
+	c.deps.unlink(key)
+	if len(on) == 0 {
+		return nil
+	}
+	if c.deps.dependents == nil {
+		c.deps.dependents = make(map[T]map[T]struct{})
+		c.deps.on = make(map[T][]T)
+	}
+	for _, dep := range on {
+		set := c.deps.dependents[dep]
+		if set == nil {
+			set = make(map[T]struct{})
+			c.deps.dependents[dep] = set
+		}
+		set[key] = struct{}{}
+	}
+	c.deps.on[key] = on
+	c.deps.n.Store(int32(len(c.deps.on)))
+	return nil
+}
+
+// unlink drops the dependencies of key. The caller holds d.mu.
+func (d *deps[T]) unlink(key T) {
+	for _, dep := range d.on[key] {
+		delete(d.dependents[dep], key)
+		if len(d.dependents[dep]) == 0 {
+			delete(d.dependents, dep)
+		}
+	}
+	delete(d.on, key)
+	d.n.Store(int32(len(d.on)))
+}
+
+// dropDeps forgets the dependencies of key, which has left the cache.
+func (c *Cache[T, V]) dropDeps(key T) {
+	if c.deps.n.Load() == 0 {
+		return
+	}
+	c.deps.mu.Lock()
+	c.deps.unlink(key)
+	c.deps.mu.Unlock()
+}
+
+// invalidateDependents removes the entries depending on key, which was
+// deleted or expired. Each removal cascades in turn; a key's dependents are
+// detached before they are removed, so cycles end. The removals are full
+// deletes, logged, replicated and written through like Delete, so they are
+// left in pending when the caller may hold the log lock.
+func (c *Cache[T, V]) invalidateDependents(key T, pending *pendingHooks) {
+	if c.deps.n.Load() == 0 {
+		return
+	}
+	c.deps.mu.Lock()
+	set := c.deps.dependents[key]
+	delete(c.deps.dependents, key)
+	c.deps.mu.Unlock()
+	for dependent := range set {
+		if pending == nil {
+			_ = c.applyDelete(dependent, nil)
+			continue
+		}
+		*pending = append(*pending, func() { _ = c.applyDelete(dependent, nil) })
+	}
+}
+
+// clearDeps forgets every dependency, as Clear removes every entry.
+func (c *Cache[T, V]) clearDeps() {
+	if c.deps.n.Load() == 0 {
+		return
+	}
+	c.deps.mu.Lock()
+	c.deps.dependents, c.deps.on = nil, nil
+	c.deps.n.Store(0)
+	c.deps.mu.Unlock()
+}
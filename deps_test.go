@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetWithDepsCascades(t *testing.T) {
+	c := NewCache[string, string](time.Minute)
+	defer c.StopCleanup()
+	c.Set("header", "<h1>")
+	c.Set("footer", "</body>")
+	assert.NoError(t, c.SetWithDeps("layout", "<h1></body>", "header", "footer"))
+	assert.NoError(t, c.SetWithDeps("page", "<h1>hi</body>", "layout"))
+	assert.NoError(t, c.SetWithDeps("other", "x", "footer"))
+
+	c.Delete("header")
+	_, found := c.Get("layout")
+	assert.False(t, found, "Expected deleting a dependency to remove its dependents")
+	_, found = c.Get("page")
+	assert.False(t, found, "Expected the invalidation to cascade")
+	_, found = c.Get("other")
+	assert.True(t, found)
+	assert.Equal(t, map[string]struct{}{"other": {}}, c.deps.dependents["footer"], "Expected removed entries to drop their links")
+}
+
+func TestSetWithDepsReplacesLinks(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	assert.NoError(t, c.SetWithDeps("a", 1, "b"))
+	assert.NoError(t, c.SetWithDeps("a", 2, "c"))
+	c.Delete("b")
+	_, found := c.Get("a")
+	assert.True(t, found, "Expected the old dependency to be replaced")
+
+	c.Set("a", 3)
+	c.Delete("c")
+	_, found = c.Get("a")
+	assert.False(t, found, "Expected Set to keep the dependencies")
+	assert.Zero(t, c.deps.n.Load())
+}
+
+func TestSetWithDepsCycle(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	assert.NoError(t, c.SetWithDeps("a", 1, "b", "a"))
+	assert.NoError(t, c.SetWithDeps("b", 2, "a"))
+	c.Delete("a")
+	assert.Zero(t, c.Len())
+	assert.Zero(t, c.deps.n.Load())
+}
+
+func TestSetWithDepsExpiry(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	assert.NoError(t, c.SetWithTTL("b", 1, 10*time.Millisecond))
+	assert.NoError(t, c.SetWithDeps("a", 1, "b"))
+	c.expire(c.nanotime() + int64(time.Second))
+	_, found := c.Get("a")
+	assert.False(t, found, "Expected expiring a dependency to remove its dependents")
+
+	assert.NoError(t, c.SetWithDeps("a", 1, "b"))
+	c.Clear()
+	assert.Zero(t, c.deps.n.Load())
+}
+
+func TestSetWithDepsDurable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+	backend := newMapBackend[string, string]()
+	c := NewCache[string, string](time.Minute,
+		WithAOF[string, string](path, AOFConfig{Fsync: FsyncAlways}),
+		WithWriteThrough[string, string](backend),
+	)
+	c.Set("parent", "p")
+	assert.NoError(t, c.SetWithDeps("child", "c", "parent"))
+	c.Delete("parent")
+	_, found := backend.data["child"]
+	assert.False(t, found, "Expected invalidated dependents to be deleted from the backend")
+	c.StopCleanup()
+
+	c = NewCache[string, string](time.Minute, WithAOF[string, string](path, AOFConfig{}))
+	defer c.StopCleanup()
+	_, found = c.Get("child")
+	assert.False(t, found, "Expected invalidated dependents to stay deleted after a restart")
+}
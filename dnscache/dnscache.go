@@ -0,0 +1,135 @@
+// Package dnscache caches host name lookups and provides a DialContext that
+// uses them, for use in http.Transport and other network clients.
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+const defaultTTL = time.Minute
+
+// LookupFunc resolves host to addresses together with how long they may be
+// cached.
+type LookupFunc func(ctx context.Context, host string) (addrs []string, ttl time.Duration, err error)
+
+type Config struct {
+	// Resolver performs lookups when Lookup is nil. Defaults to
+	// net.DefaultResolver.
+	Resolver *net.Resolver
+	// TTL is how long addresses found by Resolver are cached, since the
+	// standard resolver does not report record TTLs. Defaults to a minute.
+	TTL time.Duration
+	// Lookup, if set, replaces Resolver. Use it with a DNS client that
+	// reports record TTLs to cache each answer for exactly as long as it is
+	// valid.
+	Lookup LookupFunc
+	// Dialer establishes connections for DialContext. Defaults to a zero
+	// net.Dialer.
+	Dialer *net.Dialer
+}
+
+// Resolver caches the addresses of host names. Failed lookups are not cached.
+type Resolver struct {
+	lookup LookupFunc
+	dialer *net.Dialer
+	cache  *cache.Cache[string, []string]
+}
+
+func New(cfg Config) *Resolver {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.Dialer == nil {
+		cfg.Dialer = &net.Dialer{}
+	}
+	lookup := cfg.Lookup
+	if lookup == nil {
+		resolver := cfg.Resolver
+		if resolver == nil {
+			resolver = net.DefaultResolver
+		}
+		lookup = func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			addrs, err := resolver.LookupHost(ctx, host)
+			return addrs, cfg.TTL, err
+		}
+	}
+	return &Resolver{
+		lookup: lookup,
+		dialer: cfg.Dialer,
+		cache:  cache.NewCache[string, []string](cfg.TTL),
+	}
+}
+
+// Close stops the cache's cleanup routine.
+func (r *Resolver) Close() {
+	r.cache.StopCleanup()
+}
+
+// LookupHost returns the addresses of host, from the cache when possible.
+// Concurrent lookups of a host that is not cached share one query, made with
+// the context of the caller that started it. The returned slice is the
+// caller's to modify.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	var ttl time.Duration
+	loaded := false
+	addrs, err := r.cache.GetOrLoad(host, func(host string) ([]string, error) {
+		addrs, d, err := r.lookup(ctx, host)
+		if err == nil && len(addrs) == 0 {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		ttl, loaded = d, true
+		return addrs, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// GetOrLoad caches the answer for the default TTL; the caller that made
+	// the query replaces it with the TTL the query reported.
+	if loaded {
+		if ttl > 0 {
+			_ = r.cache.SetWithTTL(host, addrs, ttl)
+		} else {
+			r.cache.Delete(host)
+		}
+	}
+	return slices.Clone(addrs), nil
+}
+
+// Refresh drops the cached addresses of host so the next lookup queries DNS.
+func (r *Resolver) Refresh(host string) {
+	r.cache.Delete(host)
+}
+
+// DialContext connects to addr like net.Dialer.DialContext, resolving the
+// host through the cache and trying each address in turn.
+func (r *Resolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return r.dialer.DialContext(ctx, network, addr)
+	}
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolverCachesLookups(t *testing.T) {
+	calls := 0
+	r := New(Config{Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		calls++
+		if host == "bad.test" {
+			return nil, 0, errors.New("boom")
+		}
+		return []string{"10.0.0.1"}, time.Minute, nil
+	}})
+	defer r.Close()
+
+	ctx := context.Background()
+	addrs, err := r.LookupHost(ctx, "a.test")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, addrs)
+	r.LookupHost(ctx, "a.test")
+	assert.Equal(t, 1, calls)
+
+	r.Refresh("a.test")
+	r.LookupHost(ctx, "a.test")
+	assert.Equal(t, 2, calls)
+
+	_, err = r.LookupHost(ctx, "bad.test")
+	assert.Error(t, err)
+	r.LookupHost(ctx, "bad.test")
+	assert.Equal(t, 4, calls, "Expected failures not to be cached")
+}
+
+func TestResolverSharesLookups(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := New(Config{Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		return []string{"10.0.0.1", "10.0.0.2"}, time.Minute, nil
+	}})
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addrs, err := r.LookupHost(context.Background(), "a.test")
+			assert.NoError(t, err)
+			assert.Len(t, addrs, 2)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load(), "Expected concurrent lookups of a host to share one query")
+
+	addrs, _ := r.LookupHost(context.Background(), "a.test")
+	addrs[0] = "192.0.2.1"
+	addrs, _ = r.LookupHost(context.Background(), "a.test")
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addrs, "Expected callers not to modify the cached addresses")
+}
+
+func TestResolverRecordTTL(t *testing.T) {
+	calls := 0
+	r := New(Config{Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		calls++
+		if host == "short.test" {
+			return []string{"10.0.0.1"}, time.Millisecond, nil
+		}
+		return []string{"10.0.0.1"}, 0, nil
+	}})
+	defer r.Close()
+
+	ctx := context.Background()
+	r.LookupHost(ctx, "short.test")
+	time.Sleep(20 * time.Millisecond)
+	r.LookupHost(ctx, "short.test")
+	assert.Equal(t, 2, calls, "Expected answers to expire with their TTL")
+
+	r.LookupHost(ctx, "nocache.test")
+	r.LookupHost(ctx, "nocache.test")
+	assert.Equal(t, 4, calls, "Expected answers without a TTL not to be cached")
+}
+
+func TestResolverDialContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	r := New(Config{Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		// Nothing listens on the first address, so the dial falls through.
+		return []string{"127.0.0.2", "127.0.0.1"}, time.Minute, nil
+	}})
+	defer r.Close()
+	client := &http.Client{Transport: &http.Transport{DialContext: r.DialContext}}
+	resp, err := client.Get("http://service.test:" + port)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrDecrypt is returned when data written with WithEncryption fails to
+// authenticate, because it was tampered with or the key is wrong.
+var ErrDecrypt = errors.New("cache: cannot decrypt data")
+
+// maxSealedFrame bounds the frames openReader accepts, so a corrupt length
+// cannot make it allocate arbitrarily large buffers.
+const maxSealedFrame = 1 << 30
+
+// sealer encrypts what the cache writes to disk with AES-GCM. Every message
+// carries its own random nonce.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+func (s *sealer) seal(plain, aad []byte) []byte {
+	out := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plain)+s.aead.Overhead())
+	if _, err := rand.Read(out); err != nil {
+		panic(err)
+	}
+	return s.aead.Seal(out, out, plain, aad)
+}
+
+func (s *sealer) open(sealed, aad []byte) ([]byte, error) {
+	n := s.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, ErrDecrypt
+	}
+	plain, err := s.aead.Open(nil, sealed[:n], sealed[n:], aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+// streamIDLen is the length of the random identifier that starts a sealed
+// stream.
+const streamIDLen = 16
+
+// frameAAD returns the data a frame is authenticated with besides its
+// contents: the stream's identifier, the frame's position in it and whether
+// it ends the stream. Frames therefore cannot be reordered, dropped, moved to
+// another stream or cut off after an end frame without failing to open.
+func frameAAD(aad *[streamIDLen + 9]byte, id []byte, seq uint64, final bool) []byte {
+	copy(aad[:], id)
+	binary.LittleEndian.PutUint64(aad[streamIDLen:], seq)
+	aad[streamIDLen+8] = 0
+	if final {
+		aad[streamIDLen+8] = 1
+	}
+	return aad[:]
+}
+
+// sealWriter seals each Write as a length-prefixed frame, after a random
+// stream identifier. Gob encoders write one message per call, so a torn
+// final frame only loses the last record. Close appends an empty end frame,
+// without which openReader reports the stream as truncated.
+type sealWriter struct {
+	s   *sealer
+	w   io.Writer
+	id  []byte
+	seq uint64
+	aad [streamIDLen + 9]byte
+}
+
+func (w *sealWriter) Write(p []byte) (int, error) {
+	if err := w.frame(p, false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the end frame; it does not close the underlying writer.
+func (w *sealWriter) Close() error {
+	return w.frame(nil, true)
+}
+
+func (w *sealWriter) frame(p []byte, final bool) error {
+	var frame []byte
+	if w.id == nil {
+		w.id = make([]byte, streamIDLen)
+		if _, err := rand.Read(w.id); err != nil {
+			panic(err)
+		}
+		frame = append(frame, w.id...)
+	}
+	sealed := w.s.seal(p, frameAAD(&w.aad, w.id, w.seq, final))
+	w.seq++
+	frame = binary.LittleEndian.AppendUint32(frame, uint32(len(sealed)))
+	_, err := w.w.Write(append(frame, sealed...))
+	return err
+}
+
+// openReader reads the frames written by sealWriter. A stream cut short,
+// within a frame or before its end frame, is reported as
+// io.ErrUnexpectedEOF.
+type openReader struct {
+	s    *sealer
+	r    io.Reader
+	id   []byte
+	seq  uint64
+	done bool
+	head [4]byte
+	aad  [streamIDLen + 9]byte
+	buf  []byte
+}
+
+func (r *openReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next opens the next frame into buf.
+func (r *openReader) next() error {
+	if r.id == nil {
+		id := make([]byte, streamIDLen)
+		if _, err := io.ReadFull(r.r, id); err != nil {
+			return err
+		}
+		r.id = id
+	}
+	if _, err := io.ReadFull(r.r, r.head[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	n := binary.LittleEndian.Uint32(r.head[:])
+	if n > maxSealedFrame {
+		return ErrDecrypt
+	}
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(r.r, sealed); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	// A frame is either the next one or the end frame, and which is only
+	// known once one of them authenticates.
+	plain, err := r.s.open(sealed, frameAAD(&r.aad, r.id, r.seq, false))
+	if err != nil {
+		if plain, err = r.s.open(sealed, frameAAD(&r.aad, r.id, r.seq, true)); err != nil {
+			return err
+		}
+		r.done = true
+	}
+	r.seq++
+	r.buf = plain
+	return nil
+}
+
+// sealed wraps w to encrypt what is written to it if WithEncryption is set.
+func (c *Cache[T, V]) sealed(w io.Writer) io.Writer {
+	if c.sealer == nil {
+		return w
+	}
+	return &sealWriter{s: c.sealer, w: w}
+}
+
+// endSealed writes the end frame if w was wrapped by sealed.
+func endSealed(w io.Writer) error {
+	if sw, ok := w.(*sealWriter); ok {
+		return sw.Close()
+	}
+	return nil
+}
+
+// unsealed wraps r to decrypt what sealed wrote if WithEncryption is set.
+func (c *Cache[T, V]) unsealed(r io.Reader) io.Reader {
+	if c.sealer == nil {
+		return r
+	}
+	return &openReader{s: c.sealer, r: r}
+}
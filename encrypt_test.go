@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func TestCacheEncryptedSnapshot(t *testing.T) {
+	src := NewCache[string, string](time.Minute, WithEncryption[string, string](testKey))
+	defer src.StopCleanup()
+	src.Set("card", "4111-1111-1111-1111")
+
+	var buf bytes.Buffer
+	assert.NoError(t, src.SaveTo(&buf))
+	assert.NotContains(t, buf.String(), "4111", "Expected the snapshot to be encrypted")
+
+	dst := NewCache[string, string](time.Minute, WithEncryption[string, string](testKey))
+	defer dst.StopCleanup()
+	assert.NoError(t, dst.LoadFrom(bytes.NewReader(buf.Bytes())))
+	value, found := dst.Get("card")
+	assert.True(t, found)
+	assert.Equal(t, "4111-1111-1111-1111", value)
+
+	other := NewCache[string, string](time.Minute, WithEncryption[string, string](bytes.Repeat([]byte{8}, 32)))
+	defer other.StopCleanup()
+	assert.ErrorIs(t, other.LoadFrom(bytes.NewReader(buf.Bytes())), ErrDecrypt)
+}
+
+func TestCacheEncryptedAOF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+
+	src := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{Fsync: FsyncAlways}), WithEncryption[int, string](testKey))
+	src.Set(1, "secret1")
+	src.Set(2, "secret2")
+	assert.NoError(t, src.LastAOFError())
+	src.StopCleanup()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret", "Expected the log to be encrypted")
+	assert.NoError(t, os.Truncate(path, int64(len(data)-1)))
+
+	dst := NewCache[int, string](time.Minute, WithAOF[int, string](path, AOFConfig{}), WithEncryption[int, string](testKey))
+	defer dst.StopCleanup()
+	assert.NoError(t, dst.LastAOFError(), "Expected a torn final frame to be tolerated")
+	value, found := dst.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "secret1", value)
+}
+
+func TestCacheEncryptedSpill(t *testing.T) {
+	s, err := NewFileSpill[string](filepath.Join(t.TempDir(), "spill"), 0)
+	assert.NoError(t, err)
+	cache := NewCache[string, string](time.Minute,
+		WithMaxEntries[string, string](1), WithSpill[string, string](s), WithEncryption[string, string](testKey))
+	defer cache.StopCleanup()
+
+	cache.Set("a", "secret")
+	cache.Set("b", "other")
+	data, err := os.ReadFile(s.path)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, data)
+	assert.NotContains(t, string(data), "secret", "Expected spilled values to be encrypted")
+
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, "secret", value)
+	assert.NoError(t, cache.LastSpillError())
+}
+
+func TestWithEncryptionBadKey(t *testing.T) {
+	assert.Panics(t, func() { WithEncryption[string, string]([]byte("short")) })
+}
+
+func TestCacheEncryptedFrames(t *testing.T) {
+	src := NewCache[int, string](time.Minute, WithEncryption[int, string](testKey))
+	defer src.StopCleanup()
+	for i := range 20 {
+		src.Set(i, "value")
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, src.SaveTo(&buf))
+	frames := splitFrames(buf.Bytes())
+	assert.Greater(t, len(frames), 3)
+
+	load := func(data []byte) error {
+		dst := NewCache[int, string](time.Minute, WithEncryption[int, string](testKey))
+		defer dst.StopCleanup()
+		return dst.LoadFrom(bytes.NewReader(data))
+	}
+	join := func(frames ...[]byte) []byte {
+		return append(buf.Bytes()[:streamIDLen:streamIDLen], bytes.Join(frames, nil)...)
+	}
+	assert.NoError(t, load(join(frames...)))
+	assert.ErrorIs(t, load(join(frames[:len(frames)-1]...)), io.ErrUnexpectedEOF, "Expected a missing end frame to be detected")
+	dropped := append(slices.Clone(frames[:1]), frames[2:]...)
+	assert.ErrorIs(t, load(join(dropped...)), ErrDecrypt, "Expected a dropped frame to be detected")
+	swapped := slices.Clone(frames)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	assert.ErrorIs(t, load(join(swapped...)), ErrDecrypt, "Expected reordered frames to be detected")
+
+	var other bytes.Buffer
+	assert.NoError(t, src.SaveTo(&other))
+	spliced := append(slices.Clone(frames[:1]), splitFrames(other.Bytes())[1:]...)
+	assert.ErrorIs(t, load(join(spliced...)), ErrDecrypt, "Expected frames of another stream to be rejected")
+}
+
+// splitFrames returns the frames of a sealed stream, length prefix included.
+func splitFrames(data []byte) [][]byte {
+	var frames [][]byte
+	for data = data[streamIDLen:]; len(data) > 0; {
+		n := 4 + int(binary.LittleEndian.Uint32(data))
+		frames = append(frames, data[:n])
+		data = data[n:]
+	}
+	return frames
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventType identifies the kind of change an Event reports.
+type EventType uint8
+
+const (
+	EventSet EventType = iota + 1
+	EventDelete
+	EventExpire
+	EventEvict
+	EventClear
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	case EventClear:
+		return "clear"
+	default:
+		return "unknown"
+	}
+}
+
+func (t EventType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Event describes a change to a cache entry. Value holds the entry's value
+// when it is known and is the zero value otherwise; Clear events carry
+// neither a key nor a value.
+type Event[T comparable, V any] struct {
+	Type  EventType
+	Key   T
+	Value V
+}
+
+// DropPolicy decides which event is lost when a subscriber falls behind.
+type DropPolicy uint8
+
+const (
+	// DropNewest discards the incoming event.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest buffered event to make room.
+	DropOldest
+)
+
+// SubscribeConfig configures a subscription. Buffer defaults to 64.
+type SubscribeConfig struct {
+	Buffer int
+	Drop   DropPolicy
+}
+
+type subscriber[T comparable, V any] struct {
+	ch     chan Event[T, V]
+	filter func(Event[T, V]) bool
+	drop   DropPolicy
+}
+
+type subscribers[T comparable, V any] struct {
+	mu   sync.RWMutex
+	subs map[*subscriber[T, V]]struct{}
+	n    atomic.Int32
+}
+
+// Subscribe returns a channel receiving the events for which filter returns
+// true, or all events if filter is nil. Events are never blocked on: once
+// the buffer is full they are dropped according to cfg.Drop and counted in
+// Stats.EventsDropped. The returned function cancels the subscription and
+// closes the channel; StopCleanup cancels every subscription.
+func (c *Cache[T, V]) Subscribe(filter func(Event[T, V]) bool, cfg SubscribeConfig) (<-chan Event[T, V], func()) {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 64
+	}
+	s := &subscriber[T, V]{ch: make(chan Event[T, V], cfg.Buffer), filter: filter, drop: cfg.Drop}
+	c.events.mu.Lock()
+	if c.events.subs == nil {
+		c.events.subs = make(map[*subscriber[T, V]]struct{})
+	}
+	c.events.subs[s] = struct{}{}
+	c.events.n.Add(1)
+	c.events.mu.Unlock()
+	return s.ch, func() { c.unsubscribe(s) }
+}
+
+func (c *Cache[T, V]) unsubscribe(s *subscriber[T, V]) {
+	c.events.mu.Lock()
+	defer c.events.mu.Unlock()
+	if _, ok := c.events.subs[s]; ok {
+		delete(c.events.subs, s)
+		c.events.n.Add(-1)
+		close(s.ch)
+	}
+}
+
+func (c *Cache[T, V]) closeSubscriptions() {
+	c.events.mu.Lock()
+	defer c.events.mu.Unlock()
+	for s := range c.events.subs {
+		delete(c.events.subs, s)
+		close(s.ch)
+	}
+	c.events.n.Store(0)
+}
+
+// watched reports whether anyone is subscribed or has registered a hook, so
+// callers can skip the work of building events nobody receives.
+func (c *Cache[T, V]) watched() bool {
+	return c.events.n.Load() > 0 || c.hooks.n.Load() > 0
+}
+
+// notify delivers an event about item to every interested hook and subscriber.
+func (c *Cache[T, V]) notify(typ EventType, key T, item CachedItem[V], pending *pendingHooks) {
+	if !c.watched() {
+		return
+	}
+	e := Event[T, V]{Type: typ, Key: key}
+	if typ != EventClear {
+		e.Value, _ = c.value(item)
+	}
+	c.runHooks(e, pending)
+	if c.events.n.Load() == 0 {
+		return
+	}
+	c.events.mu.RLock()
+	defer c.events.mu.RUnlock()
+	for s := range c.events.subs {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+			continue
+		default:
+		}
+		if s.drop == DropOldest {
+			select {
+			case <-s.ch:
+			default:
+			}
+			select {
+			case s.ch <- e:
+			default:
+			}
+		}
+		c.stats.eventsDropped.Add(1)
+	}
+}
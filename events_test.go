@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheSubscribe(t *testing.T) {
+	cache := NewCache[string, int](20*time.Millisecond, WithExactTime[string, int]())
+	events, cancel := cache.Subscribe(nil, SubscribeConfig{})
+	defer cancel()
+
+	cache.Set("a", 1)
+	cache.Delete("a")
+	cache.Set("b", 2)
+	cache.Clear()
+	cache.Set("c", 3)
+
+	want := []Event[string, int]{
+		{Type: EventSet, Key: "a", Value: 1},
+		{Type: EventDelete, Key: "a", Value: 1},
+		{Type: EventSet, Key: "b", Value: 2},
+		{Type: EventClear},
+		{Type: EventSet, Key: "c", Value: 3},
+		{Type: EventExpire, Key: "c", Value: 3},
+	}
+	for _, w := range want {
+		select {
+		case e := <-events:
+			assert.Equal(t, w, e)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %v event", w.Type)
+		}
+	}
+
+	cache.StopCleanup()
+	_, open := <-events
+	assert.False(t, open, "Expected StopCleanup to close subscriptions")
+}
+
+func TestCacheSubscribeFilterAndDrop(t *testing.T) {
+	cache := NewCache[int, int](time.Minute)
+	defer cache.StopCleanup()
+
+	onlySets := func(e Event[int, int]) bool { return e.Type == EventSet }
+	newest, cancelNewest := cache.Subscribe(onlySets, SubscribeConfig{Buffer: 2, Drop: DropNewest})
+	oldest, cancelOldest := cache.Subscribe(onlySets, SubscribeConfig{Buffer: 2, Drop: DropOldest})
+
+	for i := 0; i < 4; i++ {
+		cache.Set(i, i)
+		cache.Delete(i)
+	}
+	assert.Equal(t, uint64(4), cache.Stats().EventsDropped)
+
+	cancelNewest()
+	cancelOldest()
+	cancelOldest()
+	var keys []int
+	for e := range newest {
+		keys = append(keys, e.Key)
+	}
+	assert.Equal(t, []int{0, 1}, keys, "Expected DropNewest to keep the first events")
+	keys = nil
+	for e := range oldest {
+		keys = append(keys, e.Key)
+	}
+	assert.Equal(t, []int{2, 3}, keys, "Expected DropOldest to keep the latest events")
+}
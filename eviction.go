@@ -0,0 +1,524 @@
+package cache
+
+import "sync"
+
+// EvictionPolicy selects how a cache bounded by WithMaxEntries or WithMaxCost
+// chooses which entry to evict.
+type EvictionPolicy uint8
+
+const (
+	// EvictLRU evicts the least recently read or written entry. Every read
+	// reorders a list under a lock.
+	EvictLRU EvictionPolicy = iota
+	// EvictClock approximates LRU with the CLOCK algorithm: reads only set
+	// a reference bit, under a shared lock, and a rotating hand gives
+	// referenced entries a second chance. It suits read-heavy workloads
+	// with many concurrent readers.
+	EvictClock
+	// EvictSLRU is segmented LRU: new entries are evicted first unless they
+	// are read again, which protects the working set from scans such as
+	// batch jobs reading many rows once.
+	EvictSLRU
+	// EvictSampled evicts the least recently used of a few entries sampled
+	// at random, as Redis does. Reads only stamp the entry, under a shared
+	// lock, and writes never reorder anything, trading eviction accuracy
+	// for throughput at very high write rates.
+	EvictSampled
+)
+
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictLRU:
+		return "lru"
+	case EvictClock:
+		return "clock"
+	case EvictSLRU:
+		return "slru"
+	case EvictSampled:
+		return "sampled"
+	default:
+		return "unknown"
+	}
+}
+
+// setPolicy makes e create policies of kind p.
+func (e *evictor[T]) setPolicy(p EvictionPolicy) {
+	e.kind = p
+	switch p {
+	case EvictClock:
+		e.newPolicy, e.sharedAccess = func() evictionPolicy[T] { return newClockPolicy[T]() }, true
+	case EvictSLRU:
+		e.newPolicy, e.sharedAccess = func() evictionPolicy[T] { return newSLRUPolicy[T]() }, false
+	case EvictSampled:
+		e.newPolicy, e.sharedAccess = func() evictionPolicy[T] { return newSampledPolicy[T]() }, true
+	default:
+		e.newPolicy, e.sharedAccess = func() evictionPolicy[T] { return newLRUPolicy[T]() }, false
+	}
+}
+
+// evictionPolicy orders the keys of a size-bounded cache by how worth keeping
+// they are. Implementations need no synchronization; evictor serializes
+// access to them.
+type evictionPolicy[T comparable] interface {
+	// add records a write of key, inserting it if it is not tracked yet.
+	add(key T)
+	// access records a read of key. Untracked keys are ignored.
+	access(key T)
+	remove(key T)
+	// victim removes and returns the key to evict next.
+	victim() (T, bool)
+	has(key T) bool
+	len() int
+}
+
+// evictor bounds the number and total cost of the entries tracked by its
+// policies, keeping one policy per priority in use. The policies may briefly
+// hold keys that a concurrent write already removed from the store; evicting
+// such a key is a no-op, so they only cost a slot until then.
+type evictor[T comparable] struct {
+	mu         sync.RWMutex
+	maxEntries int
+	maxCost    int64
+	doorkeeper *doorkeeper
+	kind       EvictionPolicy
+	newPolicy  func() evictionPolicy[T]
+	// sharedAccess is set for policies whose access method may run
+	// concurrently with itself, which then only takes the read lock.
+	sharedAccess bool
+	// levels is ordered by priority, lowest first.
+	levels []evictionLevel[T]
+	// priorities holds the priority of keys not at PriorityNormal.
+	priorities map[T]Priority
+	// costs is only filled in with a cost limit.
+	costs map[T]int64
+	cost  int64
+}
+
+type evictionLevel[T comparable] struct {
+	priority Priority
+	policy   evictionPolicy[T]
+}
+
+// tracked is a key with the priority and cost it is tracked at. hash is only
+// set with an admission filter.
+type tracked[T comparable] struct {
+	key      T
+	priority Priority
+	cost     int64
+	hash     uint64
+}
+
+// newEvictor returns an evictor without limits, which the options set.
+func newEvictor[T comparable]() *evictor[T] {
+	e := &evictor[T]{
+		priorities: make(map[T]Priority),
+		costs:      make(map[T]int64),
+	}
+	e.setPolicy(EvictLRU)
+	return e
+}
+
+// limited reports whether e enforces any limit.
+func (e *evictor[T]) limited() bool {
+	return e.maxEntries > 0 || e.maxCost > 0
+}
+
+// clone returns an empty evictor with the same configuration.
+func (e *evictor[T]) clone() *evictor[T] {
+	ne := newEvictor[T]()
+	ne.maxEntries, ne.maxCost = e.maxEntries, e.maxCost
+	ne.setPolicy(e.kind)
+	if e.doorkeeper != nil {
+		ne.doorkeeper = e.doorkeeper.clone()
+	}
+	return ne
+}
+
+// add tracks key and returns the keys that must be evicted to make room. A
+// key that does not fit on its own is returned rather than displacing other
+// keys. The caller holds e.mu.
+func (e *evictor[T]) add(k tracked[T]) []T {
+	if e.maxCost > 0 && k.cost > e.maxCost {
+		e.untrack(k.key)
+		return []T{k.key}
+	}
+	e.track(k)
+	return e.overflow()
+}
+
+// rejects reports whether the admission filter keeps a new key out of a full
+// cache, recording the key in the filter. The caller holds e.mu.
+func (e *evictor[T]) rejects(k tracked[T], now int64) bool {
+	if e.doorkeeper == nil {
+		return false
+	}
+	if l := e.level(e.priorityOf(k.key), false); l != nil && l.has(k.key) {
+		return false
+	}
+	full := e.maxEntries > 0 && e.len() >= e.maxEntries || e.maxCost > 0 && e.cost+k.cost > e.maxCost
+	return full && !e.doorkeeper.seen(k.hash, now)
+}
+
+func (e *evictor[T]) track(k tracked[T]) {
+	key, priority := k.key, k.priority
+	if e.maxCost > 0 {
+		e.cost += k.cost - e.costs[key]
+		e.costs[key] = k.cost
+	}
+	if old := e.priorityOf(key); old != priority {
+		if l := e.level(old, false); l != nil {
+			l.remove(key)
+		}
+	}
+	if priority == PriorityNormal {
+		delete(e.priorities, key)
+	} else {
+		e.priorities[key] = priority
+	}
+	e.level(priority, true).add(key)
+}
+
+func (e *evictor[T]) overflow() (victims []T) {
+	for e.maxEntries > 0 && e.len() > e.maxEntries || e.maxCost > 0 && e.cost > e.maxCost {
+		key, ok := e.victim()
+		if !ok {
+			break
+		}
+		victims = append(victims, key)
+	}
+	return victims
+}
+
+func (e *evictor[T]) victim() (T, bool) {
+	for _, l := range e.levels {
+		if key, ok := l.policy.victim(); ok {
+			delete(e.priorities, key)
+			e.uncost(key)
+			return key, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (e *evictor[T]) len() int {
+	n := 0
+	for _, l := range e.levels {
+		n += l.policy.len()
+	}
+	return n
+}
+
+func (e *evictor[T]) priorityOf(key T) Priority {
+	if len(e.priorities) == 0 {
+		return PriorityNormal
+	}
+	return e.priorities[key]
+}
+
+// level returns the policy for priority, creating it if create is set and
+// returning nil otherwise.
+func (e *evictor[T]) level(priority Priority, create bool) evictionPolicy[T] {
+	i := 0
+	for ; i < len(e.levels) && e.levels[i].priority <= priority; i++ {
+		if e.levels[i].priority == priority {
+			return e.levels[i].policy
+		}
+	}
+	if !create {
+		return nil
+	}
+	l := evictionLevel[T]{priority: priority, policy: e.newPolicy()}
+	e.levels = append(e.levels, evictionLevel[T]{})
+	copy(e.levels[i+1:], e.levels[i:])
+	e.levels[i] = l
+	return l.policy
+}
+
+func (e *evictor[T]) access(key T) {
+	if e.sharedAccess {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+	} else {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+	}
+	if l := e.level(e.priorityOf(key), false); l != nil {
+		l.access(key)
+	}
+}
+
+func (e *evictor[T]) remove(key T) {
+	e.mu.Lock()
+	e.untrack(key)
+	e.mu.Unlock()
+}
+
+func (e *evictor[T]) untrack(key T) {
+	if l := e.level(e.priorityOf(key), false); l != nil {
+		l.remove(key)
+	}
+	delete(e.priorities, key)
+	e.uncost(key)
+}
+
+func (e *evictor[T]) uncost(key T) {
+	if e.maxCost > 0 {
+		e.cost -= e.costs[key]
+		delete(e.costs, key)
+	}
+}
+
+// rebuild replaces the tracked keys with keys and returns those that do not
+// fit. The caller holds e.mu.
+func (e *evictor[T]) rebuild(keys []tracked[T]) []T {
+	e.levels = nil
+	clear(e.priorities)
+	clear(e.costs)
+	e.cost = 0
+	for _, k := range keys {
+		e.track(k)
+	}
+	return e.overflow()
+}
+
+// limits returns the evictor for the options to configure, creating it on
+// first use. newCache drops it again if no limit was set.
+func (c *Cache[T, V]) limits() *evictor[T] {
+	if c.eviction == nil {
+		c.eviction = newEvictor[T]()
+	}
+	return c.eviction
+}
+
+// admit hands a freshly stored key to the eviction policy and evicts whatever
+// no longer fits. Pinned keys are never tracked.
+func (c *Cache[T, V]) admit(key T, item CachedItem[V]) {
+	if c.eviction == nil || c.isPinned(key) {
+		return
+	}
+	k := c.tracked(key, item)
+	// Checking the store under the evictor lock keeps a write that a racing
+	// Clear dropped out of the policy retrack rebuilt; see indexPath.
+	var victims []victim[T, V]
+	c.eviction.mu.Lock()
+	if _, ok := c.items().Get(key); ok {
+		victims = c.chosen(c.eviction.add(k))
+	}
+	c.eviction.mu.Unlock()
+	for _, v := range victims {
+		c.evict(v.key, v.item)
+	}
+}
+
+// victim is an entry the eviction policy chose, as it was when chosen.
+type victim[T comparable, V any] struct {
+	key  T
+	item CachedItem[V]
+}
+
+// chosen looks up the items of the keys the policy chose. It must be called
+// with the evictor lock held, so that the items are the ones the policy
+// tracked and a write after the choice is left alone by evict.
+func (c *Cache[T, V]) chosen(keys []T) []victim[T, V] {
+	var victims []victim[T, V]
+	for _, key := range keys {
+		if item, ok := c.items().Get(key); ok {
+			victims = append(victims, victim[T, V]{key, item})
+		}
+	}
+	return victims
+}
+
+// rejected reports whether the admission filter turns away a write of key.
+// store checks it first, so a rejected write is dropped without being
+// stored, announced or spilled.
+func (c *Cache[T, V]) rejected(key T, item CachedItem[V]) bool {
+	if c.eviction == nil || c.eviction.doorkeeper == nil || c.isPinned(key) {
+		return false
+	}
+	k, now := c.tracked(key, item), c.nanotime()
+	c.eviction.mu.Lock()
+	rejected := c.eviction.rejects(k, now)
+	c.eviction.mu.Unlock()
+	if rejected {
+		c.stats.rejections.Add(1)
+	}
+	return rejected
+}
+
+func (c *Cache[T, V]) tracked(key T, item CachedItem[V]) tracked[T] {
+	k := tracked[T]{key: key, priority: item.priority}
+	if c.eviction.maxCost > 0 {
+		k.cost = c.cost(key, item)
+	}
+	if c.eviction.doorkeeper != nil {
+		k.hash = c.hasher.hash(key)
+	}
+	return k
+}
+
+// forget drops the bookkeeping for a key that was removed from the store.
+func (c *Cache[T, V]) forget(key T) {
+	if c.eviction != nil {
+		c.eviction.remove(key)
+	}
+	c.unpinRemoved(key)
+	c.dropDeps(key)
+	c.indexPath(key)
+	c.indexOrder(key)
+	c.indexHash(key)
+	c.indexValue(key)
+}
+
+// evict removes the item under key to make room for other entries, unless
+// it was pinned or written again after the policy chose it.
+func (c *Cache[T, V]) evict(key T, item CachedItem[V]) {
+	if c.isPinned(key) {
+		return
+	}
+	c.writes.Add(1)
+	ok := c.items().CompareAndDelete(key, item.version)
+	c.writes.Add(1)
+	if !ok {
+		return
+	}
+	c.stats.evictions.Add(1)
+	c.dropDeps(key)
+	c.indexPath(key)
+	c.indexOrder(key)
+	c.indexHash(key)
+	c.indexValue(key)
+	c.notify(EventEvict, key, item, nil)
+	if c.spiller != nil {
+		c.spill(key, item)
+	}
+}
+
+// retrack rebuilds the pins, the key and value indexes and the eviction
+// policy after the whole store was replaced.
+func (c *Cache[T, V]) retrack(items Store[T, V]) {
+	c.prunePins(items)
+	c.reindexPaths(items)
+	c.reindexOrder(items)
+	c.reindexHashes(items)
+	c.reindexValues(items)
+	if c.eviction == nil {
+		return
+	}
+	var keys []tracked[T]
+	c.eviction.mu.Lock()
+	items.ForEach(func(key T, item CachedItem[V]) bool {
+		if !c.isPinned(key) {
+			keys = append(keys, c.tracked(key, item))
+		}
+		return true
+	})
+	victims := c.chosen(c.eviction.rebuild(keys))
+	c.eviction.mu.Unlock()
+	for _, v := range victims {
+		c.evict(v.key, v.item)
+	}
+}
+
+type lruNode[T comparable] struct {
+	key        T
+	prev, next *lruNode[T]
+	// protected marks nodes in the protected segment of slruPolicy.
+	protected bool
+}
+
+// lruList is a doubly linked list of nodes, most recently used first.
+type lruList[T comparable] struct {
+	head lruNode[T]
+	n    int
+}
+
+func (l *lruList[T]) init() {
+	l.head.prev, l.head.next = &l.head, &l.head
+	l.n = 0
+}
+
+func (l *lruList[T]) pushFront(n *lruNode[T]) {
+	n.prev, n.next = &l.head, l.head.next
+	l.head.next.prev = n
+	l.head.next = n
+	l.n++
+}
+
+func (l *lruList[T]) unlink(n *lruNode[T]) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	l.n--
+}
+
+func (l *lruList[T]) moveToFront(n *lruNode[T]) {
+	if l.head.next == n {
+		return
+	}
+	l.unlink(n)
+	l.pushFront(n)
+}
+
+// back returns the least recently used node, or nil if l is empty.
+func (l *lruList[T]) back() *lruNode[T] {
+	if l.n == 0 {
+		return nil
+	}
+	return l.head.prev
+}
+
+// lruPolicy evicts the least recently read or written key.
+type lruPolicy[T comparable] struct {
+	nodes map[T]*lruNode[T]
+	list  lruList[T]
+}
+
+func newLRUPolicy[T comparable]() *lruPolicy[T] {
+	p := &lruPolicy[T]{nodes: make(map[T]*lruNode[T])}
+	p.list.init()
+	return p
+}
+
+func (p *lruPolicy[T]) add(key T) {
+	if n, ok := p.nodes[key]; ok {
+		p.list.moveToFront(n)
+		return
+	}
+	n := &lruNode[T]{key: key}
+	p.nodes[key] = n
+	p.list.pushFront(n)
+}
+
+func (p *lruPolicy[T]) access(key T) {
+	if n, ok := p.nodes[key]; ok {
+		p.list.moveToFront(n)
+	}
+}
+
+func (p *lruPolicy[T]) remove(key T) {
+	if n, ok := p.nodes[key]; ok {
+		p.list.unlink(n)
+		delete(p.nodes, key)
+	}
+}
+
+func (p *lruPolicy[T]) victim() (T, bool) {
+	n := p.list.back()
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	p.list.unlink(n)
+	delete(p.nodes, n.key)
+	return n.key, true
+}
+
+func (p *lruPolicy[T]) has(key T) bool {
+	_, ok := p.nodes[key]
+	return ok
+}
+
+func (p *lruPolicy[T]) len() int {
+	return len(p.nodes)
+}
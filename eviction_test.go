@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMaxEntries(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithMaxEntries[string, int](2))
+	defer cache.StopCleanup()
+	events, cancel := cache.Subscribe(nil, SubscribeConfig{})
+	defer cancel()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	_, found := cache.Get("b")
+	assert.False(t, found, "Expected the least recently used entry to be evicted")
+	for _, key := range []string{"a", "c"} {
+		_, found := cache.Get(key)
+		assert.True(t, found, key)
+	}
+	assert.Equal(t, uint64(1), cache.Stats().Evictions)
+
+	var evicted []Event[string, int]
+	for len(events) > 0 {
+		if e := <-events; e.Type == EventEvict {
+			evicted = append(evicted, e)
+		}
+	}
+	assert.Equal(t, []Event[string, int]{{Type: EventEvict, Key: "b", Value: 2}}, evicted)
+
+	cache.Delete("a")
+	cache.Set("d", 4)
+	_, found = cache.Get("c")
+	assert.True(t, found, "Expected deletes to free a slot")
+}
+
+func TestCacheMaxEntriesGeneration(t *testing.T) {
+	cache := NewCache[int, int](time.Minute, WithMaxEntries[int, int](3))
+	defer cache.StopCleanup()
+
+	g := cache.BeginGeneration()
+	for i := range 5 {
+		g.Set(i, i)
+	}
+	assert.NoError(t, cache.CommitGeneration(g))
+	assert.Len(t, cache.Snapshot(), 3)
+
+	cache.Clear()
+	for i := range 3 {
+		cache.Set(i, i)
+	}
+	assert.Len(t, cache.Snapshot(), 3, "Expected Clear to free every slot")
+}
+
+func TestCacheEvictKeepsRacingWrite(t *testing.T) {
+	s := &racingStore{Store: NewShardedMapStore[string, int](1)}
+	cache := NewCache[string, int](time.Minute, WithMaxEntries[string, int](2),
+		WithStore(func() Store[string, int] { return s }))
+	defer cache.StopCleanup()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	// a is chosen as the victim of c, then written again before it goes.
+	s.race = func() { cache.Set("a", 10) }
+	cache.Set("c", 3)
+
+	value, found := cache.Get("a")
+	assert.True(t, found, "Expected a write after the victim was chosen to be kept")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 2, cache.Len())
+}
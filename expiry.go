@@ -0,0 +1,344 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+const expiryBucketsPerTTL = 64
+
+// expiryIndex is a timing wheel over entry deadlines: keys are bucketed by
+// deadline at a fixed resolution, so a sweep only visits the buckets that have
+// come due instead of the whole map. Buckets are kept sparsely in a map, which
+// also accommodates deadlines far beyond one wheel revolution. Overwritten or
+// deleted keys are not removed from their old bucket; the sweep re-checks the
+// live item before deleting.
+type expiryIndex[T comparable] struct {
+	mu         sync.Mutex
+	resolution int64
+	cursor     int64
+	buckets    map[int64][]T
+	backlog    []T
+}
+
+func newExpiryIndex[T comparable](ttl time.Duration, now int64) *expiryIndex[T] {
+	res := int64(ttl) / expiryBucketsPerTTL
+	if res < int64(time.Millisecond) {
+		res = int64(time.Millisecond)
+	}
+	return &expiryIndex[T]{
+		resolution: res,
+		cursor:     now / res,
+		buckets:    make(map[int64][]T),
+	}
+}
+
+func (x *expiryIndex[T]) add(key T, expires int64) {
+	b := expires / x.resolution
+	x.mu.Lock()
+	if b < x.cursor {
+		b = x.cursor
+	}
+	x.buckets[b] = append(x.buckets[b], key)
+	x.mu.Unlock()
+}
+
+// due removes and returns the keys of every bucket up to and including the one
+// containing now, preceded by any backlog left over from a budgeted sweep.
+// Keys in the last bucket may not have expired yet; callers put them back with
+// add.
+func (x *expiryIndex[T]) due(now int64) []T {
+	last := now / x.resolution
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	keys := x.backlog
+	x.backlog = nil
+	for ; x.cursor <= last; x.cursor++ {
+		if bucket, ok := x.buckets[x.cursor]; ok {
+			keys = append(keys, bucket...)
+			delete(x.buckets, x.cursor)
+		}
+	}
+	return keys
+}
+
+// pending returns the keys a sweep at now would visit, without consuming
+// them. Keys may repeat and may not have expired yet.
+func (x *expiryIndex[T]) pending(now int64) []T {
+	last := now / x.resolution
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	keys := append([]T(nil), x.backlog...)
+	for b, bucket := range x.buckets {
+		if b <= last {
+			keys = append(keys, bucket...)
+		}
+	}
+	return keys
+}
+
+// postpone hands keys a budgeted sweep did not get to back to the next sweep.
+func (x *expiryIndex[T]) postpone(keys []T) {
+	x.mu.Lock()
+	x.backlog = append(keys, x.backlog...)
+	x.mu.Unlock()
+}
+
+func (x *expiryIndex[T]) reset() {
+	x.mu.Lock()
+	x.buckets = make(map[int64][]T)
+	x.backlog = nil
+	x.mu.Unlock()
+}
+
+// compact rebuilds the bucket map, which otherwise keeps its peak size.
+func (x *expiryIndex[T]) compact() {
+	x.mu.Lock()
+	buckets := make(map[int64][]T, len(x.buckets))
+	for b, keys := range x.buckets {
+		buckets[b] = keys
+	}
+	x.buckets = buckets
+	x.backlog = append([]T(nil), x.backlog...)
+	x.mu.Unlock()
+}
+
+// store inserts item under key and schedules it for expiry. Overwrites that
+// land in the same bucket as the replaced item reuse its schedule, so hot keys
+// do not grow the index. Writes the admission filter rejects are dropped.
+func (c *Cache[T, V]) store(key T, item CachedItem[V], pending *pendingHooks) {
+	item, ok := c.prepare(key, item)
+	if !ok {
+		return
+	}
+	c.writes.Add(1)
+	data := c.data.Load()
+	old, swapped := (*data).Swap(key, item)
+	if !swapped {
+		(*data).Set(key, item)
+	}
+	// A racing Clear or CommitGeneration may have replaced the store after
+	// it was loaded; write to the new one as well rather than lose the
+	// write while the indexes below record it.
+	for next := c.data.Load(); next != data; next = c.data.Load() {
+		data = next
+		if old, swapped = (*data).Swap(key, item); !swapped {
+			(*data).Set(key, item)
+		}
+	}
+	c.writes.Add(1)
+	c.stored(key, old, swapped, item, pending)
+}
+
+// storeIf is store for writes that only apply if the entry under key is
+// still old, as read by the caller; a zero old version means the key must be
+// absent. It returns the item as stored and whether it was. A racing Clear
+// wins over the write rather than being written through like in store.
+func (c *Cache[T, V]) storeIf(key T, old, item CachedItem[V], pending *pendingHooks) (CachedItem[V], bool) {
+	item, ok := c.prepare(key, item)
+	if !ok {
+		return item, false
+	}
+	c.writes.Add(1)
+	if old.version == 0 {
+		_, loaded := c.items().GetOrSet(key, item)
+		ok = !loaded
+	} else {
+		ok = c.items().CompareAndSwap(key, old.version, item)
+	}
+	c.writes.Add(1)
+	if ok {
+		c.stored(key, old, old.version != 0, item, pending)
+	}
+	return item, ok
+}
+
+// prepare stamps item with a new version before it is stored, reporting
+// false if the admission filter rejects the write.
+func (c *Cache[T, V]) prepare(key T, item CachedItem[V]) (CachedItem[V], bool) {
+	if c.rejected(key, item) {
+		return item, false
+	}
+	if c.arena != nil && c.arena.mapped != nil && item.blob.seq == 0 {
+		item = c.mapItem(key, item)
+	}
+	item.version = c.versions.Add(1)
+	return item, true
+}
+
+// stored updates the indexes, the expiry schedule and the eviction tracking
+// for an item just written over old, if swapped, or inserted.
+func (c *Cache[T, V]) stored(key T, old CachedItem[V], swapped bool, item CachedItem[V], pending *pendingHooks) {
+	x := c.expiryFor(key)
+	if !swapped {
+		c.indexPath(key)
+		c.indexOrder(key)
+		c.indexHash(key)
+	}
+	c.indexValue(key)
+	if c.spiller != nil {
+		c.unspill(key)
+	}
+	c.notify(EventSet, key, item, pending)
+	c.trace(TraceSet, key)
+	c.waiters.wake(key)
+	c.admit(key, item)
+	if !swapped || c.sweepAt(old)/x.resolution != c.sweepAt(item)/x.resolution {
+		x.add(key, c.sweepAt(item))
+	}
+}
+
+// reinsert puts item back under key, as Undelete and spill recall do, unless
+// the key has been set again in the meantime. It restores the indexes, the
+// expiry schedule and the eviction tracking that removing the entry dropped,
+// and returns the item now stored and whether it was reinserted.
+func (c *Cache[T, V]) reinsert(key T, item CachedItem[V]) (CachedItem[V], bool) {
+	item.version = c.versions.Add(1)
+	c.writes.Add(1)
+	existing, loaded := c.items().GetOrSet(key, item)
+	c.writes.Add(1)
+	if loaded {
+		return existing, false
+	}
+	c.indexPath(key)
+	c.indexOrder(key)
+	c.indexHash(key)
+	c.indexValue(key)
+	c.expiryFor(key).add(key, c.sweepAt(item))
+	c.admit(key, item)
+	return item, true
+}
+
+// expiryFor returns the expiry shard responsible for key.
+func (c *Cache[T, V]) expiryFor(key T) *expiryIndex[T] {
+	if len(c.expiry) == 1 {
+		return c.expiry[0]
+	}
+	return c.expiry[c.hasher.hash(key)%uint64(len(c.expiry))]
+}
+
+func (c *Cache[T, V]) resetExpiry() {
+	for _, x := range c.expiry {
+		x.reset()
+	}
+}
+
+func (c *Cache[T, V]) reindex(items Store[T, V]) {
+	items.ForEach(func(key T, item CachedItem[V]) bool {
+		c.expiryFor(key).add(key, c.sweepAt(item))
+		return true
+	})
+}
+
+// ExpiredPending reports how many entries are past their deadline but have
+// not been removed by the cleanup routine yet, and how long ago the oldest of
+// them expired. A count or age that keeps growing means the janitor is falling
+// behind; see WithCleanupBudget, WithExpiryShards and WithAdaptiveCleanup.
+// Entries kept by WithStaleOnError count from the end of their stale window,
+// and pinned entries are not counted. It visits every due key, so it is meant
+// for periodic monitoring rather than hot paths.
+func (c *Cache[T, V]) ExpiredPending() (n int, oldest time.Duration) {
+	now := c.nanotime()
+	seen := make(map[T]struct{})
+	for _, x := range c.expiry {
+		for _, key := range x.pending(now) {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			item, ok := c.items().Get(key)
+			if !ok || c.sweepAt(item) > now || c.immortal(key) {
+				continue
+			}
+			n++
+			oldest = max(oldest, time.Duration(now-c.sweepAt(item)))
+		}
+	}
+	return n, oldest
+}
+
+// expire runs a sweep over every expiry shard.
+func (c *Cache[T, V]) expire(now int64) {
+	for _, x := range c.expiry {
+		c.expireShard(x, now)
+	}
+}
+
+// expireShard deletes every entry of shard x whose deadline has passed,
+// visiting only the keys scheduled in due buckets. With a cleanup budget
+// configured it stops once the budget is spent and resumes from the same
+// point on the next sweep. It returns the number of entries removed and
+// whether the budget left due keys behind.
+func (c *Cache[T, V]) expireShard(x *expiryIndex[T], now int64) (expired int, more bool) {
+	nowBucket := now / x.resolution
+	keys := x.due(now)
+	var deadline time.Time
+	if c.budget.maxDuration > 0 {
+		deadline = time.Now().Add(c.budget.maxDuration)
+	}
+	for i, key := range keys {
+		if c.budget.maxEntries > 0 && i >= c.budget.maxEntries ||
+			!deadline.IsZero() && i%64 == 0 && i > 0 && time.Now().After(deadline) {
+			x.postpone(keys[i:])
+			return expired, true
+		}
+		item, ok := c.items().Get(key)
+		switch {
+		case !ok:
+		case c.sweepAt(item) <= now:
+			if c.immortal(key) {
+				// Unpin schedules it again.
+				continue
+			}
+			// A write racing the sweep replaced the item and scheduled
+			// its own expiry.
+			c.writes.Add(1)
+			ok := c.items().CompareAndDelete(key, item.version)
+			c.writes.Add(1)
+			if !ok {
+				continue
+			}
+			c.forget(key)
+			c.notify(EventExpire, key, item, nil)
+			c.invalidateDependents(key, nil)
+			expired++
+		case c.sweepAt(item)/x.resolution <= nowBucket:
+			x.add(key, c.sweepAt(item))
+		}
+	}
+	return expired, false
+}
+
+type cleanupBudget struct {
+	maxEntries  int
+	maxDuration time.Duration
+}
+
+// cleanupSchedule bounds the janitor's interval; see WithAdaptiveCleanup.
+// The zero value ticks at a fixed interval.
+type cleanupSchedule struct {
+	min, max time.Duration
+}
+
+func (s cleanupSchedule) adaptive() bool {
+	return s.max > 0
+}
+
+// start clamps the initial interval d to the bounds.
+func (s cleanupSchedule) start(d time.Duration) time.Duration {
+	return min(max(d, s.min), s.max)
+}
+
+// next returns the interval to use after a sweep that ran every d and removed
+// expired entries, compared to prev on the sweep before. The interval halves
+// while the backlog grows or a budget leaves keys behind, and doubles while
+// sweeps find nothing to remove.
+func (s cleanupSchedule) next(d time.Duration, expired, prev int, more bool) time.Duration {
+	switch {
+	case more || expired > prev:
+		d /= 2
+	case expired == 0:
+		d *= 2
+	}
+	return s.start(d)
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiryIndexDue(t *testing.T) {
+	now := nanotime()
+	x := newExpiryIndex[int](64*time.Millisecond, now)
+
+	x.add(1, now+int64(10*time.Millisecond))
+	x.add(2, now+int64(time.Hour))
+
+	assert.Empty(t, x.due(now-int64(time.Millisecond)))
+	assert.Equal(t, []int{1}, x.due(now+int64(20*time.Millisecond)))
+	assert.Empty(t, x.due(now+int64(30*time.Millisecond)), "Expected due buckets to be consumed")
+	assert.Equal(t, []int{2}, x.due(now+int64(2*time.Hour)))
+}
+
+func TestCacheExpireVisitsOnlyDueKeys(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	cache.StopCleanup()
+
+	cache.Set(1, "short")
+	item, _ := cache.items().Get(1)
+	item.expires = nanotime() - 1
+	cache.store(1, item, nil)
+	cache.Set(2, "long")
+
+	cache.expire(nanotime())
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected due entry to be expired")
+	_, found = cache.Get(2)
+	assert.True(t, found, "Expected future entry to be kept")
+}
+
+func TestCacheStoreReusesSchedule(t *testing.T) {
+	cache := NewCache[int, string](time.Hour)
+	cache.StopCleanup()
+
+	for i := 0; i < 1000; i++ {
+		cache.Set(1, "value")
+	}
+	n := 0
+	for _, keys := range cache.expiryFor(1).buckets {
+		n += len(keys)
+	}
+	assert.Less(t, n, 10, "Expected overwrites not to grow the expiry index")
+}
+
+func TestCacheCleanupBudget(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithCleanupBudget[int, string](10, 0))
+	cache.StopCleanup()
+
+	for i := 0; i < 25; i++ {
+		cache.Set(i, "value")
+	}
+	now := nanotime() + int64(2*time.Minute)
+
+	cache.expire(now)
+	assert.Equal(t, 15, cache.items().Len(), "Expected a sweep to stop at the budget")
+	cache.expire(now)
+	assert.Equal(t, 5, cache.items().Len(), "Expected the next sweep to resume")
+	cache.expire(now)
+	assert.Equal(t, 0, cache.items().Len())
+}
+
+func TestCacheShardedExpiry(t *testing.T) {
+	cache := NewCache[int, string](50*time.Millisecond, WithExpiryShards[int, string](4))
+	defer cache.StopCleanup()
+	assert.Len(t, cache.expiry, 4)
+
+	for i := 0; i < 100; i++ {
+		cache.Set(i, "value")
+	}
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, 0, cache.items().Len(), "Expected every shard's janitor to expire its keys")
+}
+
+func TestCleanupScheduleNext(t *testing.T) {
+	s := cleanupSchedule{min: time.Second, max: time.Minute}
+
+	assert.Equal(t, time.Minute, s.start(time.Hour))
+	assert.Equal(t, 10*time.Second, s.next(20*time.Second, 5, 1, false), "Expected a growing backlog to shorten the interval")
+	assert.Equal(t, 10*time.Second, s.next(20*time.Second, 0, 0, true), "Expected a spent budget to shorten the interval")
+	assert.Equal(t, 40*time.Second, s.next(20*time.Second, 0, 3, false), "Expected an empty sweep to lengthen the interval")
+	assert.Equal(t, 20*time.Second, s.next(20*time.Second, 3, 3, false))
+	assert.Equal(t, time.Second, s.next(time.Second, 9, 1, false))
+	assert.Equal(t, time.Minute, s.next(time.Minute, 0, 0, false))
+}
+
+func TestCacheAdaptiveCleanup(t *testing.T) {
+	cache := NewCache[int, string](time.Hour, WithAdaptiveCleanup[int, string](5*time.Millisecond, 20*time.Millisecond))
+	defer cache.StopCleanup()
+
+	for i := 0; i < 100; i++ {
+		cache.SetWithTTL(i, "value", 10*time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return cache.items().Len() == 0 }, time.Second, 5*time.Millisecond,
+		"Expected the janitor to sweep within its maximum interval rather than once per TTL")
+}
+
+func TestCacheExpiredPending(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	cache.StopCleanup()
+
+	for i := 0; i < 10; i++ {
+		cache.Set(i, "value")
+	}
+	n, oldest := cache.ExpiredPending()
+	assert.Zero(t, n)
+	assert.Zero(t, oldest)
+
+	now := nanotime()
+	for i := 0; i < 3; i++ {
+		item, _ := cache.items().Get(i)
+		item.expires = now - int64(time.Duration(i+1)*time.Second)
+		cache.store(i, item, nil)
+	}
+	cache.Pin(0)
+	n, oldest = cache.ExpiredPending()
+	assert.Equal(t, 2, n, "Expected expired unpinned entries to be pending")
+	assert.GreaterOrEqual(t, oldest, 3*time.Second)
+	assert.Less(t, oldest, 4*time.Second)
+
+	cache.expire(nanotime())
+	n, oldest = cache.ExpiredPending()
+	assert.Zero(t, n, "Expected a sweep to clear the backlog")
+	assert.Zero(t, oldest)
+}
+
+func TestCacheExpireKeepsRacingWrite(t *testing.T) {
+	s := &racingStore{Store: NewShardedMapStore[string, int](1)}
+	cache := NewCache[string, int](time.Minute, WithStore(func() Store[string, int] { return s }))
+	cache.StopCleanup()
+
+	cache.Set("k", 1)
+	item, _ := cache.items().Get("k")
+	item.expires = nanotime() - 1
+	cache.store("k", item, nil)
+
+	s.race = func() { cache.Set("k", 2) }
+	cache.expire(nanotime())
+	value, found := cache.Get("k")
+	assert.True(t, found, "Expected a write racing the sweep to be kept")
+	assert.Equal(t, 2, value)
+}
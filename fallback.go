@@ -0,0 +1,43 @@
+package cache
+
+// FallbackChain federates several caches in priority order. Reads try each
+// tier in turn and backfill the earlier tiers on a hit; writes go to every
+// tier.
+type FallbackChain[T comparable, V any] struct {
+	tiers []Cacher[T, V]
+}
+
+func NewFallbackChain[T comparable, V any](tiers ...Cacher[T, V]) *FallbackChain[T, V] {
+	return &FallbackChain[T, V]{tiers: tiers}
+}
+
+func (f *FallbackChain[T, V]) Get(key T) (V, bool) {
+	for i, tier := range f.tiers {
+		if value, ok := tier.Get(key); ok {
+			for _, earlier := range f.tiers[:i] {
+				earlier.Set(key, value)
+			}
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func (f *FallbackChain[T, V]) Set(key T, value V) {
+	for i := len(f.tiers) - 1; i >= 0; i-- {
+		f.tiers[i].Set(key, value)
+	}
+}
+
+func (f *FallbackChain[T, V]) Delete(key T) {
+	for i := len(f.tiers) - 1; i >= 0; i-- {
+		f.tiers[i].Delete(key)
+	}
+}
+
+func (f *FallbackChain[T, V]) Clear() {
+	for i := len(f.tiers) - 1; i >= 0; i-- {
+		f.tiers[i].Clear()
+	}
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackChainBackfills(t *testing.T) {
+	local := NewLocalCache[int, string](time.Minute, 0)
+	process := NewCache[int, string](time.Minute)
+	remote := NewCache[int, string](time.Minute)
+	chain := NewFallbackChain[int, string](local, process, remote)
+
+	remote.Set(1, "remote")
+	value, found := chain.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "remote", value)
+
+	value, found = local.Get(1)
+	assert.True(t, found, "Expected hit in a later tier to backfill the first tier")
+	assert.Equal(t, "remote", value)
+	_, found = process.Get(1)
+	assert.True(t, found, "Expected hit in a later tier to backfill the middle tier")
+
+	_, found = chain.Get(2)
+	assert.False(t, found)
+}
+
+func TestFallbackChainWritesThrough(t *testing.T) {
+	local := NewLocalCache[int, string](time.Minute, 0)
+	remote := NewCache[int, string](time.Minute)
+	chain := NewFallbackChain[int, string](local, remote)
+
+	chain.Set(1, "value")
+	_, found := remote.Get(1)
+	assert.True(t, found, "Expected Set to reach every tier")
+
+	chain.Delete(1)
+	_, found = local.Get(1)
+	assert.False(t, found)
+	_, found = remote.Get(1)
+	assert.False(t, found)
+
+	chain.Set(2, "value")
+	chain.Clear()
+	_, found = remote.Get(2)
+	assert.False(t, found)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrGenerationConflict = errors.New("cache: generation superseded")
+
+// Generation is a staged set of entries built off to the side while the
+// current contents keep serving reads. Nothing is visible until it is passed
+// to CommitGeneration.
+type Generation[T comparable, V any] struct {
+	c     *Cache[T, V]
+	items Store[T, V]
+	base  uint64
+}
+
+type generations struct {
+	mu  sync.Mutex
+	seq uint64
+}
+
+// BeginGeneration starts staging a replacement for the whole cache contents.
+func (c *Cache[T, V]) BeginGeneration() *Generation[T, V] {
+	c.gens.mu.Lock()
+	defer c.gens.mu.Unlock()
+	return &Generation[T, V]{
+		c:     c,
+		items: c.newStore(),
+		base:  c.gens.seq,
+	}
+}
+
+// Set stages value under key. The entry gets the cache's TTL, counted from
+// the commit.
+func (g *Generation[T, V]) Set(key T, value V) {
+	g.items.Set(g.c.canonical(key), CachedItem[V]{
+		Value:  value,
+		source: g.c.source(SourceSet),
+	})
+}
+
+func (g *Generation[T, V]) Len() int {
+	return g.items.Len()
+}
+
+// CommitGeneration atomically replaces the cache contents with g. It fails
+// with ErrGenerationConflict if another generation was committed after g was
+// begun. Loads in flight at commit time are discarded, as with Clear. The
+// commit is logged and streamed to replicas as a Clear followed by a Set of
+// every entry of g, and reaches the Replicator as a Clear.
+func (c *Cache[T, V]) CommitGeneration(g *Generation[T, V]) error {
+	var recs []aofRecord[T]
+	if c.aof != nil || c.stream != nil {
+		recs = c.generationRecords(g)
+	}
+	err := ErrGenerationConflict
+	if !c.logAll(recs, func() bool {
+		err = c.commitGeneration(g)
+		return err == nil
+	}) {
+		return err
+	}
+	c.replicate(aofClear, *new(T))
+	return nil
+}
+
+func (c *Cache[T, V]) commitGeneration(g *Generation[T, V]) error {
+	c.gens.mu.Lock()
+	defer c.gens.mu.Unlock()
+	if g.c != c || g.base != c.gens.seq {
+		return ErrGenerationConflict
+	}
+	c.gens.seq++
+	now := c.now()
+	g.items.ForEach(func(key T, item CachedItem[V]) bool {
+		item.created, item.expires = now, now+int64(c.ttl)
+		item.version = c.versions.Add(1)
+		g.items.Set(key, item)
+		return true
+	})
+	c.loads.advance()
+	c.resetExpiry()
+	c.writes.Add(1)
+	c.setItems(g.items)
+	c.writes.Add(1)
+	c.reindex(g.items)
+	c.retrack(g.items)
+	return nil
+}
+
+// generationRecords returns the log records of committing g. Every entry
+// of g gets the cache's TTL at commit, so the records carry it as well.
+func (c *Cache[T, V]) generationRecords(g *Generation[T, V]) []aofRecord[T] {
+	recs := []aofRecord[T]{{Op: aofClear}}
+	g.items.ForEach(func(key T, item CachedItem[V]) bool {
+		if value, ok := c.value(item); ok {
+			if rec, ok := c.aofRecord(aofSet, key, value, c.ttl); ok {
+				recs = append(recs, rec)
+			}
+		}
+		return true
+	})
+	return recs
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheGenerationRollover(t *testing.T) {
+	cache := NewCache[string, string](time.Minute)
+	cache.Set("old", "v1")
+	cache.Set("shared", "v1")
+
+	gen := cache.BeginGeneration()
+	gen.Set("shared", "v2")
+	gen.Set("new", "v2")
+	assert.Equal(t, 2, gen.Len())
+
+	value, found := cache.Get("shared")
+	assert.True(t, found)
+	assert.Equal(t, "v1", value, "Expected old generation to serve reads until commit")
+	_, found = cache.Get("new")
+	assert.False(t, found)
+
+	assert.NoError(t, cache.CommitGeneration(gen))
+
+	value, _ = cache.Get("shared")
+	assert.Equal(t, "v2", value)
+	_, found = cache.Get("new")
+	assert.True(t, found)
+	_, found = cache.Get("old")
+	assert.False(t, found, "Expected old generation to be replaced entirely")
+}
+
+func TestCacheGenerationConflict(t *testing.T) {
+	cache := NewCache[string, string](time.Minute)
+
+	first := cache.BeginGeneration()
+	second := cache.BeginGeneration()
+	assert.NoError(t, cache.CommitGeneration(second))
+	assert.ErrorIs(t, cache.CommitGeneration(first), ErrGenerationConflict)
+	assert.ErrorIs(t, NewCache[string, string](time.Minute).CommitGeneration(second), ErrGenerationConflict)
+}
+
+func TestCacheGenerationTTLStartsAtCommit(t *testing.T) {
+	cache := NewCache[string, string](50 * time.Millisecond)
+	defer cache.StopCleanup()
+
+	gen := cache.BeginGeneration()
+	gen.Set("key", "value")
+	time.Sleep(80 * time.Millisecond)
+	assert.NoError(t, cache.CommitGeneration(gen))
+
+	_, found := cache.Get("key")
+	assert.True(t, found, "Expected a staged entry's TTL to start at commit")
+	ttl, ok := cache.TTL("key")
+	assert.True(t, ok)
+	assert.Greater(t, ttl, 30*time.Millisecond)
+}
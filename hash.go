@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"hash/maphash"
+	"reflect"
+	"unsafe"
+)
+
+// keyHasher hashes cache keys. For hashable key types strings are hashed by
+// content and every other type is a fixed-size value hashed by its memory
+// representation; other comparable types need a user-supplied function.
+type keyHasher[T comparable] struct {
+	seed     maphash.Seed
+	isString bool
+	fn       func(T) uintptr
+}
+
+func newKeyHasher[T hashable]() keyHasher[T] {
+	return keyHasher[T]{
+		seed:     maphash.MakeSeed(),
+		isString: reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.String,
+	}
+}
+
+// hasherFunc returns a keyHasher that defers to fn.
+func hasherFunc[T comparable](fn func(T) uintptr) keyHasher[T] {
+	return keyHasher[T]{fn: fn}
+}
+
+func (h keyHasher[T]) hash(key T) uint64 {
+	if h.fn != nil {
+		return uint64(h.fn(key))
+	}
+	if h.isString {
+		return maphash.String(h.seed, *(*string)(unsafe.Pointer(&key)))
+	}
+	return maphash.Bytes(h.seed, unsafe.Slice((*byte)(unsafe.Pointer(&key)), unsafe.Sizeof(key)))
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namedString string
+
+func TestKeyHasher(t *testing.T) {
+	strs := newKeyHasher[namedString]()
+	assert.True(t, strs.isString)
+	a, b := namedString("session:"), namedString("session:")
+	a += "1"
+	b += "1"
+	assert.Equal(t, strs.hash(a), strs.hash(b), "Expected strings to hash by content")
+	assert.NotEqual(t, strs.hash("1"), strs.hash("2"))
+
+	ints := newKeyHasher[int64]()
+	assert.False(t, ints.isString)
+	assert.Equal(t, ints.hash(42), ints.hash(42))
+	assert.NotEqual(t, ints.hash(1), ints.hash(2))
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrStopped        = errors.New("cache: stopped")
+	ErrJanitorStalled = errors.New("cache: cleanup routine stalled")
+	ErrNotSynced      = errors.New("cache: not synchronized with primary")
+)
+
+// Pinger is implemented by backends that can check their connectivity;
+// Healthy calls Ping on the backend configured with WithBackend,
+// WithWriteThrough or WithWriteBehind.
+type Pinger interface {
+	Ping() error
+}
+
+// janitorSlack is how late, beyond twice its interval, the cleanup routine
+// may be before Healthy reports it stalled.
+const janitorSlack = time.Second
+
+// replicaLagLimit is the number of operations queued for a replica beyond
+// which Healthy reports it lagging.
+const replicaLagLimit = replicaBuffer / 2
+
+// janitorBeat records when the first cleanup routine last finished a tick.
+type janitorBeat struct {
+	last     atomic.Int64
+	interval atomic.Int64
+}
+
+func (j *janitorBeat) beat(now int64, interval time.Duration) {
+	j.interval.Store(int64(interval))
+	j.last.Store(now)
+}
+
+// Healthy returns nil if the cache is working as configured, making it
+// suitable for readiness and liveness probes. Otherwise it returns every
+// problem found, joined:
+//
+//   - ErrStopped after StopCleanup;
+//   - ErrJanitorStalled if the cleanup routine has missed two ticks;
+//   - the error of the latest snapshot, snapshot upload or append-only log
+//     write if it failed, and any StorageError;
+//   - ErrNotSynced while Follow is reconnecting, and an error when a replica
+//     of WithReplication falls behind;
+//   - the error from Ping if the backend implements Pinger.
+//
+// Unlike the Last*Error methods, Healthy forgets a persistence error once a
+// later attempt succeeds.
+func (c *Cache[T, V]) Healthy() error {
+	if c.stopped() {
+		return ErrStopped
+	}
+	var errs []error
+	if last := c.janitor.last.Load(); last != 0 {
+		late := time.Duration(c.nanotime()-last) - 2*time.Duration(c.janitor.interval.Load())
+		if late > janitorSlack {
+			errs = append(errs, ErrJanitorStalled)
+		}
+	}
+	if c.snapshot != nil && c.snapshot.failing.Load() {
+		errs = append(errs, fmt.Errorf("cache: snapshot: %w", c.LastSnapshotError()))
+	}
+	if c.uploads != nil && c.uploads.failing.Load() {
+		errs = append(errs, fmt.Errorf("cache: snapshot upload: %w", c.LastUploadError()))
+	}
+	if c.aof != nil && c.aof.failing.Load() {
+		errs = append(errs, fmt.Errorf("cache: append-only log: %w", c.LastAOFError()))
+	}
+	if err := c.StorageError(); err != nil {
+		errs = append(errs, fmt.Errorf("cache: storage: %w", err))
+	}
+	if c.synced.Load() < c.following.Load() {
+		errs = append(errs, ErrNotSynced)
+		if err := c.replErr.Load(); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	if lag := c.replicaLag(); lag > replicaLagLimit {
+		errs = append(errs, fmt.Errorf("cache: replica %d operations behind", lag))
+	}
+	if err := c.pingBackend(); err != nil {
+		errs = append(errs, fmt.Errorf("cache: backend: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// replicaLag returns the most operations queued for any connected replica.
+func (c *Cache[T, V]) replicaLag() int {
+	if c.stream == nil {
+		return 0
+	}
+	c.stream.mu.Lock()
+	defer c.stream.mu.Unlock()
+	lag := 0
+	for r := range c.stream.replicas {
+		lag = max(lag, len(r.ch))
+	}
+	return lag
+}
+
+func (c *Cache[T, V]) pingBackend() error {
+	b := c.backend
+	if b == nil && c.writeBehind != nil {
+		b = c.writeBehind.backend
+	}
+	if p, ok := b.(Pinger); ok {
+		return p.Ping()
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pingBackend struct {
+	*mapBackend[string, int]
+	err error
+}
+
+func (b *pingBackend) Ping() error { return b.err }
+
+func TestHealthy(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	assert.NoError(t, c.Healthy())
+	c.StopCleanup()
+	assert.ErrorIs(t, c.Healthy(), ErrStopped)
+}
+
+func TestHealthyJanitorStalled(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	assert.Eventually(t, func() bool { return c.janitor.last.Load() != 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, c.Healthy())
+	c.janitor.beat(c.nanotime()-int64(3*time.Minute), time.Minute)
+	assert.ErrorIs(t, c.Healthy(), ErrJanitorStalled)
+}
+
+func TestHealthyBackend(t *testing.T) {
+	b := &pingBackend{mapBackend: newMapBackend[string, int]()}
+	c := NewCache[string, int](time.Minute, WithBackend[string, int](b))
+	defer c.StopCleanup()
+	assert.NoError(t, c.Healthy())
+	b.err = errors.New("connection refused")
+	assert.ErrorIs(t, c.Healthy(), b.err)
+}
+
+func TestHealthySnapshotRecovers(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	c := NewCache[string, int](time.Minute, WithSnapshot[string, int](filepath.Join(dir, "cache.snap"), time.Hour))
+	defer c.StopCleanup()
+	assert.NoError(t, c.Healthy())
+
+	c.snapshot.record(c.SaveFile(c.snapshot.path))
+	assert.Error(t, c.Healthy(), "Expected a failed snapshot to be reported")
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	c.snapshot.record(c.SaveFile(c.snapshot.path))
+	assert.NoError(t, c.Healthy())
+	assert.Error(t, c.LastSnapshotError())
+}
+
+func TestHealthyFollower(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := NewCache[string, int](time.Minute, WithReplication[string, int](l))
+	defer primary.StopCleanup()
+	standby := NewCache[string, int](time.Minute)
+	defer standby.StopCleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- standby.Follow(ctx, l.Addr().String()) }()
+	assert.Eventually(t, func() bool { return standby.synced.Load() == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, standby.Healthy())
+
+	primary.StopCleanup()
+	assert.Eventually(t, func() bool { return errors.Is(standby.Healthy(), ErrNotSynced) }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+	assert.NoError(t, standby.Healthy())
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+)
+
+// pathIndex arranges the keys of a cache configured with WithHierarchy in a
+// tree of their path segments, so DeleteSubtree finds a subtree's keys
+// without scanning the cache.
+type pathIndex[T comparable] struct {
+	mu   sync.Mutex
+	sep  string
+	str  func(T) string
+	root pathNode[T]
+}
+
+type pathNode[T comparable] struct {
+	children map[string]*pathNode[T]
+	key      T
+	present  bool
+}
+
+func (p *pathIndex[T]) empty() *pathIndex[T] {
+	return &pathIndex[T]{sep: p.sep, str: p.str}
+}
+
+func (p *pathIndex[T]) add(key T) {
+	n := &p.root
+	for _, seg := range strings.Split(p.str(key), p.sep) {
+		child := n.children[seg]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[string]*pathNode[T])
+			}
+			child = &pathNode[T]{}
+			n.children[seg] = child
+		}
+		n = child
+	}
+	n.key, n.present = key, true
+}
+
+func (p *pathIndex[T]) remove(key T) {
+	segs := strings.Split(p.str(key), p.sep)
+	nodes := make([]*pathNode[T], 0, len(segs)+1)
+	n := &p.root
+	nodes = append(nodes, n)
+	for _, seg := range segs {
+		if n = n.children[seg]; n == nil {
+			return
+		}
+		nodes = append(nodes, n)
+	}
+	n.present = false
+	// Prune the branch back to the nearest node still in use.
+	for i := len(nodes) - 1; i > 0; i-- {
+		if nodes[i].present || len(nodes[i].children) > 0 {
+			break
+		}
+		delete(nodes[i-1].children, segs[i-1])
+	}
+}
+
+// subtree returns the keys at path and below it.
+func (p *pathIndex[T]) subtree(path string) []T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := &p.root
+	for _, seg := range strings.Split(path, p.sep) {
+		if n = n.children[seg]; n == nil {
+			return nil
+		}
+	}
+	var keys []T
+	stack := []*pathNode[T]{n}
+	for len(stack) > 0 {
+		n, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		if n.present {
+			keys = append(keys, n.key)
+		}
+		for _, child := range n.children {
+			stack = append(stack, child)
+		}
+	}
+	return keys
+}
+
+// indexPath brings key's place in the path index in line with the store.
+// Checking the store under the index lock makes racing writes and deletes
+// of a key leave the index agreeing with whichever reached the store last.
+func (c *Cache[T, V]) indexPath(key T) {
+	if c.paths == nil {
+		return
+	}
+	c.paths.mu.Lock()
+	defer c.paths.mu.Unlock()
+	if _, ok := c.items().Get(key); ok {
+		c.paths.add(key)
+	} else {
+		c.paths.remove(key)
+	}
+}
+
+// reindexPaths rebuilds the path index after the store was replaced.
+func (c *Cache[T, V]) reindexPaths(items Store[T, V]) {
+	if c.paths == nil {
+		return
+	}
+	c.paths.mu.Lock()
+	defer c.paths.mu.Unlock()
+	c.paths.root = pathNode[T]{}
+	items.ForEach(func(key T, _ CachedItem[V]) bool {
+		c.paths.add(key)
+		return true
+	})
+}
+
+// DeleteSubtree deletes the entry under path and every entry below it in a
+// cache of hierarchical keys, such as "a/b" together with "a/b/c" and
+// "a/b/c/d" but not "a/bc", and reports how many were deleted. Entries are
+// removed as by Delete. With WithHierarchy the keys are found through an
+// index in time proportional to the subtree; otherwise every key is
+// examined, with sep "/". Entries set concurrently may or may not be deleted.
+func DeleteSubtree[K ~string, V any](c *Cache[K, V], path string) int {
+	var keys []K
+	if c.paths != nil {
+		keys = c.paths.subtree(path)
+	} else {
+		c.items().ForEach(func(key K, _ CachedItem[V]) bool {
+			if s := string(key); s == path || strings.HasPrefix(s, path+"/") {
+				keys = append(keys, key)
+			}
+			return true
+		})
+	}
+	for _, key := range keys {
+		_ = c.TryDelete(key)
+	}
+	return len(keys)
+}
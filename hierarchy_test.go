@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteSubtree(t *testing.T) {
+	for _, opts := range [][]Option[string, int]{nil, {WithHierarchy[string, int]("/")}} {
+		c := NewCache[string, int](time.Minute, opts...)
+		for _, key := range []string{"a", "a/b", "a/b/c", "a/b/c/d", "a/bc", "a/x", "b/b"} {
+			c.Set(key, 1)
+		}
+		assert.Equal(t, 3, DeleteSubtree(c, "a/b"))
+		var left []string
+		c.Range(func(key string, _ int) bool {
+			left = append(left, key)
+			return true
+		})
+		sort.Strings(left)
+		assert.Equal(t, []string{"a", "a/bc", "a/x", "b/b"}, left)
+		assert.Zero(t, DeleteSubtree(c, "missing/path"))
+		assert.Equal(t, 3, DeleteSubtree(c, "a"))
+		c.StopCleanup()
+	}
+}
+
+func TestPathIndexFollowsStore(t *testing.T) {
+	c := NewCache[string, int](time.Minute, WithHierarchy[string, int]("."))
+	defer c.StopCleanup()
+	c.Set("routes.eu.fr", 1)
+	c.Set("routes.eu.de", 1)
+	c.Delete("routes.eu.fr")
+	assert.ElementsMatch(t, []string{"routes.eu.de"}, c.paths.subtree("routes"))
+	c.Delete("routes.eu.de")
+	assert.Empty(t, c.paths.root.children, "Expected empty branches to be pruned")
+
+	assert.NoError(t, c.SetWithTTL("routes.us", 1, 10*time.Millisecond))
+	c.expire(c.nanotime() + int64(time.Second))
+	assert.Empty(t, c.paths.subtree("routes"))
+
+	c.Set("routes.us", 1)
+	c.Clear()
+	assert.Empty(t, c.paths.subtree("routes"))
+
+	ns := c.Namespace("n")
+	ns.Set("x.y", 1)
+	assert.Equal(t, 1, DeleteSubtree(ns, "x"))
+}
+
+func BenchmarkDeleteSubtree(b *testing.B) {
+	c := NewCache[string, int](time.Hour, WithHierarchy[string, int]("/"))
+	defer c.StopCleanup()
+	for i := range 100000 {
+		c.Set(fmt.Sprintf("tenant/%d/config", i), i)
+	}
+	b.ResetTimer()
+	for i := range b.N {
+		key := fmt.Sprintf("tenant/%d", i%100000)
+		c.Set(key+"/config", i)
+		DeleteSubtree(c, key)
+	}
+}
@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// HookMode selects where a hook runs.
+type HookMode uint8
+
+const (
+	// HookSync runs the hook on the goroutine that made the change, after
+	// the change is applied and before the call making it returns. The
+	// cache's locks are released first, so the hook may write to the cache.
+	HookSync HookMode = iota
+	// HookAsync runs the hook on the cache's hook workers, so slow hooks do
+	// not delay writes. Hooks for different changes may run concurrently and
+	// out of order.
+	HookAsync
+)
+
+// HookFullPolicy decides what happens to a HookAsync call when the hook
+// worker queue is full.
+type HookFullPolicy uint8
+
+const (
+	// HookBlock makes the change wait for room in the queue.
+	HookBlock HookFullPolicy = iota
+	// HookDropOldest discards the oldest queued call to make room.
+	HookDropOldest
+	// HookDropNewest discards the incoming call.
+	HookDropNewest
+)
+
+// HookPoolConfig configures the workers running HookAsync hooks. Workers
+// defaults to 4 and Queue to 1024. Dropped calls are counted in
+// Stats.HooksDropped.
+type HookPoolConfig struct {
+	Workers int
+	Queue   int
+	Full    HookFullPolicy
+}
+
+type hook[T comparable, V any] struct {
+	typ  EventType
+	fn   func(key T, value V)
+	mode HookMode
+}
+
+type hooks[T comparable, V any] struct {
+	mu    sync.RWMutex
+	list  []*hook[T, V]
+	n     atomic.Int32
+	cfg   HookPoolConfig
+	start sync.Once
+	queue chan func()
+}
+
+// OnSet registers fn to be called with the key and value of every entry
+// stored in the cache, however it got there. The returned function removes
+// the hook. Unlike subscriptions, HookSync hooks and HookAsync hooks under
+// the default HookBlock policy never miss a change, so any number of them
+// can run audit logging or mirror the cache elsewhere side by side. With
+// HookDropOldest or HookDropNewest, HookAsync calls are dropped when the
+// worker queue is full and counted in Stats.HooksDropped.
+func (c *Cache[T, V]) OnSet(fn func(key T, value V), mode HookMode) func() {
+	return c.addHook(EventSet, fn, mode)
+}
+
+// OnDelete registers fn to be called with the key and last value of every
+// entry removed by Delete or its variants; see OnSet. Clear does not call it.
+func (c *Cache[T, V]) OnDelete(fn func(key T, value V), mode HookMode) func() {
+	return c.addHook(EventDelete, fn, mode)
+}
+
+// OnExpire registers fn to be called with the key and last value of every
+// entry the cleanup routine removes because it expired; see OnSet. HookSync
+// hooks run on the cleanup routine and hold it up.
+func (c *Cache[T, V]) OnExpire(fn func(key T, value V), mode HookMode) func() {
+	return c.addHook(EventExpire, fn, mode)
+}
+
+func (c *Cache[T, V]) addHook(typ EventType, fn func(T, V), mode HookMode) func() {
+	if mode == HookAsync {
+		c.hooks.start.Do(c.startHookWorkers)
+	}
+	h := &hook[T, V]{typ: typ, fn: fn, mode: mode}
+	c.hooks.mu.Lock()
+	c.hooks.list = append(c.hooks.list, h)
+	c.hooks.n.Add(1)
+	c.hooks.mu.Unlock()
+	return func() { c.removeHook(h) }
+}
+
+func (c *Cache[T, V]) removeHook(h *hook[T, V]) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	for i, x := range c.hooks.list {
+		if x == h {
+			// Copy rather than edit in place: runHooks may be iterating
+			// over the old slice.
+			c.hooks.list = append(c.hooks.list[:i:i], c.hooks.list[i+1:]...)
+			c.hooks.n.Add(-1)
+			return
+		}
+	}
+}
+
+// startHookWorkers starts the workers running HookAsync hooks. They finish
+// the queued calls when the cache is stopped; hooks triggered afterwards, for
+// instance by the final flush of a write buffer, run synchronously.
+func (c *Cache[T, V]) startHookWorkers() {
+	cfg := c.hooks.cfg
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = 1024
+	}
+	c.hooks.queue = make(chan func(), cfg.Queue)
+	for range cfg.Workers {
+		c.background(func() {
+			for {
+				select {
+				case call := <-c.hooks.queue:
+					call()
+				case <-c.stopCleanup:
+					for {
+						select {
+						case call := <-c.hooks.queue:
+							call()
+						default:
+							return
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// pendingHooks collects the HookSync calls for changes made while a cache
+// lock is held, such as the append-only log's or a transaction's, so that
+// they run once it is released and may call back into the cache.
+type pendingHooks []func()
+
+func (p *pendingHooks) run() {
+	for _, call := range *p {
+		call()
+	}
+	*p = nil
+}
+
+// runHooks calls the hooks registered for e.Type, leaving HookSync hooks in
+// pending if it is not nil.
+func (c *Cache[T, V]) runHooks(e Event[T, V], pending *pendingHooks) {
+	if c.hooks.n.Load() == 0 {
+		return
+	}
+	c.hooks.mu.RLock()
+	list := c.hooks.list
+	c.hooks.mu.RUnlock()
+	for _, h := range list {
+		if h.typ != e.Type {
+			continue
+		}
+		fn := h.fn
+		if h.mode == HookSync || c.stopped() {
+			if pending != nil {
+				*pending = append(*pending, func() { fn(e.Key, e.Value) })
+			} else {
+				fn(e.Key, e.Value)
+			}
+			continue
+		}
+		c.enqueueHook(func() { fn(e.Key, e.Value) })
+	}
+}
+
+// enqueueHook hands call to the hook workers, applying the pool's policy
+// when the queue is full.
+func (c *Cache[T, V]) enqueueHook(call func()) {
+	q := c.hooks.queue
+	select {
+	case q <- call:
+		return
+	default:
+	}
+	switch c.hooks.cfg.Full {
+	case HookDropNewest:
+	case HookDropOldest:
+		select {
+		case <-q:
+		default:
+		}
+		select {
+		case q <- call:
+		default:
+		}
+	default:
+		select {
+		case q <- call:
+		case <-c.stopCleanup:
+			call()
+		}
+		return
+	}
+	c.stats.hooksDropped.Add(1)
+}
+
+func (c *Cache[T, V]) stopped() bool {
+	select {
+	case <-c.stopCleanup:
+		return true
+	default:
+		return false
+	}
+}
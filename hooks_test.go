@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooksSync(t *testing.T) {
+	c := NewCache[string, int](20 * time.Millisecond)
+	defer c.StopCleanup()
+	var audit, mirror []string
+	c.OnSet(func(key string, value int) { audit = append(audit, "set "+key) }, HookSync)
+	c.OnDelete(func(key string, value int) { audit = append(audit, "delete "+key) }, HookSync)
+	removeMirror := c.OnSet(func(key string, value int) { mirror = append(mirror, key) }, HookSync)
+
+	c.Set("a", 1)
+	c.Delete("a")
+	c.Delete("missing")
+	assert.Equal(t, []string{"set a", "delete a"}, audit)
+	assert.Equal(t, []string{"a"}, mirror)
+
+	removeMirror()
+	c.Set("b", 2)
+	assert.Equal(t, []string{"a"}, mirror)
+	assert.Equal(t, []string{"set a", "delete a", "set b"}, audit)
+}
+
+func TestHooksSyncReenter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.aof")
+	c := NewCache[string, int](time.Minute, WithAOF[string, int](path, AOFConfig{Fsync: FsyncNever}))
+	defer c.StopCleanup()
+	c.OnSet(func(key string, value int) {
+		if !strings.HasPrefix(key, "copy/") {
+			c.Set("copy/"+key, value)
+		}
+	}, HookSync)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Set("set", 1)
+		_, _ = c.GetOrLoad("load", func(string) (int, error) { return 2, nil })
+		c.SetIfVersion("cas", 3, 0)
+		_ = c.Txn(func(tx *Tx[string, int]) error {
+			tx.Set("txn", 4)
+			return nil
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected hooks writing to the cache not to deadlock")
+	}
+	for key, want := range map[string]int{"set": 1, "load": 2, "cas": 3, "txn": 4} {
+		value, ok := c.Get("copy/" + key)
+		assert.True(t, ok, key)
+		assert.Equal(t, want, value, key)
+	}
+}
+
+func TestHooksExpireAsync(t *testing.T) {
+	c := NewCache[string, int](20 * time.Millisecond)
+	defer c.StopCleanup()
+	expired := make(chan string, 1)
+	c.OnExpire(func(key string, value int) {
+		assert.Equal(t, 1, value)
+		expired <- key
+	}, HookAsync)
+	c.Set("k", 1)
+	select {
+	case key := <-expired:
+		assert.Equal(t, "k", key)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the expire hook to run")
+	}
+}
+
+func TestHooksAsyncFinishBeforeStop(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	var mu sync.Mutex
+	seen := 0
+	c.OnSet(func(string, int) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		seen++
+		mu.Unlock()
+	}, HookAsync)
+	for i := range 50 {
+		c.Set(string(rune('a'+i)), i)
+	}
+	c.StopCleanup()
+	assert.Equal(t, 50, seen)
+	c.Set("after", 1)
+	assert.Equal(t, 51, seen)
+}
+
+func TestHookPoolDropPolicies(t *testing.T) {
+	for _, tt := range []struct {
+		full HookFullPolicy
+		want []int
+	}{
+		{HookDropNewest, []int{0, 1, 2}},
+		{HookDropOldest, []int{0, 3, 4}},
+	} {
+		c := NewCache[string, int](time.Minute, WithHookPool[string, int](HookPoolConfig{Workers: 1, Queue: 2, Full: tt.full}))
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		var mu sync.Mutex
+		var got []int
+		c.OnSet(func(key string, value int) {
+			if value == 0 {
+				started <- struct{}{}
+				<-release
+			}
+			mu.Lock()
+			got = append(got, value)
+			mu.Unlock()
+		}, HookAsync)
+
+		c.Set("k", 0)
+		<-started
+		for i := 1; i <= 4; i++ {
+			c.Set("k", i)
+		}
+		assert.Equal(t, uint64(2), c.Stats().HooksDropped)
+		close(release)
+		c.StopCleanup()
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestHookPoolBlocks(t *testing.T) {
+	c := NewCache[string, int](time.Minute, WithHookPool[string, int](HookPoolConfig{Workers: 1, Queue: 1}))
+	defer c.StopCleanup()
+	release := make(chan struct{})
+	c.OnSet(func(string, int) { <-release }, HookAsync)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	done := make(chan struct{})
+	go func() {
+		c.Set("c", 3)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected Set to wait for room in the hook queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	assert.Zero(t, c.Stats().HooksDropped)
+}
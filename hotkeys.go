@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// KeyStat reports how often a key has been read, as recorded by
+// WithAccessTracking.
+type KeyStat[T any] struct {
+	Key        T
+	Hits       uint64
+	LastAccess time.Time
+}
+
+// TopKeys returns the n most read keys, hottest first. It walks the whole
+// cache and returns nil unless WithAccessTracking is enabled.
+func (c *Cache[T, V]) TopKeys(n int) []KeyStat[T] {
+	return c.rankKeys(n, func(a, b KeyStat[T]) bool {
+		return a.Hits > b.Hits || a.Hits == b.Hits && a.LastAccess.After(b.LastAccess)
+	})
+}
+
+// ColdKeys returns the n least read keys, coldest first, breaking ties by
+// the oldest last access. Like TopKeys it requires WithAccessTracking.
+func (c *Cache[T, V]) ColdKeys(n int) []KeyStat[T] {
+	return c.rankKeys(n, func(a, b KeyStat[T]) bool {
+		return a.Hits < b.Hits || a.Hits == b.Hits && a.LastAccess.Before(b.LastAccess)
+	})
+}
+
+// rankKeys returns the first n keys in the order defined by before, keeping
+// only n candidates in memory while scanning.
+func (c *Cache[T, V]) rankKeys(n int, before func(a, b KeyStat[T]) bool) []KeyStat[T] {
+	if !c.tracking || n <= 0 {
+		return nil
+	}
+	now := c.now()
+	h := &statHeap[T]{worse: func(a, b KeyStat[T]) bool { return before(b, a) }}
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		if item.access == nil || c.expired(key, item, now) {
+			return true
+		}
+		stat := KeyStat[T]{Key: key, Hits: item.access.hits.Load()}
+		if last := item.access.lastAccess.Load(); last != 0 {
+			stat.LastAccess = wallTime(last)
+		}
+		switch {
+		case h.Len() < n:
+			heap.Push(h, stat)
+		case before(stat, h.stats[0]):
+			h.stats[0] = stat
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+	sort.Slice(h.stats, func(i, j int) bool { return before(h.stats[i], h.stats[j]) })
+	return h.stats
+}
+
+// statHeap keeps the worst of the retained candidates at the root so it can
+// be replaced by a better one.
+type statHeap[T any] struct {
+	stats []KeyStat[T]
+	worse func(a, b KeyStat[T]) bool
+}
+
+func (h *statHeap[T]) Len() int           { return len(h.stats) }
+func (h *statHeap[T]) Less(i, j int) bool { return h.worse(h.stats[i], h.stats[j]) }
+func (h *statHeap[T]) Swap(i, j int)      { h.stats[i], h.stats[j] = h.stats[j], h.stats[i] }
+func (h *statHeap[T]) Push(x any)         { h.stats = append(h.stats, x.(KeyStat[T])) }
+
+func (h *statHeap[T]) Pop() any {
+	last := h.stats[len(h.stats)-1]
+	h.stats = h.stats[:len(h.stats)-1]
+	return last
+}
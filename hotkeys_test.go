@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheTopKeys(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithAccessTracking[string, int]())
+	defer cache.StopCleanup()
+
+	for i := range 10 {
+		key := fmt.Sprint("key", i)
+		cache.Set(key, i)
+		for range i {
+			cache.Get(key)
+		}
+	}
+
+	keys := func(stats []KeyStat[string]) []string {
+		var out []string
+		for _, s := range stats {
+			out = append(out, s.Key)
+		}
+		return out
+	}
+	top := cache.TopKeys(3)
+	assert.Equal(t, []string{"key9", "key8", "key7"}, keys(top))
+	assert.Equal(t, uint64(9), top[0].Hits)
+	assert.Equal(t, []string{"key0", "key1"}, keys(cache.ColdKeys(2)))
+	assert.Len(t, cache.TopKeys(100), 10)
+}
+
+func TestCacheTopKeysUntracked(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	cache.Set("k", 1)
+	cache.Get("k")
+	assert.Nil(t, cache.TopKeys(1))
+}
+
+func TestCacheAccessSampling(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithAccessSampling[string, int](10))
+	defer cache.StopCleanup()
+
+	cache.Set("hot", 1)
+	cache.Set("cold", 1)
+	for range 10000 {
+		cache.Get("hot")
+	}
+	cache.Get("cold")
+	top := cache.TopKeys(1)
+	assert.Equal(t, "hot", top[0].Key)
+	assert.InDelta(t, 10000, top[0].Hits, 2000, "Expected sampled hits to be scaled up")
+}
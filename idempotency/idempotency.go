@@ -0,0 +1,215 @@
+// Package idempotency makes retried operations safe by remembering the
+// result of each operation under a client-supplied idempotency key.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+type call[R any] struct {
+	done   chan struct{}
+	result R
+	err    error
+}
+
+// Store records operations by key while they run and their results once
+// they succeed.
+type Store[R any] struct {
+	cache *cache.Cache[string, *call[R]]
+}
+
+// New returns a Store. Operations that run for longer than maxInFlight stop
+// blocking retries, which then execute again.
+func New[R any](maxInFlight time.Duration) *Store[R] {
+	return &Store[R]{cache: cache.NewCache[string, *call[R]](maxInFlight)}
+}
+
+// Close stops the cache's cleanup routine.
+func (s *Store[R]) Close() {
+	s.cache.StopCleanup()
+}
+
+// errPanicked marks a call whose function panicked.
+var errPanicked = errors.New("idempotency: operation panicked")
+
+// Do runs fn unless it has already succeeded for key within the last ttl, in
+// which case the stored result is returned. A call made while fn is running
+// for the same key waits for it and shares its outcome. Failures are not
+// recorded, so a retry after an error or a panic in fn runs fn again.
+func (s *Store[R]) Do(key string, ttl time.Duration, fn func() (R, error)) (R, error) {
+	return s.DoContext(context.Background(), key, ttl, fn)
+}
+
+// DoContext is Do that stops waiting for a call running for the same key,
+// returning ctx.Err(), once ctx is done.
+func (s *Store[R]) DoContext(ctx context.Context, key string, ttl time.Duration, fn func() (R, error)) (R, error) {
+	for {
+		if c, ok := s.cache.Get(key); ok {
+			select {
+			case <-c.done:
+			case <-ctx.Done():
+				var zero R
+				return zero, ctx.Err()
+			}
+			if c.err == nil {
+				return c.result, nil
+			}
+			// The call failed, and its owner removes it; try to take over.
+			continue
+		}
+		c := &call[R]{done: make(chan struct{})}
+		if !s.cache.SetIfVersion(key, c, 0) {
+			continue
+		}
+		s.run(key, ttl, c, fn)
+		return c.result, c.err
+	}
+}
+
+// run calls fn for c and records its outcome, also when fn panics, so that
+// waiting calls are released.
+func (s *Store[R]) run(key string, ttl time.Duration, c *call[R], fn func() (R, error)) {
+	c.err = errPanicked
+	defer func() {
+		if c.err != nil {
+			s.release(key, c)
+		} else if err := s.cache.SetWithTTL(key, c, ttl); err != nil {
+			s.release(key, c)
+		}
+		close(c.done)
+	}()
+	c.result, c.err = fn()
+}
+
+// release drops the record of c, unless it has been replaced by another
+// call after running for longer than maxInFlight.
+func (s *Store[R]) release(key string, c *call[R]) {
+	for {
+		err := s.cache.Txn(func(tx *cache.Tx[string, *call[R]]) error {
+			if current, ok := tx.Get(key); ok && current == c {
+				tx.Delete(key)
+			}
+			return nil
+		})
+		if !errors.Is(err, cache.ErrTxnConflict) {
+			return
+		}
+	}
+}
+
+// Forget drops the record of key.
+func (s *Store[R]) Forget(key string) {
+	s.cache.Delete(key)
+}
+
+// Header is the request header carrying the idempotency key.
+const Header = "Idempotency-Key"
+
+// MaxBodySize bounds the request bodies Handler reads to fingerprint them.
+// Larger requests carrying an idempotency key are rejected with 413 Request
+// Entity Too Large.
+const MaxBodySize = 1 << 20
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// payload is the digest of the request body that produced the response.
+	payload [sha256.Size]byte
+}
+
+// Handler replays the recorded response of next for requests repeating an
+// Idempotency-Key header seen within ttl. Requests without the header are
+// passed through. Responses with a 5xx status are not recorded, so clients
+// can retry after server errors. Keys are scoped by method, path and the
+// Authorization header, so callers cannot replay each other's responses, and
+// a key reused with a different request body is rejected with 422
+// Unprocessable Entity. A request waiting for the same key to finish gives up
+// with 409 Conflict when its context is done.
+func Handler(next http.Handler, store *Store[*Response], ttl time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+		if err != nil {
+			status := http.StatusBadRequest
+			if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, "idempotency: reading request body: "+err.Error(), status)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		payload := sha256.Sum256(body)
+		caller := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+		scoped := r.Method + " " + r.URL.Path + " " + string(caller[:]) + " " + key
+
+		executed := false
+		resp, err := store.DoContext(r.Context(), scoped, ttl, func() (*Response, error) {
+			executed = true
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			resp := &Response{Status: rec.status, Header: w.Header().Clone(), Body: rec.body.Bytes(), payload: payload}
+			if rec.status >= 500 {
+				return resp, errServer
+			}
+			return resp, nil
+		})
+		if executed {
+			return
+		}
+		if err != nil {
+			http.Error(w, "idempotency: request with the same key still in progress: "+err.Error(), http.StatusConflict)
+			return
+		}
+		if resp.payload != payload {
+			http.Error(w, "idempotency: key reused with a different request", http.StatusUnprocessableEntity)
+			return
+		}
+		h := w.Header()
+		for k, v := range resp.Header {
+			h[k] = v
+		}
+		h.Set("Idempotent-Replayed", "true")
+		w.WriteHeader(resp.Status)
+		w.Write(resp.Body)
+	})
+}
+
+var errServer = errors.New("idempotency: server error")
+
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
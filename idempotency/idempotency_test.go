@@ -0,0 +1,238 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreDo(t *testing.T) {
+	s := New[int](time.Minute)
+	defer s.Close()
+
+	var calls atomic.Int32
+	charge := func() (int, error) { return int(calls.Add(1)), nil }
+	first, err := s.Do("k", time.Minute, charge)
+	assert.NoError(t, err)
+	again, _ := s.Do("k", time.Minute, charge)
+	assert.Equal(t, first, again, "Expected a retry to return the recorded result")
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err = s.Do("bad", time.Minute, func() (int, error) { return 0, errors.New("boom") })
+	assert.Error(t, err)
+	value, err := s.Do("bad", time.Minute, charge)
+	assert.NoError(t, err, "Expected failures not to be recorded")
+	assert.Equal(t, 2, value)
+
+	s.Forget("k")
+	value, _ = s.Do("k", time.Minute, charge)
+	assert.Equal(t, 3, value)
+}
+
+func TestStoreDoInFlight(t *testing.T) {
+	s := New[int](time.Minute)
+	defer s.Close()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := s.Do("k", time.Minute, func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load(), "Expected concurrent retries to wait for the running call")
+}
+
+func TestHandler(t *testing.T) {
+	s := New[*Response](time.Minute)
+	defer s.Close()
+
+	var orders atomic.Int32
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			orders.Add(1)
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprint("/orders/", orders.Add(1)))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "created")
+	}), s, time.Minute)
+
+	post := func(path, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		if key != "" {
+			r.Header.Set(Header, key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	first := post("/orders", "abc")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	retry := post("/orders", "abc")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "created", retry.Body.String())
+	assert.Equal(t, "/orders/1", retry.Header().Get("Location"))
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+
+	post("/orders", "")
+	post("/orders", "other")
+	assert.Equal(t, int32(3), orders.Load())
+
+	post("/fail", "abc")
+	post("/fail", "abc")
+	assert.Equal(t, int32(5), orders.Load(), "Expected server errors not to be replayed")
+}
+
+func TestStoreDoPanic(t *testing.T) {
+	s := New[int](time.Minute)
+	defer s.Close()
+
+	assert.Panics(t, func() {
+		s.Do("k", time.Minute, func() (int, error) { panic("boom") })
+	})
+	value, err := s.Do("k", time.Minute, func() (int, error) { return 1, nil })
+	assert.NoError(t, err, "Expected a panic to be recorded as a failure")
+	assert.Equal(t, 1, value)
+}
+
+func TestStoreDoContext(t *testing.T) {
+	s := New[int](time.Minute)
+	defer s.Close()
+
+	release := make(chan struct{})
+	go s.Do("k", time.Minute, func() (int, error) {
+		<-release
+		return 1, nil
+	})
+	defer close(release)
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.DoContext(ctx, "k", time.Minute, func() (int, error) { return 2, nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHandlerScope(t *testing.T) {
+	s := New[*Response](time.Minute)
+	defer s.Close()
+
+	var orders atomic.Int32
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%d %s", orders.Add(1), body)
+	}), s, time.Minute)
+
+	post := func(auth, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		r.Header.Set(Header, "abc")
+		r.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	assert.Equal(t, "1 a", post("alice", "a").Body.String())
+	assert.Equal(t, "1 a", post("alice", "a").Body.String())
+	assert.Equal(t, "2 a", post("bob", "a").Body.String(), "Expected keys to be scoped by caller")
+	assert.Equal(t, http.StatusUnprocessableEntity, post("alice", "b").Code)
+	assert.Equal(t, int32(2), orders.Load())
+}
+
+func TestStoreDoFailureKeepsNewerCall(t *testing.T) {
+	s := New[int](time.Minute)
+	defer s.Close()
+
+	release := make(chan struct{})
+	failed := make(chan struct{})
+	go func() {
+		s.Do("k", time.Minute, func() (int, error) {
+			<-release
+			return 0, errors.New("boom")
+		})
+		close(failed)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s.Forget("k")
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	go s.Do("k", time.Minute, func() (int, error) {
+		close(started)
+		<-finish
+		return 1, nil
+	})
+	<-started
+	close(release)
+	<-failed
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		value, err := s.Do("k", time.Minute, func() (int, error) {
+			calls.Add(1)
+			return 2, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, value)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(finish)
+	<-done
+	assert.Zero(t, calls.Load(), "Expected a failed call not to drop the record of the call that replaced it")
+}
+
+func TestHandlerLimits(t *testing.T) {
+	s := New[*Response](time.Minute)
+	defer s.Close()
+
+	release := make(chan struct{})
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, "ok")
+	}), s, time.Minute)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", MaxBodySize+1)))
+	r.Header.Set(Header, "big")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	go func() {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set(Header, "slow")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}()
+	defer close(release)
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r = httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx)
+	r.Header.Set(Header, "slow")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusConflict, w.Code, "Expected a waiter that gives up to get an error status")
+}
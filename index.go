@@ -0,0 +1,109 @@
+package cache
+
+import "sync"
+
+// valueIndex maps an attribute derived from values to the keys holding them,
+// and each key back to its attribute so the key can be dropped on removal
+// without its value.
+type valueIndex[T comparable, V any] struct {
+	mu   sync.RWMutex
+	fn   func(V) any
+	keys map[any]map[T]struct{}
+	of   map[T]any
+}
+
+func newValueIndex[T comparable, V any](fn func(V) any) *valueIndex[T, V] {
+	return &valueIndex[T, V]{fn: fn, keys: make(map[any]map[T]struct{}), of: make(map[T]any)}
+}
+
+func (x *valueIndex[T, V]) add(key T, attr any) {
+	if old, ok := x.of[key]; ok {
+		if old == attr {
+			return
+		}
+		x.remove(key)
+	}
+	set := x.keys[attr]
+	if set == nil {
+		set = make(map[T]struct{})
+		x.keys[attr] = set
+	}
+	set[key] = struct{}{}
+	x.of[key] = attr
+}
+
+func (x *valueIndex[T, V]) remove(key T) {
+	attr, ok := x.of[key]
+	if !ok {
+		return
+	}
+	delete(x.keys[attr], key)
+	if len(x.keys[attr]) == 0 {
+		delete(x.keys, attr)
+	}
+	delete(x.of, key)
+}
+
+// indexValue brings key's entries in the value indexes in line with the
+// store; see indexPath for why the store is read under the index lock.
+func (c *Cache[T, V]) indexValue(key T) {
+	for _, x := range c.indexes {
+		x.mu.Lock()
+		item, ok := c.items().Get(key)
+		var value V
+		if ok {
+			value, ok = c.value(item)
+		}
+		if ok {
+			x.add(key, x.fn(value))
+		} else {
+			x.remove(key)
+		}
+		x.mu.Unlock()
+	}
+}
+
+// reindexValues rebuilds the value indexes after the store was replaced.
+func (c *Cache[T, V]) reindexValues(items Store[T, V]) {
+	for _, x := range c.indexes {
+		x.mu.Lock()
+		x.keys, x.of = make(map[any]map[T]struct{}), make(map[T]any)
+		items.ForEach(func(key T, item CachedItem[V]) bool {
+			if value, ok := c.value(item); ok {
+				x.add(key, x.fn(value))
+			}
+			return true
+		})
+		x.mu.Unlock()
+	}
+}
+
+// GetByIndex returns the values of the unexpired entries whose attribute in
+// the index registered as name by WithIndex equals value, in no particular
+// order. value must have the type the index function returns. It returns nil
+// for an unknown index. Like Range, it does not count as a read of the
+// entries it returns.
+func (c *Cache[T, V]) GetByIndex(name string, value any) []V {
+	x, ok := c.indexes[name]
+	if !ok {
+		return nil
+	}
+	x.mu.RLock()
+	keys := make([]T, 0, len(x.keys[value]))
+	for key := range x.keys[value] {
+		keys = append(keys, key)
+	}
+	x.mu.RUnlock()
+	var values []V
+	now := c.now()
+	for _, key := range keys {
+		item, ok := c.items().Get(key)
+		if !ok || c.expired(key, item, now) {
+			continue
+		}
+		if v, ok := c.value(item); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
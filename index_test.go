@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type session struct {
+	User  int
+	Token string
+}
+
+func TestGetByIndex(t *testing.T) {
+	c := NewCache[string, session](time.Minute, WithIndex[string, session]("user", func(s session) int { return s.User }))
+	defer c.StopCleanup()
+	c.Set("s1", session{User: 1, Token: "a"})
+	c.Set("s2", session{User: 1, Token: "b"})
+	c.Set("s3", session{User: 2, Token: "c"})
+
+	assert.ElementsMatch(t, []session{{1, "a"}, {1, "b"}}, c.GetByIndex("user", 1))
+	assert.Empty(t, c.GetByIndex("user", 3))
+	assert.Nil(t, c.GetByIndex("missing", 1))
+
+	c.Set("s2", session{User: 2, Token: "b"})
+	assert.ElementsMatch(t, []session{{1, "a"}}, c.GetByIndex("user", 1))
+	assert.ElementsMatch(t, []session{{2, "b"}, {2, "c"}}, c.GetByIndex("user", 2))
+
+	c.Delete("s1")
+	assert.Empty(t, c.GetByIndex("user", 1))
+	assert.NotContains(t, c.indexes["user"].keys, 1, "Expected empty buckets to be dropped")
+
+	assert.NoError(t, c.SetWithTTL("s4", session{User: 2}, 10*time.Millisecond))
+	c.expire(c.nanotime() + int64(time.Second))
+	assert.Len(t, c.GetByIndex("user", 2), 2)
+
+	c.Clear()
+	assert.Empty(t, c.GetByIndex("user", 2))
+	assert.Empty(t, c.indexes["user"].of)
+}
+
+func TestGetByIndexByteStorage(t *testing.T) {
+	c := NewCache[string, session](time.Minute,
+		WithByteStorage[string, session](JSONCodec[session]{}),
+		WithIndex[string, session]("token", func(s session) string { return s.Token }))
+	defer c.StopCleanup()
+	c.Set("s1", session{User: 1, Token: "a"})
+	assert.Equal(t, []session{{1, "a"}}, c.GetByIndex("token", "a"))
+
+	ns := c.Namespace("other")
+	ns.Set("s1", session{User: 9, Token: "a"})
+	assert.Equal(t, []session{{9, "a"}}, ns.GetByIndex("token", "a"))
+	assert.Equal(t, []session{{1, "a"}}, c.GetByIndex("token", "a"))
+}
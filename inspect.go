@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+)
+
+type accessStats struct {
+	hits       atomic.Uint64
+	lastAccess atomic.Int64
+}
+
+// clone returns a copy of a that is counted separately from then on.
+func (a *accessStats) clone() *accessStats {
+	c := &accessStats{}
+	c.hits.Store(a.hits.Load())
+	c.lastAccess.Store(a.lastAccess.Load())
+	return c
+}
+
+// EntryInfo describes a single entry for debugging. Hits and LastAccess are
+// only recorded with WithAccessTracking.
+type EntryInfo struct {
+	Created    time.Time
+	LastAccess time.Time
+	Hits       uint64
+	// Cost is the number of bytes the encoded value occupies with
+	// WithByteStorage, and 0 otherwise.
+	Cost    int64
+	TTL     time.Duration
+	Source  Source
+	Version uint64
+}
+
+// touch records a read of item.
+func (c *Cache[T, V]) touch(key T, item CachedItem[V]) {
+	if c.eviction != nil {
+		c.eviction.access(key)
+	}
+	if item.access == nil {
+		return
+	}
+	if c.sampleRate > 1 {
+		if rand.Uint32N(c.sampleRate) != 0 {
+			return
+		}
+		item.access.hits.Add(uint64(c.sampleRate))
+		item.access.lastAccess.Store(c.now())
+		return
+	}
+	item.access.hits.Add(1)
+	item.access.lastAccess.Store(c.now())
+}
+
+// Inspect returns metadata about the entry under key without counting as an
+// access to it.
+func (c *Cache[T, V]) Inspect(key T) (EntryInfo, bool) {
+	key = c.canonical(key)
+	item, _, ok := c.lookup(key)
+	if !ok {
+		return EntryInfo{}, false
+	}
+	info := EntryInfo{
+		Created: wallTime(item.created),
+		Cost:    int64(item.blob.n),
+		TTL:     time.Duration(item.expires - c.now()),
+		Source:  item.source,
+		Version: item.version,
+	}
+	if item.access != nil {
+		info.Hits = item.access.hits.Load()
+		if last := item.access.lastAccess.Load(); last != 0 {
+			info.LastAccess = wallTime(last)
+		}
+	}
+	return info, true
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheInspect(t *testing.T) {
+	cache := NewCache[string, string](time.Minute, WithAccessTracking[string, string](), WithProvenance[string, string]())
+	defer cache.StopCleanup()
+
+	_, found := cache.Inspect("missing")
+	assert.False(t, found)
+
+	before := time.Now().Add(-time.Second)
+	cache.Set("k", "v")
+	info, found := cache.Inspect("k")
+	assert.True(t, found)
+	assert.True(t, info.Created.After(before))
+	assert.True(t, info.LastAccess.IsZero(), "Expected no accesses yet")
+	assert.Zero(t, info.Hits)
+	assert.Equal(t, SourceSet, info.Source)
+	assert.InDelta(t, time.Minute, info.TTL, float64(time.Second))
+
+	cache.Get("k")
+	cache.Get("k")
+	cache.Get("missing")
+	info, _ = cache.Inspect("k")
+	assert.Equal(t, uint64(2), info.Hits)
+	assert.False(t, info.LastAccess.IsZero())
+
+	cache.Expire("k", time.Hour)
+	expired, _ := cache.Inspect("k")
+	assert.Equal(t, uint64(2), expired.Hits, "Expected access stats to survive a TTL change")
+	assert.Equal(t, info.Created, expired.Created)
+	assert.Greater(t, expired.TTL, time.Minute)
+}
+
+func TestCacheInspectCost(t *testing.T) {
+	cache := NewCache[string, string](time.Minute, WithByteStorage[string, string](JSONCodec[string]{}))
+	defer cache.StopCleanup()
+
+	cache.Set("k", "hello")
+	cache.Get("k")
+	info, _ := cache.Inspect("k")
+	assert.Equal(t, int64(len(`"hello"`)), info.Cost)
+	assert.Zero(t, info.Hits, "Expected hits not to be tracked by default")
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+type jsonlEntry[T, V any] struct {
+	Key     T         `json:"key"`
+	Value   V         `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// ExportJSONL writes every non-expired entry to w as one JSON object per
+// line, holding its key, value and expiry time, so a cache can be inspected
+// with jq or moved to another environment with ImportJSONL. Keys and values
+// are encoded with encoding/json whatever the configured Codec.
+func (c *Cache[T, V]) ExportJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	now, wall := c.now(), c.wallNow()
+	var err error
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		if item.expires <= now {
+			return true
+		}
+		value, ok := c.value(item)
+		if !ok {
+			return true
+		}
+		err = enc.Encode(jsonlEntry[T, V]{
+			Key:     key,
+			Value:   value,
+			Expires: wall.Add(time.Duration(item.expires - now)).UTC(),
+		})
+		return err == nil
+	})
+	return err
+}
+
+// ImportJSONL adds the entries read from r, in the format written by
+// ExportJSONL, keeping their expiry times. Entries that have expired are
+// skipped. Import stops at the first malformed line; entries before it stay
+// in the cache.
+func (c *Cache[T, V]) ImportJSONL(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var e jsonlEntry[T, V]
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("cache: JSONL entry %d: %w", line, err)
+		}
+		c.restore(e.Key, e.Value, e.Expires.Sub(c.wallNow()))
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheExportImportJSONL(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	src := NewCache[int, user](time.Minute)
+	defer src.StopCleanup()
+	src.Set(1, user{Name: "ann"})
+	src.SetWithTTL(2, user{Name: "bob"}, time.Hour)
+
+	var buf bytes.Buffer
+	assert.NoError(t, src.ExportJSONL(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2, "Expected one line per entry")
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Contains(t, line, "key")
+	assert.Contains(t, line, "value")
+	assert.Contains(t, line, "expires")
+
+	dst := NewCache[int, user](time.Minute)
+	defer dst.StopCleanup()
+	assert.NoError(t, dst.ImportJSONL(&buf))
+	assert.Equal(t, map[int]user{1: {Name: "ann"}, 2: {Name: "bob"}}, dst.Snapshot())
+	entry, _ := dst.GetEntry(2)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), entry.ExpiresAt, time.Second, "Expected expiry times to be kept")
+}
+
+func TestCacheImportJSONLSkipsExpiredAndReportsErrors(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	input := `{"key":"old","value":1,"expires":"2000-01-01T00:00:00Z"}
+{"key":"new","value":2,"expires":"2100-01-01T00:00:00Z"}
+{"key":"bad","value":"three"}
+`
+	err := cache.ImportJSONL(strings.NewReader(input))
+	assert.ErrorContains(t, err, "entry 3")
+	assert.Equal(t, map[string]int{"new": 2}, cache.Snapshot())
+}
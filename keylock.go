@@ -0,0 +1,43 @@
+package cache
+
+import "sync"
+
+const lockStripes = 256
+
+type paddedMutex struct {
+	sync.Mutex
+	_ [64 - 8]byte
+}
+
+// keyLocks is a table of mutexes shared by keys with the same hash modulo
+// its size, allocated on first use.
+type keyLocks struct {
+	once    sync.Once
+	stripes []paddedMutex
+}
+
+func (c *Cache[T, V]) stripe(key T) *sync.Mutex {
+	c.locks.once.Do(func() {
+		c.locks.stripes = make([]paddedMutex, lockStripes)
+	})
+	return &c.locks.stripes[c.hasher.hash(c.canonical(key))%lockStripes].Mutex
+}
+
+// Lock acquires an advisory lock on key, letting callers serialize
+// read-modify-write sequences on one key without a lock of their own. It does
+// not block other cache operations. Locks are striped over a fixed table, so
+// unrelated keys occasionally share one: never hold two at once.
+func (c *Cache[T, V]) Lock(key T) {
+	c.stripe(key).Lock()
+}
+
+// TryLock is Lock that fails instead of blocking if key is locked, or shares
+// its stripe with a locked key.
+func (c *Cache[T, V]) TryLock(key T) bool {
+	return c.stripe(key).TryLock()
+}
+
+// Unlock releases the lock on key taken by Lock or TryLock.
+func (c *Cache[T, V]) Unlock(key T) {
+	c.stripe(key).Unlock()
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheLock(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				cache.Lock("counter")
+				n, _ := cache.Get("counter")
+				cache.Set("counter", n+1)
+				cache.Unlock("counter")
+			}
+		}()
+	}
+	wg.Wait()
+	n, _ := cache.Get("counter")
+	assert.Equal(t, 800, n, "Expected locked increments not to be lost")
+}
+
+func TestCacheTryLock(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithKeyCanonicalizer[string, int](func(s string) string {
+		if s == "K" {
+			return "k"
+		}
+		return s
+	}))
+	defer cache.StopCleanup()
+
+	assert.True(t, cache.TryLock("k"))
+	assert.False(t, cache.TryLock("K"), "Expected locks to apply to canonical keys")
+	cache.Unlock("k")
+	assert.True(t, cache.TryLock("K"))
+	cache.Unlock("K")
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrLoaderPanicked is returned to GetOrLoad callers that were waiting on a
+// loader call that panicked. The caller that ran the loader gets the panic.
+var ErrLoaderPanicked = errors.New("cache: loader panicked")
+
+type call[V any] struct {
+	wg  sync.WaitGroup
+	val V
+	err error
+}
+
+type flightGroup[T comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[T]*call[V]
+}
+
+func (g *flightGroup[T, V]) do(key T, fn func() (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[T]*call[V])
+	}
+	if cl, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		cl.wg.Wait()
+		return cl.val, cl.err
+	}
+	cl := &call[V]{err: ErrLoaderPanicked}
+	cl.wg.Add(1)
+	g.calls[key] = cl
+	g.mu.Unlock()
+
+	// Release the call even if fn panics, so the waiters and later callers
+	// of key are not stuck on it.
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		cl.wg.Done()
+	}()
+	cl.val, cl.err = fn()
+	return cl.val, cl.err
+}
+
+// GetOrLoad returns the cached value for key, calling loader to compute it on
+// a miss or once the entry has expired. Concurrent loads of the same key are
+// collapsed into a single loader call. If loader panics, the panic reaches
+// the caller that ran it and the callers waiting on it get ErrLoaderPanicked.
+func (c *Cache[T, V]) GetOrLoad(key T, loader func(T) (V, error)) (V, error) {
+	key = c.canonical(key)
+	c.analyze(key)
+	if item, value, ok := c.lookup(key); ok && !c.shouldRefresh(item, c.now()) {
+		c.touch(key, item)
+		c.recordRead(key, true)
+		return value, nil
+	}
+	c.recordRead(key, false)
+	return c.flight.do(key, func() (V, error) {
+		epoch := c.loads.begin()
+		// The load is ended even if loader panics, so ClearAndWait does
+		// not wait for it forever.
+		var commit func(pending *pendingHooks)
+		defer func() { c.loads.end(epoch, commit) }()
+		start := c.nanotime()
+		value, err := profiled(c.profiler, func() (V, error) { return c.load(key, loader) })
+		if err == nil {
+			err = c.validate(value)
+		}
+		if err != nil {
+			if value, ok := c.stale(key); ok {
+				return value, nil
+			}
+			var zero V
+			return zero, err
+		}
+		now := c.nanotime()
+		commit = func(pending *pendingHooks) {
+			item := c.newItem(value, SourceLoader)
+			item.expires, item.delta = now+int64(c.ttl), time.Duration(now-start)
+			c.store(key, item, pending)
+		}
+		return value, nil
+	})
+}
+
+// shouldRefresh reports whether item must be recomputed. With early expiration
+// enabled it implements XFetch: each reader independently decides to refresh
+// ahead of the deadline with a probability that grows as the deadline nears
+// and with the cost of the last recomputation.
+func (c *Cache[T, V]) shouldRefresh(item CachedItem[V], now int64) bool {
+	if c.beta <= 0 || item.delta <= 0 {
+		return now >= item.expires
+	}
+	gap := int64(float64(item.delta) * c.beta * -math.Log(1-rand.Float64()))
+	return now+gap >= item.expires
+}
+
+// FetchMany returns the values for keys, calling loader once with only the
+// keys that are missing or expired. Keys the loader does not return are
+// omitted from the result, as are values rejected by the validator. Both the
+// loader and the result see canonical keys.
+func (c *Cache[T, V]) FetchMany(keys []T, loader func(missing []T) (map[T]V, error)) (map[T]V, error) {
+	result := make(map[T]V, len(keys))
+	var missing []T
+	seen := make(map[T]struct{}, len(keys))
+	now := c.now()
+	for _, key := range keys {
+		key = c.canonical(key)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		c.analyze(key)
+		if item, value, ok := c.lookup(key); ok && !c.shouldRefresh(item, now) {
+			c.touch(key, item)
+			c.recordRead(key, true)
+			result[key] = value
+			continue
+		}
+		c.recordRead(key, false)
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	epoch := c.loads.begin()
+	var commit func(pending *pendingHooks)
+	defer func() { c.loads.end(epoch, commit) }()
+	start := c.nanotime()
+	loaded, err := profiled(c.profiler, func() (map[T]V, error) {
+		return retry(c.retry, func() (map[T]V, error) { return loader(missing) })
+	})
+	if err != nil {
+		if c.maxStale <= 0 {
+			return nil, err
+		}
+		for _, key := range missing {
+			value, ok := c.stale(key)
+			if !ok {
+				return nil, err
+			}
+			result[key] = value
+		}
+		return result, nil
+	}
+	valid := make(map[T]V, len(loaded))
+	for key, value := range loaded {
+		if c.validate(value) == nil {
+			valid[c.canonical(key)] = value
+		}
+	}
+	now = c.nanotime()
+	commit = func(pending *pendingHooks) {
+		for key, value := range valid {
+			item := c.newItem(value, SourceLoader)
+			item.expires, item.delta = now+int64(c.ttl), time.Duration(now-start)
+			c.store(key, item, pending)
+		}
+	}
+	for key, value := range valid {
+		result[key] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheGetOrLoad(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	var calls int32
+
+	loader := func(key int) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "loaded", nil
+	}
+
+	value, err := cache.GetOrLoad(1, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded", value)
+
+	value, err = cache.GetOrLoad(1, loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded", value)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected loader to be called once")
+}
+
+func TestCacheGetOrLoadError(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	loadErr := errors.New("backend down")
+
+	_, err := cache.GetOrLoad(1, func(int) (string, error) {
+		return "", loadErr
+	})
+	assert.ErrorIs(t, err, loadErr)
+
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected failed load not to be cached")
+}
+
+func TestCacheGetOrLoadSingleFlight(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	var calls int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.GetOrLoad(1, func(int) (string, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(20 * time.Millisecond)
+				return "value", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected concurrent loads to be collapsed")
+}
+
+func TestCacheEarlyExpiration(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithEarlyExpiration[int, string](1))
+	now := nanotime()
+	minute := int64(time.Minute)
+
+	cheap := CachedItem[string]{expires: now + minute, delta: time.Nanosecond}
+	assert.False(t, cache.shouldRefresh(cheap, now), "Expected fresh cheap entry not to refresh")
+
+	expensive := CachedItem[string]{expires: now + int64(time.Second), delta: 1000 * time.Hour}
+	assert.True(t, cache.shouldRefresh(expensive, now), "Expected expensive entry near expiry to refresh early")
+
+	expired := CachedItem[string]{expires: now - minute}
+	assert.True(t, cache.shouldRefresh(expired, now), "Expected expired entry to refresh")
+}
+
+func TestCacheFetchMany(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	cache.Set(1, "cached")
+
+	var requested []int
+	values, err := cache.FetchMany([]int{1, 2, 3, 2}, func(missing []int) (map[int]string, error) {
+		requested = missing
+		return map[int]string{2: "two", 3: "three"}, nil
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{2, 3}, requested, "Expected loader to receive only missing keys")
+	assert.Equal(t, map[int]string{1: "cached", 2: "two", 3: "three"}, values)
+
+	value, found := cache.Get(3)
+	assert.True(t, found, "Expected loaded key to be cached")
+	assert.Equal(t, "three", value)
+
+	_, err = cache.FetchMany([]int{1, 2, 3}, func(missing []int) (map[int]string, error) {
+		t.Errorf("Expected loader not to be called, got %v", missing)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestCacheFetchManyError(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	loadErr := errors.New("backend down")
+
+	_, err := cache.FetchMany([]int{1}, func([]int) (map[int]string, error) {
+		return nil, loadErr
+	})
+	assert.ErrorIs(t, err, loadErr)
+}
+
+func TestCacheGetOrLoadPanic(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	defer cache.StopCleanup()
+	started, release := make(chan struct{}), make(chan struct{})
+
+	leader := make(chan any)
+	go func() {
+		defer func() { leader <- recover() }()
+		cache.GetOrLoad(1, func(int) (string, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	waiter := make(chan error)
+	go func() {
+		_, err := cache.GetOrLoad(1, func(int) (string, error) { return "unused", nil })
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.Equal(t, "boom", <-leader, "Expected the panic to reach the caller that ran the loader")
+	assert.ErrorIs(t, <-waiter, ErrLoaderPanicked)
+
+	value, err := cache.GetOrLoad(1, func(int) (string, error) { return "value", nil })
+	assert.NoError(t, err, "Expected the key to load again after a panic")
+	assert.Equal(t, "value", value)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, cache.ClearAndWait(ctx), "Expected a panicking load not to block ClearAndWait")
+}
+
+func TestCacheFetchManyCanonical(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithKeyCanonicalizer[string, int](strings.ToLower))
+	defer cache.StopCleanup()
+
+	values, err := cache.FetchMany([]string{"A"}, func(missing []string) (map[string]int, error) {
+		return map[string]int{"A": 1}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, values, "Expected results to be keyed by canonical keys")
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+}
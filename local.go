@@ -0,0 +1,98 @@
+package cache
+
+import "time"
+
+type localEntry[T hashable, V any] struct {
+	key        T
+	value      V
+	expires    int64
+	prev, next *localEntry[T, V]
+}
+
+// LocalCache is a TTL-aware LRU cache for use by a single goroutine, such as
+// per-request scratch memoization. It starts no goroutines and uses no
+// synchronization; expired entries are dropped lazily on access.
+type LocalCache[T hashable, V any] struct {
+	ttl      time.Duration
+	capacity int
+	entries  map[T]*localEntry[T, V]
+	head     localEntry[T, V]
+}
+
+// NewLocalCache creates a LocalCache. A capacity of zero means unbounded;
+// otherwise the least recently used entry is evicted when it is exceeded.
+func NewLocalCache[T hashable, V any](ttl time.Duration, capacity int) *LocalCache[T, V] {
+	l := &LocalCache[T, V]{
+		ttl:      ttl,
+		capacity: capacity,
+		entries:  make(map[T]*localEntry[T, V]),
+	}
+	l.head.prev, l.head.next = &l.head, &l.head
+	return l
+}
+
+func (l *LocalCache[T, V]) Set(key T, value V) {
+	expires := nanotime() + int64(l.ttl)
+	if e, ok := l.entries[key]; ok {
+		e.value, e.expires = value, expires
+		l.moveToFront(e)
+		return
+	}
+	e := &localEntry[T, V]{key: key, value: value, expires: expires}
+	l.entries[key] = e
+	l.pushFront(e)
+	if l.capacity > 0 && len(l.entries) > l.capacity {
+		l.remove(l.head.prev)
+	}
+}
+
+func (l *LocalCache[T, V]) Get(key T) (V, bool) {
+	e, ok := l.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if nanotime() > e.expires {
+		l.remove(e)
+		var zero V
+		return zero, false
+	}
+	l.moveToFront(e)
+	return e.value, true
+}
+
+func (l *LocalCache[T, V]) Delete(key T) {
+	if e, ok := l.entries[key]; ok {
+		l.remove(e)
+	}
+}
+
+func (l *LocalCache[T, V]) Clear() {
+	clear(l.entries)
+	l.head.prev, l.head.next = &l.head, &l.head
+}
+
+func (l *LocalCache[T, V]) Len() int {
+	return len(l.entries)
+}
+
+func (l *LocalCache[T, V]) pushFront(e *localEntry[T, V]) {
+	e.prev, e.next = &l.head, l.head.next
+	l.head.next.prev = e
+	l.head.next = e
+}
+
+func (l *LocalCache[T, V]) unlink(e *localEntry[T, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+}
+
+func (l *LocalCache[T, V]) moveToFront(e *localEntry[T, V]) {
+	l.unlink(e)
+	l.pushFront(e)
+}
+
+func (l *LocalCache[T, V]) remove(e *localEntry[T, V]) {
+	l.unlink(e)
+	delete(l.entries, e.key)
+}
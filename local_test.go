@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalCacheSetAndGet(t *testing.T) {
+	cache := NewLocalCache[int, string](time.Minute, 0)
+
+	cache.Set(1, "test1")
+	value, found := cache.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "test1", value)
+
+	cache.Delete(1)
+	_, found = cache.Get(1)
+	assert.False(t, found)
+
+	cache.Set(2, "test2")
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestLocalCacheTTL(t *testing.T) {
+	cache := NewLocalCache[int, string](10*time.Millisecond, 0)
+
+	cache.Set(1, "test1")
+	time.Sleep(20 * time.Millisecond)
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected expired entry to be dropped")
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestLocalCacheLRUEviction(t *testing.T) {
+	cache := NewLocalCache[int, string](time.Minute, 2)
+
+	cache.Set(1, "test1")
+	cache.Set(2, "test2")
+	cache.Get(1)
+	cache.Set(3, "test3")
+
+	_, found := cache.Get(2)
+	assert.False(t, found, "Expected least recently used key to be evicted")
+	_, found = cache.Get(1)
+	assert.True(t, found)
+	_, found = cache.Get(3)
+	assert.True(t, found)
+}
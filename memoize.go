@@ -0,0 +1,35 @@
+package cache
+
+import "time"
+
+// Memoize wraps fn so that results are cached for ttl per argument and
+// concurrent calls with the same argument share one call to fn. Errors are
+// returned to every waiting caller but not cached. The returned stop
+// function ends the cleanup routine of the cache behind the memoized
+// function; call it once the function is no longer used.
+func Memoize[K hashable, V any](ttl time.Duration, fn func(K) (V, error)) (memoized func(K) (V, error), stop func()) {
+	c := NewCache[K, V](ttl)
+	return func(key K) (V, error) {
+		return c.GetOrLoad(key, fn)
+	}, c.StopCleanup
+}
+
+type memoKey[A, B comparable] struct {
+	a A
+	b B
+}
+
+// Memoize2 is Memoize for functions of two arguments.
+func Memoize2[A, B hashable, V any](ttl time.Duration, fn func(A, B) (V, error)) (memoized func(A, B) (V, error), stop func()) {
+	ha, hb := newKeyHasher[A](), newKeyHasher[B]()
+	hash := func(k memoKey[A, B]) uintptr {
+		return uintptr(ha.hash(k.a)*31 ^ hb.hash(k.b))
+	}
+	c := NewCacheComparable[memoKey[A, B], V](ttl, hash)
+	load := func(k memoKey[A, B]) (V, error) {
+		return fn(k.a, k.b)
+	}
+	return func(a A, b B) (V, error) {
+		return c.GetOrLoad(memoKey[A, B]{a, b}, load)
+	}, c.StopCleanup
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoize(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	square, stop := Memoize(time.Minute, func(n int) (int, error) {
+		calls.Add(1)
+		<-release
+		return n * n, nil
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := square(4)
+			assert.NoError(t, err)
+			assert.Equal(t, 16, value)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	square(4)
+	assert.Equal(t, int32(1), calls.Load(), "Expected concurrent and later calls to share one result")
+
+	square(5)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMemoizeErrorsNotCached(t *testing.T) {
+	calls := 0
+	fail, stop := Memoize(time.Minute, func(string) (int, error) {
+		calls++
+		return 0, errors.New("boom")
+	})
+	defer stop()
+	_, err := fail("k")
+	assert.Error(t, err)
+	fail("k")
+	assert.Equal(t, 2, calls)
+}
+
+func TestMemoize2(t *testing.T) {
+	calls := 0
+	add, stop := Memoize2(time.Minute, func(a int, b string) (string, error) {
+		calls++
+		return b + string(rune('0'+a)), nil
+	})
+	defer stop()
+	value, _ := add(1, "x")
+	assert.Equal(t, "x1", value)
+	add(1, "x")
+	value, _ = add(2, "x")
+	assert.Equal(t, "x2", value)
+	assert.Equal(t, 2, calls)
+}
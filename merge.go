@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Merge copies every live entry of other into c. Keys only in other keep
+// their remaining TTL; for keys in both, resolve chooses the value from c's
+// value a and other's value b, and the later of the two deadlines is kept.
+// A nil resolve lets other's value win. Entries are written as by
+// SetWithTTL, so the append-only log, replicas and backends see them.
+func (c *Cache[T, V]) Merge(other *Cache[T, V], resolve func(a, b V) V) {
+	now := other.now()
+	other.items().ForEach(func(key T, item CachedItem[V]) bool {
+		remaining := time.Duration(item.expires - now)
+		b, ok := other.value(item)
+		if remaining <= 0 || !ok {
+			return true
+		}
+		if mine, a, ok := c.lookup(key); ok {
+			if resolve != nil {
+				b = resolve(a, b)
+			}
+			if left := time.Duration(mine.expires - c.now()); left > remaining {
+				remaining = left
+			}
+		}
+		_ = c.SetWithTTL(key, b, remaining)
+		return true
+	})
+}
+
+// Difference lists the keys on which two caches disagree.
+type Difference[T comparable] struct {
+	// Missing holds keys present only in the other cache.
+	Missing []T
+	// Extra holds keys present only in this cache.
+	Extra []T
+	// Changed holds keys present in both with different values.
+	Changed []T
+}
+
+// Digests returns a 64-bit digest of the encoded value of every entry, for
+// comparing caches without exchanging values, for example across a network.
+// Values whose encoding is not deterministic, such as maps, may digest
+// differently on every call.
+func (c *Cache[T, V]) Digests() map[T]uint64 {
+	codec := c.valueCodec()
+	digests := make(map[T]uint64, c.Len())
+	c.Range(func(key T, value V) bool {
+		data, err := codec.Encode(value)
+		if err != nil {
+			c.stats.codecErrors.Add(1)
+			return true
+		}
+		h := fnv.New64a()
+		h.Write(data)
+		digests[key] = h.Sum64()
+		return true
+	})
+	return digests
+}
+
+// Diff compares the contents of c and other by digest.
+func (c *Cache[T, V]) Diff(other *Cache[T, V]) Difference[T] {
+	return DiffDigests(c.Digests(), other.Digests())
+}
+
+// DiffDigests compares two sets of digests returned by Digests, mine being
+// this side's.
+func DiffDigests[T comparable](mine, theirs map[T]uint64) Difference[T] {
+	var d Difference[T]
+	for key, sum := range mine {
+		other, ok := theirs[key]
+		switch {
+		case !ok:
+			d.Extra = append(d.Extra, key)
+		case other != sum:
+			d.Changed = append(d.Changed, key)
+		}
+	}
+	for key := range theirs {
+		if _, ok := mine[key]; !ok {
+			d.Missing = append(d.Missing, key)
+		}
+	}
+	return d
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMerge(t *testing.T) {
+	a := NewCache[string, int](time.Minute)
+	b := NewCache[string, int](time.Minute)
+	defer a.StopCleanup()
+	defer b.StopCleanup()
+
+	a.Set("both", 1)
+	a.Set("a", 1)
+	b.Set("both", 2)
+	assert.NoError(t, b.SetWithTTL("b", 3, time.Hour))
+
+	a.Merge(b, func(x, y int) int { return x + y })
+	value, _ := a.Get("both")
+	assert.Equal(t, 3, value, "Expected resolve to combine both values")
+	value, _ = a.Get("b")
+	assert.Equal(t, 3, value)
+	ttl, _ := a.TTL("b")
+	assert.Greater(t, ttl, 59*time.Minute, "Expected merged keys to keep their TTL")
+	value, _ = a.Get("a")
+	assert.Equal(t, 1, value)
+
+	a.Merge(b, nil)
+	value, _ = a.Get("both")
+	assert.Equal(t, 2, value, "Expected other to win without resolve")
+}
+
+func TestCacheDiff(t *testing.T) {
+	a := NewCache[string, int](time.Minute)
+	b := NewCache[string, int](time.Minute)
+	defer a.StopCleanup()
+	defer b.StopCleanup()
+
+	a.Set("same", 1)
+	b.Set("same", 1)
+	a.Set("changed", 1)
+	b.Set("changed", 2)
+	a.Set("extra", 1)
+	b.Set("missing", 1)
+
+	d := a.Diff(b)
+	assert.Equal(t, []string{"missing"}, d.Missing)
+	assert.Equal(t, []string{"extra"}, d.Extra)
+	assert.Equal(t, []string{"changed"}, d.Changed)
+
+	a.Merge(b, nil)
+	d = a.Diff(b)
+	assert.Empty(t, d.Missing)
+	assert.Empty(t, d.Changed)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Ops is the set of cache operations a Middleware can intercept.
+type Ops[T comparable, V any] struct {
+	Get    func(key T) (V, bool)
+	Set    func(key T, value V) error
+	Delete func(key T) error
+}
+
+// Middleware wraps the operations of a cache, typically by returning Ops
+// whose functions do some work around calls to next. Fields left nil pass
+// through to next unchanged.
+type Middleware[T comparable, V any] func(next Ops[T, V]) Ops[T, V]
+
+type middlewares[T comparable, V any] struct {
+	mu  sync.Mutex
+	ops atomic.Pointer[Ops[T, V]]
+}
+
+// Use layers mw over Get, Set, TrySet, Delete and TryDelete, so
+// concerns such as metrics, tracing or authorization can be added without
+// forking the cache. Middleware added later runs first. Use is safe to call
+// concurrently with other operations.
+func (c *Cache[T, V]) Use(mw ...Middleware[T, V]) {
+	c.middleware.mu.Lock()
+	defer c.middleware.mu.Unlock()
+	ops := c.baseOps()
+	if cur := c.middleware.ops.Load(); cur != nil {
+		ops = *cur
+	}
+	for _, m := range mw {
+		next := ops
+		ops = m(next)
+		if ops.Get == nil {
+			ops.Get = next.Get
+		}
+		if ops.Set == nil {
+			ops.Set = next.Set
+		}
+		if ops.Delete == nil {
+			ops.Delete = next.Delete
+		}
+	}
+	c.middleware.ops.Store(&ops)
+}
+
+func (c *Cache[T, V]) baseOps() Ops[T, V] {
+	return Ops[T, V]{Get: c.get, Set: c.trySet, Delete: c.tryDelete}
+}
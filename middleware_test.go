@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheUse(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	var calls []string
+	trace := func(name string) Middleware[string, int] {
+		return func(next Ops[string, int]) Ops[string, int] {
+			return Ops[string, int]{
+				Get: func(key string) (int, bool) {
+					calls = append(calls, name+":get:"+key)
+					return next.Get(key)
+				},
+			}
+		}
+	}
+	readOnly := func(next Ops[string, int]) Ops[string, int] {
+		return Ops[string, int]{
+			Set: func(key string, value int) error {
+				if key == "locked" {
+					return errors.New("read-only key")
+				}
+				return next.Set(key, value)
+			},
+		}
+	}
+	cache.Use(trace("outer"), readOnly)
+	cache.Use(trace("last"))
+
+	assert.NoError(t, cache.TrySet("a", 1))
+	assert.Error(t, cache.TrySet("locked", 1))
+	cache.Set("locked", 2)
+	_, found := cache.Get("locked")
+	assert.False(t, found, "Expected middleware to intercept Set")
+
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, []string{"last:get:locked", "outer:get:locked", "last:get:a", "outer:get:a"}, calls)
+
+	cache.Delete("a")
+	_, found = cache.Get("a")
+	assert.False(t, found, "Expected unwrapped operations to pass through")
+}
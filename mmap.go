@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	mmapMagic = "MCMMAP01"
+	// mmapHeaderSize keeps the first chunk page-aligned.
+	mmapHeaderSize = 4096
+	// blobTrailerSize is the fixed part of a mapped blob's trailer: the wall
+	// clock deadline and the key length, followed by the encoded key.
+	blobTrailerSize = 12
+	// deadSeq marks a mapped blob whose entry is gone, so that a restart does
+	// not bring it back. Unlike a zero sequence it does not end the chunk.
+	deadSeq = ^uint32(0)
+)
+
+// mappedFile is a file mapped into memory as the arena of WithMmapStorage.
+type mappedFile struct {
+	f    *os.File
+	data []byte
+	err  atomic.Pointer[error]
+}
+
+func (m *mappedFile) record(err error) {
+	if err != nil {
+		m.err.Store(&err)
+	}
+}
+
+// openMapped maps the file at path, creating it with room for size bytes if
+// it is empty. An existing file keeps its size.
+func openMapped(path string, size int64) (*mappedFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fresh := info.Size() == 0
+	if fresh {
+		chunks := (size - mmapHeaderSize) / arenaChunkSize
+		if chunks < 1 {
+			f.Close()
+			return nil, fmt.Errorf("cache: mmap size %d is below one %d byte chunk", size, arenaChunkSize)
+		}
+		size = mmapHeaderSize + chunks*arenaChunkSize
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, err
+		}
+	} else {
+		size = info.Size()
+		if size < mmapHeaderSize+arenaChunkSize || (size-mmapHeaderSize)%arenaChunkSize != 0 {
+			f.Close()
+			return nil, fmt.Errorf("cache: %s is not a cache mmap file", path)
+		}
+	}
+	data, err := mmap(f, int(size))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m := &mappedFile{f: f, data: data}
+	if fresh {
+		copy(data, mmapMagic)
+		binary.LittleEndian.PutUint64(data[len(mmapMagic):], arenaChunkSize)
+	} else if !bytes.Equal(data[:len(mmapMagic)], []byte(mmapMagic)) ||
+		binary.LittleEndian.Uint64(data[len(mmapMagic):]) != arenaChunkSize {
+		m.close()
+		return nil, fmt.Errorf("cache: %s is not a cache mmap file", path)
+	}
+	return m, nil
+}
+
+func (m *mappedFile) close() error {
+	err := errors.Join(msync(m.data), munmap(m.data))
+	m.data = nil
+	return errors.Join(err, m.f.Close())
+}
+
+// newMappedArena returns an arena whose chunks are the slices of m past its
+// header.
+func newMappedArena[V any](codec Codec[V], m *mappedFile) *byteArena[V] {
+	a := &byteArena[V]{codec: codec, mapped: m}
+	for off := mmapHeaderSize; off < len(m.data); off += arenaChunkSize {
+		a.chunks = append(a.chunks, &arenaChunk{buf: m.data[off : off+arenaChunkSize : off+arenaChunkSize]})
+	}
+	return a
+}
+
+// mappedBlob is a blob found in a mapped chunk by walk.
+type mappedBlob struct {
+	ref     blobRef
+	expires int64
+	key     []byte
+}
+
+// walk calls fn for every blob in chunk i, live or dead, and returns the end
+// of the last one. It stops at a zero sequence number or a malformed blob.
+func (a *byteArena[V]) walk(i uint32, fn func(b mappedBlob)) int {
+	buf := a.chunks[i].buf
+	off := 0
+	for off+blobHeaderSize+blobTrailerSize <= len(buf) {
+		seq := binary.LittleEndian.Uint32(buf[off:])
+		n := int(binary.LittleEndian.Uint32(buf[off+4:]))
+		if seq == 0 || off+blobHeaderSize+n+blobTrailerSize > len(buf) {
+			break
+		}
+		trailer := buf[off+blobHeaderSize+n:]
+		keyLen := int(binary.LittleEndian.Uint32(trailer[8:]))
+		end := off + blobHeaderSize + n + blobTrailerSize + keyLen
+		if end > len(buf) {
+			break
+		}
+		fn(mappedBlob{
+			ref:     blobRef{chunk: i, off: uint32(off), n: uint32(n), seq: seq},
+			expires: int64(binary.LittleEndian.Uint64(trailer)),
+			key:     trailer[blobTrailerSize : blobTrailerSize+keyLen],
+		})
+		off = end
+	}
+	return off
+}
+
+func (a *byteArena[V]) bury(ref blobRef) {
+	binary.LittleEndian.PutUint32(a.chunks[ref.chunk].buf[ref.off:], deadSeq)
+}
+
+// buryDead marks every blob in chunk i that is not in live as dead. Callers
+// hold a.mu.
+func (a *byteArena[V]) buryDead(i uint32, live map[blobRef]struct{}) {
+	a.walk(i, func(b mappedBlob) {
+		if _, ok := live[b.ref]; !ok && b.ref.seq != deadSeq {
+			a.bury(b.ref)
+		}
+	})
+}
+
+// close flushes and unmaps the file. Later reads miss and later writes keep
+// values on the heap.
+func (a *byteArena[V]) close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.chunks, a.free = nil, nil
+	return a.mapped.close()
+}
+
+// mapItem moves the value of an item about to be stored under key into the
+// mapped file, along with the key and deadline a restart needs. Values that
+// do not fit stay on the heap.
+func (c *Cache[T, V]) mapItem(key T, item CachedItem[V]) CachedItem[V] {
+	k, err := GobCodec[T]{}.Encode(key)
+	if err != nil {
+		c.stats.codecErrors.Add(1)
+		return item
+	}
+	trailer := make([]byte, blobTrailerSize+len(k))
+	expires := c.wallNow().Add(time.Duration(item.expires - c.now()))
+	binary.LittleEndian.PutUint64(trailer, uint64(expires.UnixNano()))
+	binary.LittleEndian.PutUint32(trailer[8:], uint32(len(k)))
+	copy(trailer[blobTrailerSize:], k)
+	ref, err := c.arena.put(item.Value, trailer)
+	if err != nil {
+		if !errors.Is(err, errArenaFull) {
+			c.stats.codecErrors.Add(1)
+		}
+		c.arena.mapped.record(err)
+		return item
+	}
+	item.blob, item.Value = ref, *new(V)
+	return item
+}
+
+// restoreMapped rebuilds the entries held in the mapped file without decoding
+// their values. Where a key was written more than once the newest blob wins;
+// the others, and expired ones, are marked dead.
+func (c *Cache[T, V]) restoreMapped() {
+	a := c.arena
+	type found struct {
+		key     T
+		ref     blobRef
+		expires int64
+	}
+	latest := make(map[T]found)
+	now := c.wallNow().UnixNano()
+	a.mu.Lock()
+	for i := range a.chunks {
+		idx := uint32(i)
+		used := a.walk(idx, func(b mappedBlob) {
+			if b.ref.seq == deadSeq {
+				return
+			}
+			if b.expires <= now {
+				a.bury(b.ref)
+				return
+			}
+			key, err := GobCodec[T]{}.Decode(b.key)
+			if err != nil {
+				c.arena.mapped.record(err)
+				a.bury(b.ref)
+				return
+			}
+			if prev, ok := latest[key]; ok {
+				if seqAfter(prev.ref.seq, b.ref.seq) {
+					a.bury(b.ref)
+					return
+				}
+				a.bury(prev.ref)
+			}
+			latest[key] = found{key: key, ref: b.ref, expires: b.expires}
+			if seqAfter(b.ref.seq, a.seq) {
+				a.seq = b.ref.seq
+			}
+		})
+		a.chunks[i].used = used
+	}
+	// Carry on in the emptiest chunk; the other empty ones are free.
+	for i, ch := range a.chunks {
+		if ch.used < a.chunks[a.current].used {
+			a.current = uint32(i)
+		}
+	}
+	for i, ch := range a.chunks {
+		if ch.used == 0 && uint32(i) != a.current {
+			a.free = append(a.free, uint32(i))
+		}
+	}
+	a.mu.Unlock()
+
+	for _, f := range latest {
+		item := c.newItem(*new(V), SourceSnapshot)
+		item.blob = f.ref
+		item.expires = c.now() + f.expires - now
+		item.explicit = true
+		c.store(c.canonical(f.key), item, nil)
+	}
+}
+
+// seqAfter reports whether blob sequence number a was handed out after b,
+// allowing for wraparound.
+func seqAfter(a, b uint32) bool {
+	return int32(a-b) > 0
+}
+
+// StorageError returns the most recent error from the file backing
+// WithMmapStorage, or nil. If the file could not be mapped, values are kept
+// in heap arenas as with WithByteStorage.
+func (c *Cache[T, V]) StorageError() error {
+	if c.mmapErr != nil {
+		return c.mmapErr
+	}
+	if c.arena == nil || c.arena.mapped == nil {
+		return nil
+	}
+	if err := c.arena.mapped.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package cache
+
+import (
+	"errors"
+	"os"
+)
+
+func mmap(f *os.File, size int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func msync(data []byte) error {
+	return nil
+}
+
+func munmap(data []byte) error {
+	return nil
+}
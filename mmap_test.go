@@ -0,0 +1,75 @@
+//go:build unix
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMmapStorageSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values")
+	open := func() *Cache[string, string] {
+		return NewCache[string, string](time.Hour, WithMmapStorage[string, string](path, 4<<20, GobCodec[string]{}))
+	}
+
+	cache := open()
+	assert.NoError(t, cache.StorageError())
+	cache.Set("a", "one")
+	cache.Set("b", "two")
+	cache.Set("a", "three")
+	cache.Set("gone", "deleted")
+	cache.Delete("gone")
+	cache.SetWithTTL("short", "expired", 10*time.Millisecond)
+	item, _ := cache.items().Get("a")
+	assert.Zero(t, item.Value, "Expected the value to live in the mapped file")
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, "three", value)
+	cache.StopCleanup()
+
+	_, found = cache.Get("a")
+	assert.False(t, found, "Expected lookups to miss once the file is unmapped")
+
+	time.Sleep(20 * time.Millisecond)
+	cache = open()
+	defer cache.StopCleanup()
+	assert.NoError(t, cache.StorageError())
+	assert.Equal(t, map[string]string{"a": "three", "b": "two"}, cache.Snapshot())
+
+	cache.Set("c", "four")
+	value, found = cache.Get("c")
+	assert.True(t, found)
+	assert.Equal(t, "four", value)
+}
+
+func TestCacheMmapStorageFull(t *testing.T) {
+	cache := NewCache[int, []byte](time.Hour, WithMmapStorage[int, []byte](filepath.Join(t.TempDir(), "values"), 2<<20, GobCodec[[]byte]{}))
+	defer cache.StopCleanup()
+
+	for i := 0; i < 3; i++ {
+		cache.Set(i, make([]byte, 600<<10))
+	}
+	assert.ErrorIs(t, cache.StorageError(), errArenaFull)
+	for i := 0; i < 3; i++ {
+		value, found := cache.Get(i)
+		assert.True(t, found, "Expected values that do not fit to stay on the heap")
+		assert.Len(t, value, 600<<10)
+	}
+}
+
+func TestCacheMmapStorageRejectsForeignFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values")
+	assert.NoError(t, os.WriteFile(path, []byte("not a cache file"), 0o600))
+
+	cache := NewCache[string, string](time.Hour, WithMmapStorage[string, string](path, 4<<20, GobCodec[string]{}))
+	defer cache.StopCleanup()
+	assert.Error(t, cache.StorageError())
+	cache.Set("a", "one")
+	value, _ := cache.Get("a")
+	assert.Equal(t, "one", value, "Expected a fallback to heap arenas")
+}
@@ -0,0 +1,85 @@
+package cache
+
+import "sync"
+
+type namespaces[T comparable, V any] struct {
+	mu sync.Mutex
+	m  map[string]*Cache[T, V]
+}
+
+// Namespace returns the sub-cache registered under name, creating it on first
+// use. Each namespace has its own keys, Clear and Stats, and shares the
+// parent's TTL and in-memory options: early expiration, key canonicalization,
+// validation, provenance, byte storage, clock, cleanup budget and schedule,
+// expiry shards, store, profiling, hierarchy, value indexes and key order.
+// Backends, snapshots, the append-only log and spill stores are not
+// inherited, since they have no notion of namespaces. StopCleanup on the
+// parent also stops every namespace.
+func (c *Cache[T, V]) Namespace(name string) *Cache[T, V] {
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+	if ns, ok := c.namespaces.m[name]; ok {
+		return ns
+	}
+	if c.namespaces.m == nil {
+		c.namespaces.m = make(map[string]*Cache[T, V])
+	}
+	ns := newCache(c.ttl, c.hasher, c.storeFactory, []Option[T, V]{c.inherit})
+	c.namespaces.m[name] = ns
+	return ns
+}
+
+// inherit copies the in-memory configuration of c onto a new namespace.
+func (c *Cache[T, V]) inherit(ns *Cache[T, V]) {
+	ns.beta = c.beta
+	ns.canonicalize = c.canonicalize
+	ns.validator = c.validator
+	ns.validateOnRead = c.validateOnRead
+	ns.provenance = c.provenance
+	ns.codec = c.codec
+	if c.arena != nil {
+		ns.arena = newByteArena(c.codec)
+	}
+	ns.exactTime = c.exactTime
+	ns.clock = c.clock
+	ns.maxStale = c.maxStale
+	ns.breakers = c.breakers
+	ns.retry = c.retry
+	if c.eviction != nil {
+		ns.eviction = c.eviction.clone()
+	}
+	ns.pinnedExpiry = c.pinnedExpiry
+	ns.weigher, ns.autoWeigh = c.weigher, c.autoWeigh
+	if c.buffer != nil {
+		ns.buffer = newWriteBuffer[T, V](len(c.buffer.slots))
+	}
+	ns.budget, ns.schedule = c.budget, c.schedule
+	ns.capacity = c.capacity
+	ns.tracking, ns.sampleRate = c.tracking, c.sampleRate
+	if c.reuse != nil {
+		ns.reuse = newReuseTracker[T](int(c.reuse.rate))
+	}
+	ns.profiler = c.profiler
+	ns.hooks.cfg = c.hooks.cfg
+	if c.paths != nil {
+		ns.paths = c.paths.empty()
+	}
+	if c.order != nil {
+		ns.order = newOrderedIndex(c.order.less)
+	}
+	for name, x := range c.indexes {
+		if ns.indexes == nil {
+			ns.indexes = make(map[string]*valueIndex[T, V])
+		}
+		ns.indexes[name] = newValueIndex[T](x.fn)
+	}
+	ns.expiry = make([]*expiryIndex[T], len(c.expiry))
+}
+
+func (c *Cache[T, V]) stopNamespaces() {
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+	for _, ns := range c.namespaces.m {
+		ns.StopCleanup()
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheNamespaceIsolation(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	a := cache.Namespace("tenant-a")
+	b := cache.Namespace("tenant-b")
+	assert.Same(t, a, cache.Namespace("tenant-a"), "Expected the same namespace to be returned")
+
+	cache.Set("k", 0)
+	a.Set("k", 1)
+	b.Set("k", 2)
+
+	value, _ := a.Get("k")
+	assert.Equal(t, 1, value)
+	value, _ = b.Get("k")
+	assert.Equal(t, 2, value)
+
+	a.Clear()
+	_, found := a.Get("k")
+	assert.False(t, found, "Expected Clear to empty the namespace")
+	value, found = b.Get("k")
+	assert.True(t, found, "Expected other namespaces to survive Clear")
+	assert.Equal(t, 2, value)
+	value, found = cache.Get("k")
+	assert.True(t, found, "Expected the parent to survive a namespace Clear")
+	assert.Equal(t, 0, value)
+}
+
+func TestCacheNamespaceInheritsOptions(t *testing.T) {
+	cache := NewCache[string, int](50*time.Millisecond, WithValidator[string, int](func(v int) error {
+		if v < 0 {
+			return errors.New("negative")
+		}
+		return nil
+	}))
+
+	ns := cache.Namespace("tenant")
+	_, err := ns.GetOrLoad("k", func(string) (int, error) { return -1, nil })
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	assert.Equal(t, uint64(1), ns.Stats().ValidationFailures)
+	assert.Equal(t, uint64(0), cache.Stats().ValidationFailures, "Expected stats to be kept per namespace")
+
+	ns.Set("k", 1)
+	time.Sleep(150 * time.Millisecond)
+	_, found := ns.Get("k")
+	assert.False(t, found, "Expected namespaces to expire entries with the parent's TTL")
+
+	cache.StopCleanup()
+	ns.StopCleanup()
+}
@@ -0,0 +1,44 @@
+package cache
+
+// NopCache is a Cacher that stores nothing: every Get misses and writes are
+// discarded. The zero value is ready to use, which makes it a convenient
+// stand-in when caching is disabled by configuration.
+type NopCache[T comparable, V any] struct{}
+
+func (NopCache[T, V]) Get(key T) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+func (NopCache[T, V]) Set(key T, value V) {}
+
+func (NopCache[T, V]) Delete(key T) {}
+
+func (NopCache[T, V]) Clear() {}
+
+// GetOrLoad calls loader on every lookup, mirroring Cache.GetOrLoad.
+func (NopCache[T, V]) GetOrLoad(key T, loader func(T) (V, error)) (V, error) {
+	return loader(key)
+}
+
+// PassThrough is a Cacher that answers every Get by calling its loader and
+// never stores anything, so reads always reach the origin.
+type PassThrough[T comparable, V any] struct {
+	NopCache[T, V]
+	loader func(T) (V, error)
+}
+
+func NewPassThrough[T comparable, V any](loader func(T) (V, error)) *PassThrough[T, V] {
+	return &PassThrough[T, V]{loader: loader}
+}
+
+// Get reports a miss when the loader fails.
+func (p *PassThrough[T, V]) Get(key T) (V, bool) {
+	value, err := p.loader(key)
+	return value, err == nil
+}
+
+var (
+	_ Cacher[int, int] = NopCache[int, int]{}
+	_ Cacher[int, int] = (*PassThrough[int, int])(nil)
+)
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNopCache(t *testing.T) {
+	var c Cacher[string, int] = NopCache[string, int]{}
+	c.Set("k", 1)
+	_, found := c.Get("k")
+	assert.False(t, found, "Expected writes to be discarded")
+
+	calls := 0
+	loader := func(string) (int, error) { calls++; return 2, nil }
+	for range 2 {
+		value, err := NopCache[string, int]{}.GetOrLoad("k", loader)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, value)
+	}
+	assert.Equal(t, 2, calls, "Expected every lookup to reach the loader")
+}
+
+func TestPassThrough(t *testing.T) {
+	c := NewPassThrough(func(key string) (int, error) {
+		if key == "bad" {
+			return 0, errors.New("boom")
+		}
+		return len(key), nil
+	})
+	c.Set("abc", 10)
+	value, found := c.Get("abc")
+	assert.True(t, found)
+	assert.Equal(t, 3, value, "Expected reads to ignore writes and go to the loader")
+	_, found = c.Get("bad")
+	assert.False(t, found)
+}
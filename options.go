@@ -0,0 +1,485 @@
+package cache
+
+import (
+	"cmp"
+	"io"
+	"net"
+	"time"
+)
+
+type Option[T comparable, V any] func(*Cache[T, V])
+
+// WithEarlyExpiration enables probabilistic early expiration (XFetch) for
+// GetOrLoad. Larger beta values favour earlier recomputation; 1 is the
+// recommended default.
+func WithEarlyExpiration[T comparable, V any](beta float64) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.beta = beta
+	}
+}
+
+// WithKeyCanonicalizer applies fn to every key before it reaches the cache, so
+// equivalent spellings of a key share a single entry.
+func WithKeyCanonicalizer[T comparable, V any](fn func(T) T) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.canonicalize = fn
+	}
+}
+
+// WithValidator rejects loader results for which fn returns an error, so they
+// are neither cached nor returned. Rejections are counted in
+// Stats.ValidationFailures.
+func WithValidator[T comparable, V any](fn func(V) error) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.validator = fn
+	}
+}
+
+// WithValidateOnRead additionally runs the validator on cached values at read
+// time, dropping entries that fail.
+func WithValidateOnRead[T comparable, V any]() Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.validateOnRead = true
+	}
+}
+
+// WithBackend sets the backend used by Fetch to load missing keys.
+func WithBackend[T comparable, V any](b Backend[T, V]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.backend = b
+	}
+}
+
+// WithWriteThrough sets b as the backend and makes Set and Delete
+// synchronously propagate to it before returning.
+func WithWriteThrough[T comparable, V any](b Backend[T, V]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.backend = b
+		c.writeThrough = true
+	}
+}
+
+// WithProvenance records the Source of every entry, exposed through GetEntry
+// and DumpMetadata.
+func WithProvenance[T comparable, V any]() Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.provenance = true
+	}
+}
+
+// WithWriteBehind sets b as the backend and queues Set and Delete for
+// asynchronous, batched propagation to it. Pending writes are flushed on
+// StopCleanup or by calling Flush.
+func WithWriteBehind[T comparable, V any](b Backend[T, V], cfg WriteBehindConfig) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.backend = b
+		c.writeThrough = false
+		c.writeBehind = newWriteBehind(b, cfg, func() {
+			c.stats.backendErrors.Add(1)
+		})
+	}
+}
+
+// WithSnapshot restores the cache from the snapshot at path on construction
+// and rewrites it every interval, and once more on StopCleanup.
+func WithSnapshot[T comparable, V any](path string, interval time.Duration) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.snapshot = &snapshotter{path: path, interval: interval}
+	}
+}
+
+// WithWarmup fills a new cache from seed in the background, as Warm does, and
+// keeps Ready false until it is done, so a service can hold back its
+// readiness probe, or block in WaitReady, instead of starting cold. Lookups
+// are served while warming. StopCleanup cancels warming that has not finished.
+func WithWarmup[T comparable, V any](seed func(yield func(T, V)) error) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.warmup = &warmup[T, V]{seed: seed, done: make(chan struct{})}
+	}
+}
+
+// WithSnapshotUploads writes a snapshot to store every interval and once more
+// on StopCleanup, so that a replacement node can pick up the cache's warm
+// state with RestoreLatest. Snapshots are named after the time they were
+// taken; pruning old ones is left to the store, for example through a bucket
+// lifecycle rule. See LastUploadError.
+func WithSnapshotUploads[T comparable, V any](store SnapshotStore, interval time.Duration) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.uploads = &uploader{store: store, interval: interval}
+	}
+}
+
+// WithAOF logs every Set, Delete and Clear to an append-only file at path and
+// replays it on construction. See AOFConfig for durability and compaction
+// settings.
+func WithAOF[T comparable, V any](path string, cfg AOFConfig) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.aof = &appendLog[T, V]{path: path, cfg: cfg}
+	}
+}
+
+// WithCodec sets the Codec used to serialize values for snapshots and the
+// append-only log. GobCodec is used by default.
+func WithCodec[T comparable, V any](codec Codec[V]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.codec = codec
+	}
+}
+
+// WithByteStorage stores values serialized with codec in large pre-allocated
+// byte arenas instead of as Go values, reducing the number of pointers the
+// garbage collector has to scan. Reads decode a fresh copy of the value.
+// Arena chunks are recycled by the cleanup routine once none of their values
+// is referenced.
+func WithByteStorage[T comparable, V any](codec Codec[V]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.codec = codec
+		c.arena = newByteArena(codec)
+	}
+}
+
+// WithMmapStorage is WithByteStorage with the arena kept in a memory-mapped
+// file at path instead of on the Go heap, so caches larger than RAM can be
+// paged by the kernel and the entries survive a restart: a new cache opened
+// on the same file rebuilds its index from the keys and deadlines stored next
+// to each value, without decoding the values. size bounds a new file and is
+// rounded down to whole 1 MiB chunks; an existing file keeps its size. Keys
+// are encoded with gob. Values that do not fit stay on the heap until the
+// cleanup routine frees chunks. Deadlines are recorded as of each write, and
+// entries removed less than a cleanup interval before a crash may come back.
+// StopCleanup unmaps the file, after which lookups miss. If the file cannot be
+// mapped the cache behaves as with WithByteStorage; see StorageError.
+func WithMmapStorage[T comparable, V any](path string, size int64, codec Codec[V]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.codec = codec
+		m, err := openMapped(path, size)
+		if err != nil {
+			c.mmapErr = err
+			c.arena = newByteArena(codec)
+			return
+		}
+		c.arena = newMappedArena(codec, m)
+	}
+}
+
+// WithExactTime makes the cache read the clock on every operation instead of
+// using the shared millisecond-resolution clock.
+func WithExactTime[T comparable, V any]() Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.exactTime = true
+	}
+}
+
+// WithStaleOnError keeps entries for maxStale after they expire and returns
+// them from GetOrLoad, Fetch and FetchMany when the loader fails, so an origin
+// outage degrades to serving stale data. Get stops returning an entry as soon
+// as it expires.
+func WithStaleOnError[T comparable, V any](maxStale time.Duration) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.maxStale = maxStale
+	}
+}
+
+// WithCircuitBreaker stops calling the loader in GetOrLoad and Fetch for a
+// group of keys after repeated failures, returning ErrCircuitOpen, or a stale
+// value with WithStaleOnError, until a probe load succeeds.
+func WithCircuitBreaker[T comparable, V any](cfg BreakerConfig[T]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.breakers = newBreakers(cfg)
+	}
+}
+
+// WithLoaderRetry makes GetOrLoad, Fetch and FetchMany call a failing loader
+// up to attempts times in total, sleeping for backoff(n) before retry n. A
+// nil backoff retries immediately; ExponentialBackoff suits most origins.
+// With a circuit breaker, a whole series of attempts counts as one failure.
+func WithLoaderRetry[T comparable, V any](attempts int, backoff BackoffFunc) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.retry = &retryPolicy{attempts: attempts, backoff: backoff}
+	}
+}
+
+// WithClock makes the cache read time from clock instead of the system clock.
+// It drives entry deadlines, the cleanup routine and the snapshot interval.
+func WithClock[T comparable, V any](clock Clock) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.clock = clock
+	}
+}
+
+// WithMaxEntries bounds the cache to n entries, evicting the least recently
+// used entry of the lowest Priority to make room for a new one. Evictions are
+// reported as EventEvict and counted in Stats.Evictions; pinned entries are
+// exempt. Namespaces get a limit of their own.
+func WithMaxEntries[T comparable, V any](n int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		if n < 1 {
+			n = 1
+		}
+		c.limits().maxEntries = n
+	}
+}
+
+// WithMaxCost bounds the total cost of the entries to maxCost, evicting as
+// for WithMaxEntries. An entry's cost is computed by WithWeigher's function
+// if set, and is otherwise its encoded size with WithByteStorage or an
+// estimate of its size in bytes: exact for strings, byte slices, slices of
+// fixed-size elements and types without pointers, and the shallow size of
+// anything else. Entries costing more than maxCost are evicted right away,
+// without displacing others.
+func WithMaxCost[T comparable, V any](maxCost int64) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		if maxCost < 1 {
+			maxCost = 1
+		}
+		c.limits().maxCost = maxCost
+		c.autoWeigh = autoWeigher[V]()
+	}
+}
+
+// WithWeigher sets the function computing the cost of an entry for
+// WithMaxCost. It is called on every write and must be cheap.
+func WithWeigher[T comparable, V any](weigh func(key T, value V) int64) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.weigher = weigh
+	}
+}
+
+// WithPinnedExpiry lets pinned entries expire at the end of their TTL. By
+// default a pinned entry is only removed explicitly.
+func WithPinnedExpiry[T comparable, V any]() Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.pinnedExpiry = true
+	}
+}
+
+// WithEvictionPolicy selects the algorithm choosing which entry to evict
+// under WithMaxEntries or WithMaxCost. The default is EvictLRU.
+func WithEvictionPolicy[T comparable, V any](p EvictionPolicy) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.limits().setPolicy(p)
+	}
+}
+
+// WithAdmissionFilter stops new keys from displacing entries of a cache that
+// is full under WithMaxEntries or WithMaxCost until they have been written
+// twice within window, so keys written only once are rejected instead of
+// pushing out established entries. A rejected write is dropped before it is
+// stored: it fires no EventSet or EventEvict, is not spilled, and is counted
+// in Stats.Rejections. keys is the expected number of distinct keys written
+// per window and sizes the underlying Bloom filter.
+func WithAdmissionFilter[T comparable, V any](keys int, window time.Duration) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.limits().doorkeeper = newDoorkeeper(keys, window)
+	}
+}
+
+// WithWriteBuffer makes Set, SetWithTTL, SetWithPriority and Delete queue
+// their writes in a lock-free buffer of size slots, applied in order by a
+// background goroutine, for much higher write throughput. In exchange,
+// writes become visible to reads only once applied; Wait applies them
+// immediately, and Clear and StopCleanup apply them first. A writer that
+// finds the buffer full applies the queued writes and its own synchronously.
+// Caches that write through to a backend are not buffered.
+func WithWriteBuffer[T comparable, V any](size int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.buffer = newWriteBuffer[T, V](size)
+	}
+}
+
+// WithTraceRecorder writes a compact binary log of the cache's reads, writes
+// and deletes to w for offline analysis, such as replaying it with the
+// simulate package. Keys are recorded as hashes and sampled by hash, one in
+// sampleRate, so every operation on a sampled key is kept. Writes to w are
+// buffered and flushed every second and by StopCleanup; see LastTraceError.
+func WithTraceRecorder[T comparable, V any](w io.Writer, sampleRate int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.tracer = newTracer(w, sampleRate)
+	}
+}
+
+// WithProfiling makes the cache's work attributable in profiles of the host
+// program. Background goroutines run with the pprof labels cache=name and
+// cache_task=background, or cache_task=sweep while expired entries are
+// removed, so CPU profiles can be filtered by them. Loader calls started by
+// GetOrLoad and FetchMany are listed, while in progress, in the profile named
+// LoadProfileName.
+func WithProfiling[T comparable, V any](name string) Option[T, V] {
+	loadProfile()
+	return func(c *Cache[T, V]) {
+		c.profiler = &profiler{name: name}
+	}
+}
+
+// WithHookPool configures the workers running hooks registered with
+// HookAsync, bounding the memory and goroutines they use. With HookBlock, the
+// default, a full queue makes Set, Delete and the cleanup routine wait for
+// the hooks to catch up; the drop policies keep them from ever waiting.
+func WithHookPool[T comparable, V any](cfg HookPoolConfig) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.hooks.cfg = cfg
+	}
+}
+
+// WithHierarchy indexes string keys as paths of segments separated by sep,
+// such as "a/b/c" with sep "/", so DeleteSubtree does not scan the cache. The
+// index costs a map lookup per segment when a key is added or removed.
+func WithHierarchy[K ~string, V any](sep string) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.paths = &pathIndex[K]{sep: sep, str: func(key K) string { return string(key) }}
+	}
+}
+
+// WithIndex maintains an index of the entries by the attribute fn derives
+// from their values, such as the user ID of a session, so GetByIndex can find
+// them by name without scanning the cache. fn runs on every write and must be
+// cheap and deterministic.
+func WithIndex[T comparable, V any, I comparable](name string, fn func(V) I) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		if c.indexes == nil {
+			c.indexes = make(map[string]*valueIndex[T, V])
+		}
+		c.indexes[name] = newValueIndex[T](func(v V) any { return fn(v) })
+	}
+}
+
+// WithOrderedKeys keeps the keys sorted in an index so that RangeBetween, Min
+// and Max need not scan the cache, for example to query time-bucketed keys.
+// The index costs a logarithmic-time update whenever a key is added or
+// removed.
+func WithOrderedKeys[K Ordered, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.order = newOrderedIndex(cmp.Less[K])
+	}
+}
+
+// WithEncryption encrypts snapshots, the append-only log and spilled values
+// with AES-GCM under key, which must be 16, 24 or 32 bytes long; it panics
+// otherwise. Data written without the same key cannot be read back and makes
+// LoadFrom, replay and recall fail with ErrDecrypt. Replication streams and
+// WithMmapStorage files are not encrypted.
+func WithEncryption[T comparable, V any](key []byte) Option[T, V] {
+	s, err := newSealer(key)
+	if err != nil {
+		panic("cache: WithEncryption: " + err.Error())
+	}
+	return func(c *Cache[T, V]) {
+		c.sealer = s
+	}
+}
+
+// WithSpill moves entries evicted by WithMaxEntries or WithMaxCost into s
+// instead of dropping them, and moves them back into memory when a lookup
+// misses, giving rarely-hot data a larger effective cache. Values are encoded
+// with the cache's codec. Spilled entries keep their deadline, report
+// SourceSnapshot once recalled and do not count towards Len. Writes and
+// deletes drop the spilled copy, Clear empties s and StopCleanup closes it.
+// See NewFileSpill and LastSpillError.
+func WithSpill[T comparable, V any](s SpillStore[T]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.spiller = &spiller[T]{store: s}
+	}
+}
+
+// WithCleanupBudget bounds the work done by each cleanup tick to maxEntries
+// keys or maxDuration, whichever comes first; zero disables a limit. Keys not
+// reached are examined first on the following tick.
+func WithCleanupBudget[T comparable, V any](maxEntries int, maxDuration time.Duration) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.budget = cleanupBudget{maxEntries: maxEntries, maxDuration: maxDuration}
+	}
+}
+
+// WithAdaptiveCleanup lets the cleanup routine pick its own interval between
+// minInterval and maxInterval instead of ticking once per TTL. The interval
+// starts at the TTL, halves while the number of entries each sweep expires
+// grows or a cleanup budget leaves due keys behind, and doubles while sweeps
+// find nothing to remove. A non-positive minInterval defaults to a
+// millisecond; maxInterval below minInterval is raised to it.
+func WithAdaptiveCleanup[T comparable, V any](minInterval, maxInterval time.Duration) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		if minInterval <= 0 {
+			minInterval = time.Millisecond
+		}
+		c.schedule = cleanupSchedule{min: minInterval, max: max(maxInterval, minInterval)}
+	}
+}
+
+// WithExpiryShards splits the expiry index into n shards selected by key
+// hash, each with its own lock and swept by its own cleanup goroutine, which
+// spreads expiry bookkeeping and sweeps across cores on write-heavy
+// workloads. Only the expiry index is split: the store keeps its own
+// sharding, and the eviction policy of WithMaxEntries and WithMaxCost stays
+// shared by all keys.
+func WithExpiryShards[T comparable, V any](n int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		if n < 1 {
+			n = 1
+		}
+		c.expiry = make([]*expiryIndex[T], n)
+	}
+}
+
+// WithStore replaces the default haxmap-backed item store. newStore is called
+// whenever the cache needs a fresh, empty store; see NewShardedMapStore and
+// NewSyncMapStore for stdlib-only implementations.
+func WithStore[T comparable, V any](newStore func() Store[T, V]) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.storeFactory = func(capacity int) Store[T, V] {
+			s := newStore()
+			if g, ok := s.(Grower); ok && capacity > 0 {
+				g.Grow(capacity)
+			}
+			return s
+		}
+	}
+}
+
+// WithInitialCapacity sizes the store for n entries up front instead of the
+// default of 8192, which avoids resize churn for large known working sets and
+// saves memory for tiny caches.
+func WithInitialCapacity[T comparable, V any](n int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.capacity = n
+	}
+}
+
+// WithAccessTracking records the hit count and last access time of every
+// entry, as reported by Inspect. It costs an allocation per write.
+func WithAccessTracking[T comparable, V any]() Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.tracking = true
+	}
+}
+
+// WithAccessSampling enables access tracking but records only about one in
+// rate reads, counting each as rate hits. Hit counts become estimates and
+// last access times lag, in exchange for far fewer shared writes on hot keys.
+func WithAccessSampling[T comparable, V any](rate int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.tracking = true
+		if rate > 1 {
+			c.sampleRate = uint32(rate)
+		}
+	}
+}
+
+// WithTTLAnalysis records the time between reads of one in sampleRate keys so
+// that EstimateHitRatio can predict the effect of a different TTL. Sampling
+// is by key, so every read of a sampled key is recorded.
+func WithTTLAnalysis[T comparable, V any](sampleRate int) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.reuse = newReuseTracker[T](sampleRate)
+	}
+}
+
+// WithReplication serves an ordered stream of the cache's Set, Delete,
+// Expire and Clear operations to standby caches that connect to l and call
+// Follow. Each replica first receives a snapshot, taken while writes are
+// briefly held back, and is disconnected to resynchronize if it falls more
+// than a few thousand operations behind. l is closed by StopCleanup.
+func WithReplication[T comparable, V any](l net.Listener) Option[T, V] {
+	return func(c *Cache[T, V]) {
+		c.stream = &stream[T]{l: l}
+	}
+}
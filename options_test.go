@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKeyCanonicalizer(t *testing.T) {
+	canon := func(key string) string {
+		return strings.ToLower(strings.TrimSpace(key))
+	}
+	cache := NewCache[string, int](time.Minute, WithKeyCanonicalizer[string, int](canon))
+
+	cache.Set(" User:1 ", 42)
+	value, found := cache.Get("user:1")
+	assert.True(t, found, "Expected canonical key to be found")
+	assert.Equal(t, 42, value)
+
+	value, err := cache.GetOrLoad("USER:1", func(string) (int, error) {
+		t.Error("Expected loader not to be called for an equivalent key")
+		return 0, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	cache.Delete("USER:1")
+	_, found = cache.Get("user:1")
+	assert.False(t, found, "Expected equivalent key to be deleted")
+}
+
+func TestCacheInitialCapacity(t *testing.T) {
+	cache := NewCache[int, int](time.Minute, WithInitialCapacity[int, int](16))
+	defer cache.StopCleanup()
+	assert.Equal(t, uintptr(32), cache.items().(*haxmapStore[int, int]).size.Load())
+
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i)
+	}
+	value, found := cache.Get(99)
+	assert.True(t, found, "Expected a small cache to grow past its initial capacity")
+	assert.Equal(t, 99, value)
+}
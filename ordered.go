@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// orderedMaxLevel bounds the height of the skip list; with a branching
+// factor of four it stays efficient well past a billion keys.
+const orderedMaxLevel = 16
+
+// orderedBatch is the number of keys RangeBetween copies out of the index
+// per lock acquisition.
+const orderedBatch = 128
+
+type skipNode[T comparable] struct {
+	key  T
+	next []*skipNode[T]
+}
+
+// orderedIndex keeps the keys of a cache configured with WithOrderedKeys in
+// a skip list, sorted by less.
+type orderedIndex[T comparable] struct {
+	mu    sync.RWMutex
+	less  func(a, b T) bool
+	head  skipNode[T]
+	level int
+}
+
+func newOrderedIndex[T comparable](less func(a, b T) bool) *orderedIndex[T] {
+	return &orderedIndex[T]{less: less, head: skipNode[T]{next: make([]*skipNode[T], orderedMaxLevel)}, level: 1}
+}
+
+// find returns the last node at each level whose key is less than key.
+func (o *orderedIndex[T]) find(key T, update *[orderedMaxLevel]*skipNode[T]) *skipNode[T] {
+	x := &o.head
+	for i := o.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && o.less(x.next[i].key, key) {
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+	}
+	return x
+}
+
+func (o *orderedIndex[T]) add(key T) {
+	var update [orderedMaxLevel]*skipNode[T]
+	x := o.find(key, &update)
+	if n := x.next[0]; n != nil && n.key == key {
+		return
+	}
+	level := 1
+	for level < orderedMaxLevel && rand.Uint32()&3 == 0 {
+		level++
+	}
+	for ; o.level < level; o.level++ {
+		update[o.level] = &o.head
+	}
+	n := &skipNode[T]{key: key, next: make([]*skipNode[T], level)}
+	for i := range level {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+	}
+}
+
+func (o *orderedIndex[T]) remove(key T) {
+	var update [orderedMaxLevel]*skipNode[T]
+	n := o.find(key, &update).next[0]
+	if n == nil || n.key != key {
+		return
+	}
+	for i := range n.next {
+		update[i].next[i] = n.next[i]
+	}
+	for o.level > 1 && o.head.next[o.level-1] == nil {
+		o.level--
+	}
+}
+
+func (o *orderedIndex[T]) reset() {
+	clear(o.head.next)
+	o.level = 1
+}
+
+// ascending returns up to n keys from from, or after it unless inclusive,
+// up to and including to.
+func (o *orderedIndex[T]) ascending(from T, inclusive bool, to T, n int) []T {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	x := o.find(from, nil).next[0]
+	if x != nil && !inclusive && x.key == from {
+		x = x.next[0]
+	}
+	var keys []T
+	for ; x != nil && len(keys) < n && !o.less(to, x.key); x = x.next[0] {
+		keys = append(keys, x.key)
+	}
+	return keys
+}
+
+// after returns the smallest key greater than key.
+func (o *orderedIndex[T]) after(key T) (T, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	x := o.find(key, nil).next[0]
+	if x != nil && x.key == key {
+		x = x.next[0]
+	}
+	if x == nil {
+		var zero T
+		return zero, false
+	}
+	return x.key, true
+}
+
+// first returns the smallest key.
+func (o *orderedIndex[T]) first() (T, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if n := o.head.next[0]; n != nil {
+		return n.key, true
+	}
+	var zero T
+	return zero, false
+}
+
+// last returns the largest key, or the largest key less than before when
+// bounded is set.
+func (o *orderedIndex[T]) last(before T, bounded bool) (T, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	x := &o.head
+	for i := o.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && (!bounded || o.less(x.next[i].key, before)) {
+			x = x.next[i]
+		}
+	}
+	if x == &o.head {
+		var zero T
+		return zero, false
+	}
+	return x.key, true
+}
+
+// indexOrder brings key's place in the ordered index in line with the store;
+// see indexPath for why the store is read under the index lock.
+func (c *Cache[T, V]) indexOrder(key T) {
+	if c.order == nil {
+		return
+	}
+	c.order.mu.Lock()
+	defer c.order.mu.Unlock()
+	if _, ok := c.items().Get(key); ok {
+		c.order.add(key)
+	} else {
+		c.order.remove(key)
+	}
+}
+
+// reindexOrder rebuilds the ordered index after the store was replaced.
+func (c *Cache[T, V]) reindexOrder(items Store[T, V]) {
+	if c.order == nil {
+		return
+	}
+	c.order.mu.Lock()
+	defer c.order.mu.Unlock()
+	c.order.reset()
+	items.ForEach(func(key T, _ CachedItem[V]) bool {
+		c.order.add(key)
+		return true
+	})
+}
+
+// live returns the value of the unexpired entry under key.
+func (c *Cache[T, V]) live(key T, now int64) (V, bool) {
+	item, ok := c.items().Get(key)
+	if !ok || c.expired(key, item, now) {
+		var zero V
+		return zero, false
+	}
+	return c.value(item)
+}
+
+// RangeBetween calls fn in ascending key order for every unexpired entry
+// whose key lies between lo and hi inclusive, until fn returns false. With
+// WithOrderedKeys it walks an index and holds no lock while fn runs, so fn
+// may modify the cache; otherwise the matching keys are collected and sorted
+// first. Entries set or deleted concurrently may or may not be visited.
+func RangeBetween[K Ordered, V any](c *Cache[K, V], lo, hi K, fn func(key K, value V) bool) {
+	now := c.now()
+	if c.order == nil {
+		var keys []K
+		c.items().ForEach(func(key K, _ CachedItem[V]) bool {
+			if lo <= key && key <= hi {
+				keys = append(keys, key)
+			}
+			return true
+		})
+		slices.Sort(keys)
+		for _, key := range keys {
+			if value, ok := c.live(key, now); ok && !fn(key, value) {
+				return
+			}
+		}
+		return
+	}
+	from, inclusive := lo, true
+	for {
+		keys := c.order.ascending(from, inclusive, hi, orderedBatch)
+		for _, key := range keys {
+			if value, ok := c.live(key, now); ok && !fn(key, value) {
+				return
+			}
+		}
+		if len(keys) < orderedBatch {
+			return
+		}
+		from, inclusive = keys[len(keys)-1], false
+	}
+}
+
+// Min returns the unexpired entry with the smallest key, reporting false if
+// there is none. It takes logarithmic time with WithOrderedKeys and scans the
+// cache otherwise.
+func Min[K Ordered, V any](c *Cache[K, V]) (K, V, bool) {
+	return extreme(c, false)
+}
+
+// Max returns the unexpired entry with the largest key; see Min.
+func Max[K Ordered, V any](c *Cache[K, V]) (K, V, bool) {
+	return extreme(c, true)
+}
+
+func extreme[K Ordered, V any](c *Cache[K, V], largest bool) (K, V, bool) {
+	now := c.now()
+	var best K
+	var bestValue V
+	found := false
+	if c.order == nil {
+		c.items().ForEach(func(key K, _ CachedItem[V]) bool {
+			if found && (largest && key <= best || !largest && key >= best) {
+				return true
+			}
+			if value, ok := c.live(key, now); ok {
+				best, bestValue, found = key, value, true
+			}
+			return true
+		})
+		return best, bestValue, found
+	}
+	if !largest {
+		key, ok := c.order.first()
+		for ok {
+			if value, live := c.live(key, now); live {
+				return key, value, true
+			}
+			key, ok = c.order.after(key)
+		}
+		return best, bestValue, false
+	}
+	key, ok := c.order.last(best, false)
+	for ok {
+		if value, live := c.live(key, now); live {
+			return key, value, true
+		}
+		key, ok = c.order.last(key, true)
+	}
+	return best, bestValue, false
+}
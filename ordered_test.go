@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeBetween(t *testing.T) {
+	for _, opts := range [][]Option[int, int]{nil, {WithOrderedKeys[int, int]()}} {
+		c := NewCache[int, int](time.Minute, opts...)
+		for i := range 1000 {
+			c.Set(i*2, i)
+		}
+		var keys []int
+		RangeBetween(c, 10, 20, func(key, value int) bool {
+			assert.Equal(t, key/2, value)
+			keys = append(keys, key)
+			return true
+		})
+		assert.Equal(t, []int{10, 12, 14, 16, 18, 20}, keys)
+
+		keys = keys[:0]
+		RangeBetween(c, 11, 1000, func(key, _ int) bool {
+			keys = append(keys, key)
+			if key%2 == 0 {
+				c.Delete(key + 2)
+			}
+			return len(keys) < 200
+		})
+		assert.Len(t, keys, 200)
+		assert.Equal(t, []int{12, 16, 20}, keys[:3], "Expected deletes made by fn to be seen")
+
+		count := 0
+		RangeBetween(c, 5, 4, func(int, int) bool { count++; return true })
+		assert.Zero(t, count)
+		c.StopCleanup()
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	for _, opts := range [][]Option[string, int]{nil, {WithOrderedKeys[string, int]()}} {
+		c := NewCache[string, int](time.Minute, opts...)
+		_, _, ok := Min(c)
+		assert.False(t, ok)
+		for _, key := range []string{"2024-03", "2024-01", "2024-02", "2024-04"} {
+			c.Set(key, 1)
+		}
+		assert.NoError(t, c.SetWithTTL("2023-12", 1, time.Nanosecond))
+		assert.NoError(t, c.SetWithTTL("2024-05", 1, time.Nanosecond))
+		time.Sleep(20 * time.Millisecond)
+
+		key, _, ok := Min(c)
+		assert.True(t, ok)
+		assert.Equal(t, "2024-01", key, "Expected expired entries to be skipped")
+		key, _, ok = Max(c)
+		assert.True(t, ok)
+		assert.Equal(t, "2024-04", key)
+
+		c.Delete("2024-04")
+		key, _, _ = Max(c)
+		assert.Equal(t, "2024-03", key)
+		c.Clear()
+		_, _, ok = Max(c)
+		assert.False(t, ok)
+		c.StopCleanup()
+	}
+}
+
+func TestOrderedIndexRandom(t *testing.T) {
+	o := newOrderedIndex(func(a, b int) bool { return a < b })
+	want := map[int]bool{}
+	for range 5000 {
+		k := rand.IntN(1000)
+		if rand.IntN(3) == 0 {
+			o.remove(k)
+			delete(want, k)
+		} else {
+			o.add(k)
+			want[k] = true
+		}
+	}
+	var keys []int
+	for k := range want {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	assert.Equal(t, keys, o.ascending(-1, true, 1000, len(keys)+1))
+}
@@ -0,0 +1,162 @@
+// Package peer turns a set of caches on different nodes into a distributed
+// read-through cache in the style of groupcache. Every key is owned by one
+// node, chosen by consistent hashing; a miss on any node is fetched from the
+// owner, which loads and caches the value once for the whole cluster. This
+// suits immutable data: entries are never invalidated across nodes.
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// DefaultBasePath is the URL path prefix under which nodes serve each other.
+const DefaultBasePath = "/_memorycache/"
+
+// Transport fetches an encoded value from a peer. HTTPTransport is the
+// default; other transports such as gRPC can be plugged in.
+type Transport interface {
+	Fetch(ctx context.Context, peer, group, key string) ([]byte, error)
+}
+
+// Group is a named distributed cache on one node. Nodes share a group by
+// using the same name, loader and peer list.
+type Group[V any] struct {
+	name      string
+	self      string
+	cache     *cache.Cache[string, V]
+	load      func(ctx context.Context, key string) (V, error)
+	codec     cache.Codec[V]
+	ring      *Ring
+	transport Transport
+}
+
+// Config configures a Group. Self is this node's address as it appears in
+// the peer list. Codec defaults to cache.GobCodec and Transport to an
+// HTTPTransport using http.DefaultClient.
+type Config[V any] struct {
+	Self      string
+	Codec     cache.Codec[V]
+	Transport Transport
+}
+
+// NewGroup returns a group storing values in c and loading keys this node
+// owns with load. Call SetPeers before use, and serve the group's Handler on
+// DefaultBasePath so other nodes can reach it.
+func NewGroup[V any](name string, c *cache.Cache[string, V], load func(ctx context.Context, key string) (V, error), cfg Config[V]) *Group[V] {
+	if cfg.Codec == nil {
+		cfg.Codec = cache.GobCodec[V]{}
+	}
+	if cfg.Transport == nil {
+		cfg.Transport = &HTTPTransport{}
+	}
+	return &Group[V]{
+		name:      name,
+		self:      cfg.Self,
+		cache:     c,
+		load:      load,
+		codec:     cfg.Codec,
+		ring:      NewRing(),
+		transport: cfg.Transport,
+	}
+}
+
+// SetPeers sets the nodes sharing the group, including this one.
+func (g *Group[V]) SetPeers(peers ...string) {
+	g.ring.Set(peers...)
+}
+
+// Get returns the value for key. Concurrent misses for a key are collapsed
+// into one load or peer fetch, whose ctx is that of the first caller. If the
+// owning peer cannot be reached the key is loaded locally instead.
+func (g *Group[V]) Get(ctx context.Context, key string) (V, error) {
+	return g.cache.GetOrLoad(key, func(key string) (V, error) {
+		owner := g.ring.Owner(key)
+		if owner == "" || owner == g.self {
+			return g.load(ctx, key)
+		}
+		data, err := g.transport.Fetch(ctx, owner, g.name, key)
+		if errors.Is(err, cache.ErrNotFound) {
+			var zero V
+			return zero, err
+		}
+		if err != nil {
+			return g.load(ctx, key)
+		}
+		return g.codec.Decode(data)
+	})
+}
+
+// Handler serves the group's keys to other nodes, loading them locally on a
+// miss. It answers GET {DefaultBasePath}{group}/{key} and should be mounted at
+// DefaultBasePath.
+func (g *Group[V]) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group, key, ok := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), DefaultBasePath), "/")
+		if !ok || group != url.PathEscape(g.name) {
+			http.NotFound(w, r)
+			return
+		}
+		key, err := url.PathUnescape(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		value, err := g.cache.GetOrLoad(key, func(key string) (V, error) {
+			return g.load(r.Context(), key)
+		})
+		if errors.Is(err, cache.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data, err := g.codec.Encode(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+	})
+}
+
+// HTTPTransport fetches values from peers' Handlers. Peers are base URLs
+// such as "http://10.0.0.1:8080".
+type HTTPTransport struct {
+	Client *http.Client
+}
+
+func (t *HTTPTransport) Fetch(ctx context.Context, peer, group, key string) ([]byte, error) {
+	u := strings.TrimSuffix(peer, "/") + DefaultBasePath + url.PathEscape(group) + "/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, cache.ErrNotFound
+	default:
+		return nil, fmt.Errorf("peer: %s returned %s", peer, resp.Status)
+	}
+}
@@ -0,0 +1,74 @@
+package peer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	var loads sync.Map
+	load := func(_ context.Context, key string) (string, error) {
+		n, _ := loads.LoadOrStore(key, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+		if key == "missing" {
+			return "", cache.ErrNotFound
+		}
+		return "value:" + key, nil
+	}
+
+	var groups []*Group[string]
+	var peers []string
+	for i := 0; i < 3; i++ {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+		c := cache.NewCache[string, string](time.Minute)
+		defer c.StopCleanup()
+		g := NewGroup("users", c, load, Config[string]{Self: srv.URL})
+		mux.Handle(DefaultBasePath, g.Handler())
+		groups = append(groups, g)
+		peers = append(peers, srv.URL)
+	}
+	for _, g := range groups {
+		g.SetPeers(peers...)
+	}
+
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h", "a b/c"}
+	for _, g := range groups {
+		for _, key := range keys {
+			value, err := g.Get(context.Background(), key)
+			assert.NoError(t, err)
+			assert.Equal(t, "value:"+key, value)
+		}
+	}
+	for _, key := range keys {
+		n, _ := loads.Load(key)
+		assert.Equal(t, int32(1), n.(*atomic.Int32).Load(), "Expected %q to be loaded once across the cluster", key)
+	}
+
+	for _, g := range groups {
+		_, err := g.Get(context.Background(), "missing")
+		assert.ErrorIs(t, err, cache.ErrNotFound)
+	}
+}
+
+func TestGroupPeerDown(t *testing.T) {
+	c := cache.NewCache[string, string](time.Minute)
+	defer c.StopCleanup()
+	g := NewGroup("users", c, func(_ context.Context, key string) (string, error) {
+		return "local:" + key, nil
+	}, Config[string]{Self: "http://self"})
+	g.SetPeers("http://127.0.0.1:1")
+
+	value, err := g.Get(context.Background(), "k")
+	assert.NoError(t, err)
+	assert.Equal(t, "local:k", value, "Expected an unreachable owner to fall back to a local load")
+}
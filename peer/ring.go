@@ -0,0 +1,61 @@
+package peer
+
+import (
+	"hash/crc32"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// defaultReplicas is the number of points each peer gets on the ring, which
+// evens out the share of keys each peer owns.
+const defaultReplicas = 64
+
+// Ring assigns keys to peers by consistent hashing, so adding or removing a
+// peer only moves the keys that peer owns. Every node must build its ring
+// from the same peer list to agree on ownership.
+type Ring struct {
+	mu       sync.RWMutex
+	replicas int
+	points   []uint32
+	owners   map[uint32]string
+}
+
+// NewRing returns a Ring over peers.
+func NewRing(peers ...string) *Ring {
+	r := &Ring{replicas: defaultReplicas}
+	r.Set(peers...)
+	return r
+}
+
+// Set replaces the peers on the ring.
+func (r *Ring) Set(peers ...string) {
+	points := make([]uint32, 0, len(peers)*r.replicas)
+	owners := make(map[uint32]string, len(peers)*r.replicas)
+	for _, p := range peers {
+		for i := 0; i < r.replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + p))
+			points = append(points, h)
+			owners[h] = p
+		}
+	}
+	slices.Sort(points)
+	r.mu.Lock()
+	r.points, r.owners = points, owners
+	r.mu.Unlock()
+}
+
+// Owner returns the peer responsible for key, or "" if the ring is empty.
+func (r *Ring) Owner(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i, _ := slices.BinarySearch(r.points, h)
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
@@ -0,0 +1,35 @@
+package peer
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	assert.Equal(t, "", NewRing().Owner("k"))
+
+	r := NewRing("a", "b", "c")
+	counts := map[string]int{}
+	before := map[string]string{}
+	for i := 0; i < 3000; i++ {
+		key := strconv.Itoa(i)
+		owner := r.Owner(key)
+		counts[owner]++
+		before[key] = owner
+	}
+	for _, p := range []string{"a", "b", "c"} {
+		assert.Greater(t, counts[p], 500, "Expected keys to be spread over every peer")
+	}
+
+	r.Set("a", "b", "c", "d")
+	moved := 0
+	for key, owner := range before {
+		if now := r.Owner(key); now != owner {
+			assert.Equal(t, "d", now, "Expected keys to move only to the new peer")
+			moved++
+		}
+	}
+	assert.Less(t, moved, 1500)
+}
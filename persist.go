@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+}
+
+type snapshotEntry[T comparable] struct {
+	Key   T
+	Value []byte
+	TTL   time.Duration
+}
+
+// SaveTo writes every non-expired entry and its remaining TTL to w. Keys are
+// encoded with encoding/gob and values with the configured Codec. With
+// WithEncryption the output is encrypted.
+func (c *Cache[T, V]) SaveTo(w io.Writer) error {
+	w = c.sealed(w)
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion}); err != nil {
+		return err
+	}
+	codec := c.valueCodec()
+	now := c.now()
+	var err error
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		remaining := time.Duration(item.expires - now)
+		if remaining <= 0 {
+			return true
+		}
+		value, ok := c.value(item)
+		if !ok {
+			return true
+		}
+		var data []byte
+		if data, err = codec.Encode(value); err != nil {
+			return false
+		}
+		err = enc.Encode(snapshotEntry[T]{Key: key, Value: data, TTL: remaining})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return endSealed(w)
+}
+
+// LoadFrom reads entries written by SaveTo and adds them to the cache with
+// their remaining TTL. Entries that expired in the meantime are skipped.
+func (c *Cache[T, V]) LoadFrom(r io.Reader) error {
+	dec := gob.NewDecoder(c.unsealed(r))
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != snapshotVersion {
+		return errors.New("cache: unsupported snapshot version")
+	}
+	codec := c.valueCodec()
+	for {
+		var e snapshotEntry[T]
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		value, err := codec.Decode(e.Value)
+		if err != nil {
+			return err
+		}
+		c.restore(e.Key, value, e.TTL)
+	}
+}
+
+// restore inserts a persisted entry so that it expires after remaining.
+func (c *Cache[T, V]) restore(key T, value V, remaining time.Duration) {
+	if remaining <= 0 {
+		return
+	}
+	item := c.newItem(value, SourceSnapshot)
+	item.expires, item.explicit = c.now()+int64(remaining), true
+	c.store(c.canonical(key), item, nil)
+}
+
+// SaveFile writes a snapshot to path. The snapshot is written to a temporary
+// file in the same directory and renamed over path, so readers never observe
+// a partial file.
+func (c *Cache[T, V]) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := c.SaveTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFile restores a snapshot written by SaveFile.
+func (c *Cache[T, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.LoadFrom(f)
+}
+
+type snapshotter struct {
+	path     string
+	interval time.Duration
+	lastErr  atomic.Pointer[error]
+	// failing reports whether the latest attempt failed.
+	failing atomic.Bool
+}
+
+func (s *snapshotter) record(err error) {
+	s.failing.Store(err != nil)
+	if err != nil {
+		s.lastErr.Store(&err)
+	}
+}
+
+func (c *Cache[T, V]) runSnapshots() {
+	ticker := c.newTicker(c.snapshot.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			c.snapshot.record(c.SaveFile(c.snapshot.path))
+		case <-c.stopCleanup:
+			c.snapshot.record(c.SaveFile(c.snapshot.path))
+			return
+		}
+	}
+}
+
+// LastSnapshotError returns the most recent error from periodic snapshotting
+// or the initial snapshot load, or nil.
+func (c *Cache[T, V]) LastSnapshotError() error {
+	if c.snapshot == nil {
+		return nil
+	}
+	if err := c.snapshot.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheSaveToLoadFrom(t *testing.T) {
+	src := NewCache[string, int](time.Minute)
+	src.Set("a", 1)
+	src.Set("b", 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, src.SaveTo(&buf))
+
+	dst := NewCache[string, int](time.Minute, WithProvenance[string, int]())
+	assert.NoError(t, dst.LoadFrom(&buf))
+
+	entry, found := dst.GetEntry("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, entry.Value)
+	assert.Equal(t, SourceSnapshot, entry.Source)
+	assert.True(t, time.Until(entry.ExpiresAt) <= time.Minute)
+
+	value, found := dst.Get("b")
+	assert.True(t, found)
+	assert.Equal(t, 2, value)
+}
+
+func TestCacheSaveSkipsExpired(t *testing.T) {
+	src := NewCache[string, int](time.Minute)
+	src.StopCleanup()
+	src.items().Set("old", CachedItem[int]{Value: 1, expires: nanotime() - int64(time.Hour)})
+	src.Set("new", 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, src.SaveTo(&buf))
+
+	dst := NewCache[string, int](time.Minute)
+	assert.NoError(t, dst.LoadFrom(&buf))
+	_, found := dst.Get("old")
+	assert.False(t, found, "Expected expired entry not to be persisted")
+	_, found = dst.Get("new")
+	assert.True(t, found)
+}
+
+func TestCacheSaveFileLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	src := NewCache[int, string](time.Minute)
+	src.Set(1, "test1")
+	assert.NoError(t, src.SaveFile(path))
+
+	dst := NewCache[int, string](time.Minute)
+	assert.NoError(t, dst.LoadFile(path))
+	value, found := dst.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "test1", value)
+}
+
+func TestCacheWithSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	src := NewCache[int, string](time.Minute, WithSnapshot[int, string](path, 10*time.Millisecond))
+	src.Set(1, "test1")
+	time.Sleep(50 * time.Millisecond)
+	src.StopCleanup()
+	assert.NoError(t, src.LastSnapshotError())
+
+	dst := NewCache[int, string](time.Minute, WithSnapshot[int, string](path, time.Hour))
+	defer dst.StopCleanup()
+	value, found := dst.Get(1)
+	assert.True(t, found, "Expected snapshot to be loaded on construction")
+	assert.Equal(t, "test1", value)
+	assert.NoError(t, dst.LastSnapshotError())
+}
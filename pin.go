@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type pins[T comparable] struct {
+	mu   sync.RWMutex
+	keys map[T]struct{}
+	// n mirrors len(keys) so that caches without pins skip the lock.
+	n atomic.Int32
+}
+
+// Pin exempts the entry under key from eviction by WithMaxEntries and, unless
+// WithPinnedExpiry is set, from expiry, so that entries such as configuration
+// or signing keys stay resident under memory pressure. The pin lasts until
+// Unpin, or until the entry is removed by Delete, Clear or, with
+// WithPinnedExpiry, its TTL. Pinned entries do not count against the entry
+// limit. Pin reports whether key was present.
+func (c *Cache[T, V]) Pin(key T) bool {
+	key = c.canonical(key)
+	if _, ok := c.items().Get(key); !ok {
+		return false
+	}
+	c.pins.mu.Lock()
+	if c.pins.keys == nil {
+		c.pins.keys = make(map[T]struct{})
+	}
+	c.pins.keys[key] = struct{}{}
+	c.pins.n.Store(int32(len(c.pins.keys)))
+	c.pins.mu.Unlock()
+	if _, ok := c.items().Get(key); !ok {
+		// Deleted while we were pinning it.
+		c.unpinRemoved(key)
+		return false
+	}
+	if c.eviction != nil {
+		c.eviction.remove(key)
+	}
+	return true
+}
+
+// Unpin makes the entry under key subject to eviction and expiry again. An
+// entry whose TTL ran out while it was pinned is removed by the next cleanup.
+// Unpin reports whether key was pinned.
+func (c *Cache[T, V]) Unpin(key T) bool {
+	key = c.canonical(key)
+	c.pins.mu.Lock()
+	_, ok := c.pins.keys[key]
+	delete(c.pins.keys, key)
+	c.pins.n.Store(int32(len(c.pins.keys)))
+	c.pins.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if item, ok := c.items().Get(key); ok {
+		if !c.pinnedExpiry {
+			// The cleanup routine drops pinned keys from the expiry index.
+			c.expiryFor(key).add(key, c.sweepAt(item))
+		}
+		c.admit(key, item)
+	}
+	return true
+}
+
+// Pinned reports whether key is pinned.
+func (c *Cache[T, V]) Pinned(key T) bool {
+	return c.isPinned(c.canonical(key))
+}
+
+func (c *Cache[T, V]) isPinned(key T) bool {
+	if c.pins.n.Load() == 0 {
+		return false
+	}
+	c.pins.mu.RLock()
+	_, ok := c.pins.keys[key]
+	c.pins.mu.RUnlock()
+	return ok
+}
+
+// immortal reports whether key is exempt from expiry.
+func (c *Cache[T, V]) immortal(key T) bool {
+	return !c.pinnedExpiry && c.isPinned(key)
+}
+
+func (c *Cache[T, V]) unpinRemoved(key T) {
+	if c.pins.n.Load() == 0 {
+		return
+	}
+	c.pins.mu.Lock()
+	delete(c.pins.keys, key)
+	c.pins.n.Store(int32(len(c.pins.keys)))
+	c.pins.mu.Unlock()
+}
+
+// prunePins drops the pins of keys missing from items.
+func (c *Cache[T, V]) prunePins(items Store[T, V]) {
+	if c.pins.n.Load() == 0 {
+		return
+	}
+	c.pins.mu.Lock()
+	for key := range c.pins.keys {
+		if _, ok := items.Get(key); !ok {
+			delete(c.pins.keys, key)
+		}
+	}
+	c.pins.n.Store(int32(len(c.pins.keys)))
+	c.pins.mu.Unlock()
+}
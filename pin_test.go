@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePin(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithMaxEntries[string, int](1))
+	defer cache.StopCleanup()
+
+	assert.False(t, cache.Pin("config"), "Expected missing keys not to be pinned")
+	cache.Set("config", 1)
+	assert.True(t, cache.Pin("config"))
+	assert.True(t, cache.Pinned("config"))
+
+	cache.Set("a", 2)
+	cache.Set("b", 3)
+	_, found := cache.Get("config")
+	assert.True(t, found, "Expected pinned entries to survive capacity pressure")
+	_, found = cache.Get("a")
+	assert.False(t, found)
+
+	assert.True(t, cache.Unpin("config"))
+	assert.False(t, cache.Unpin("config"))
+	_, found = cache.Get("config")
+	assert.True(t, found)
+	_, found = cache.Get("b")
+	assert.False(t, found, "Expected Unpin to count the entry against the limit again")
+
+	cache.Pin("config")
+	cache.Delete("config")
+	assert.False(t, cache.Pinned("config"), "Expected Delete to drop the pin")
+}
+
+func TestCachePinExpiry(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithExactTime[string, int]())
+	defer cache.StopCleanup()
+
+	cache.SetWithTTL("k", 1, 10*time.Millisecond)
+	cache.Pin("k")
+	time.Sleep(20 * time.Millisecond)
+	cache.expire(nanotime() + int64(2*time.Second))
+	value, found := cache.Get("k")
+	assert.True(t, found, "Expected pinned entries not to expire")
+	assert.Equal(t, 1, value)
+
+	cache.Unpin("k")
+	_, found = cache.Get("k")
+	assert.False(t, found)
+	cache.expire(nanotime() + int64(4*time.Second))
+	_, found = cache.items().Get("k")
+	assert.False(t, found, "Expected the cleanup to remove the entry once unpinned")
+
+	pinned := NewCache[string, int](time.Minute, WithExactTime[string, int](), WithPinnedExpiry[string, int]())
+	defer pinned.StopCleanup()
+	pinned.SetWithTTL("k", 1, 10*time.Millisecond)
+	pinned.Pin("k")
+	time.Sleep(20 * time.Millisecond)
+	_, found = pinned.Get("k")
+	assert.False(t, found, "Expected WithPinnedExpiry to let pinned entries expire")
+}
@@ -0,0 +1,21 @@
+package cache
+
+// Priority ranks entries of a cache bounded by WithMaxEntries: every entry of
+// a lower priority is evicted before any entry of a higher one, and the
+// eviction policy only decides among entries of the same priority. Any value
+// may be used; the constants name the common levels.
+type Priority int8
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// SetWithPriority is TrySet with an eviction priority for the entry, which
+// lets one cache hold both cheap and expensive to rebuild data. The priority
+// lasts until the key is set again, and only affects this cache: it is not
+// recorded in the append-only log nor sent to replicas.
+func (c *Cache[T, V]) SetWithPriority(key T, value V, priority Priority) error {
+	return c.trySetEntry(key, value, 0, priority)
+}
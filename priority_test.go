@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheSetWithPriority(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithMaxEntries[string, int](3))
+	defer cache.StopCleanup()
+
+	assert.NoError(t, cache.SetWithPriority("expensive", 1, PriorityHigh))
+	cache.Set("a", 2)
+	assert.NoError(t, cache.SetWithPriority("cheap", 3, PriorityLow))
+	cache.Get("cheap")
+	cache.Set("b", 4)
+
+	_, found := cache.Get("cheap")
+	assert.False(t, found, "Expected low priority entries to be evicted first")
+
+	cache.Set("c", 5)
+	_, found = cache.Get("expensive")
+	assert.True(t, found, "Expected high priority entries to outlive normal ones")
+	_, found = cache.Get("a")
+	assert.False(t, found)
+
+	cache.Set("expensive", 6)
+	cache.Get("b")
+	cache.Get("c")
+	cache.Set("d", 7)
+	_, found = cache.Get("expensive")
+	assert.False(t, found, "Expected Set to reset the priority")
+}
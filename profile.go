@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync"
+)
+
+// LoadProfileName is the name of the pprof profile that lists loader calls
+// in progress in caches configured with WithProfiling, with the stack that
+// started each one. Like goroutine, it is served by net/http/pprof at
+// /debug/pprof/<name>.
+const LoadProfileName = "github.com/NikoMalik/MemoryCache.loads"
+
+// loadProfile is registered by the first WithProfiling option.
+var loadProfile = sync.OnceValue(func() *pprof.Profile {
+	return pprof.NewProfile(LoadProfileName)
+})
+
+// loadToken identifies one loader call in loadProfile. It is not empty so
+// that every token has its own address.
+type loadToken struct{ _ byte }
+
+// profiler attributes the cache's work in profiles; see WithProfiling.
+type profiler struct {
+	name string
+}
+
+// labelled runs fn with the cache's profiler labels plus cache_task set to
+// task, so CPU samples taken meanwhile are attributed to the cache.
+func (c *Cache[T, V]) labelled(task string, fn func()) {
+	if c.profiler == nil {
+		fn()
+		return
+	}
+	labels := pprof.Labels("cache", c.profiler.name, "cache_task", task)
+	pprof.Do(context.Background(), labels, func(context.Context) { fn() })
+}
+
+// profiled runs the loader call fn, listing it in the load profile while it
+// is in progress. Unlike background work, loader calls are not labelled: they
+// run on the caller's goroutine, whose own labels would be replaced.
+func profiled[R any](p *profiler, fn func() (R, error)) (R, error) {
+	if p == nil {
+		return fn()
+	}
+	token := new(loadToken)
+	loadProfile().Add(token, 1)
+	defer loadProfile().Remove(token)
+	return fn()
+}
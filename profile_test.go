@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilingListsLoads(t *testing.T) {
+	c := NewCache[string, int](time.Minute, WithProfiling[string, int]("loads"))
+	defer c.StopCleanup()
+	p := pprof.Lookup(LoadProfileName)
+	if !assert.NotNil(t, p) {
+		return
+	}
+	before := p.Count()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.GetOrLoad("k", func(string) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+	assert.Equal(t, before+1, p.Count())
+	var buf bytes.Buffer
+	assert.NoError(t, p.WriteTo(&buf, 1))
+	assert.Contains(t, buf.String(), "GetOrLoad")
+	close(release)
+	<-done
+	assert.Equal(t, before, p.Count())
+
+	_, err := c.FetchMany([]string{"a"}, func(missing []string) (map[string]int, error) {
+		assert.Equal(t, before+1, p.Count())
+		return map[string]int{"a": 1}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, before, p.Count())
+}
+
+func TestProfilingLabelsBackgroundWork(t *testing.T) {
+	c := NewCache[string, int](10*time.Millisecond, WithProfiling[string, int]("janitor-test"))
+	defer c.StopCleanup()
+	c.Set("k", 1)
+
+	assert.Eventually(t, func() bool {
+		var buf bytes.Buffer
+		pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		return bytes.Contains(buf.Bytes(), []byte(`"cache":"janitor-test"`))
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return c.Len() == 0 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Source records how an entry got into the cache.
+type Source uint8
+
+const (
+	SourceUnknown Source = iota
+	SourceSet
+	SourceLoader
+	SourceSnapshot
+	SourceRemote
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceSet:
+		return "set"
+	case SourceLoader:
+		return "loader"
+	case SourceSnapshot:
+		return "snapshot"
+	case SourceRemote:
+		return "remote"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Source) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Source) UnmarshalText(text []byte) error {
+	for src := SourceUnknown; src <= SourceRemote; src++ {
+		if src.String() == string(text) {
+			*s = src
+			return nil
+		}
+	}
+	return fmt.Errorf("cache: unknown source %q", text)
+}
+
+type Entry[V any] struct {
+	Value       V
+	CreatedTime time.Time
+	ExpiresAt   time.Time
+	Source      Source
+}
+
+func (c *Cache[T, V]) source(s Source) Source {
+	if !c.provenance {
+		return SourceUnknown
+	}
+	return s
+}
+
+// GetEntry returns the value stored under key together with its metadata.
+func (c *Cache[T, V]) GetEntry(key T) (Entry[V], bool) {
+	key = c.canonical(key)
+	item, value, ok := c.lookup(key)
+	if !ok {
+		return Entry[V]{}, false
+	}
+	return Entry[V]{
+		Value:       value,
+		CreatedTime: wallTime(item.created),
+		ExpiresAt:   item.ExpiresAt(),
+		Source:      item.source,
+	}, true
+}
+
+type entryMeta[T any] struct {
+	Key         T         `json:"key"`
+	CreatedTime time.Time `json:"created"`
+	ExpiresAt   time.Time `json:"expires"`
+	Source      Source    `json:"source"`
+}
+
+// DumpMetadata writes one JSON object per entry to w describing its key,
+// creation and expiry times and source. Values are not included.
+func (c *Cache[T, V]) DumpMetadata(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	var err error
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		err = enc.Encode(entryMeta[T]{
+			Key:         key,
+			CreatedTime: wallTime(item.created),
+			ExpiresAt:   item.ExpiresAt(),
+			Source:      item.source,
+		})
+		return err == nil
+	})
+	return err
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheProvenance(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithProvenance[int, string]())
+
+	cache.Set(1, "set")
+	_, err := cache.GetOrLoad(2, func(int) (string, error) {
+		return "loaded", nil
+	})
+	assert.NoError(t, err)
+
+	entry, found := cache.GetEntry(1)
+	assert.True(t, found)
+	assert.Equal(t, "set", entry.Value)
+	assert.Equal(t, SourceSet, entry.Source)
+	assert.Equal(t, time.Minute, entry.ExpiresAt.Sub(entry.CreatedTime))
+
+	entry, found = cache.GetEntry(2)
+	assert.True(t, found)
+	assert.Equal(t, SourceLoader, entry.Source)
+
+	_, found = cache.GetEntry(3)
+	assert.False(t, found)
+}
+
+func TestCacheProvenanceDisabled(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+
+	cache.Set(1, "set")
+	entry, found := cache.GetEntry(1)
+	assert.True(t, found)
+	assert.Equal(t, SourceUnknown, entry.Source)
+}
+
+func TestCacheDumpMetadata(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithProvenance[int, string]())
+	cache.Set(1, "set")
+
+	var buf bytes.Buffer
+	assert.NoError(t, cache.DumpMetadata(&buf))
+
+	var meta map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &meta))
+	assert.Equal(t, float64(1), meta["key"])
+	assert.Equal(t, "set", meta["source"])
+}
@@ -0,0 +1,136 @@
+// Package ratelimit provides per-key rate limiters whose state lives in a
+// cache, so keys that stop sending requests are forgotten automatically.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+type window struct {
+	start time.Time
+	n     atomic.Int64
+}
+
+// FixedWindow allows up to limit events per key in consecutive windows of
+// fixed length. It is cheap, but allows up to twice the limit in bursts that
+// straddle a window boundary.
+type FixedWindow[T comparable] struct {
+	limit  int64
+	length time.Duration
+	cache  *cache.Cache[T, *window]
+	now    func() time.Time
+}
+
+func NewFixedWindow[T cache.Hashable](limit int, length time.Duration) *FixedWindow[T] {
+	return &FixedWindow[T]{
+		limit:  int64(limit),
+		length: length,
+		cache:  cache.NewCache[T, *window](length),
+		now:    time.Now,
+	}
+}
+
+// Close stops the cache's cleanup routine.
+func (l *FixedWindow[T]) Close() {
+	l.cache.StopCleanup()
+}
+
+func (l *FixedWindow[T]) Allow(key T) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n events may happen for key now, counting them if
+// so.
+func (l *FixedWindow[T]) AllowN(key T, n int) bool {
+	now := l.now()
+	for {
+		w, version, ok := l.cache.GetVersioned(key)
+		if !ok || now.Sub(w.start) >= l.length {
+			w = &window{start: now.Truncate(l.length)}
+			if !l.cache.SetIfVersion(key, w, version) {
+				continue
+			}
+		}
+		if w.n.Add(int64(n)) > l.limit {
+			w.n.Add(-int64(n))
+			return false
+		}
+		return true
+	}
+}
+
+type bucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	extended time.Time
+}
+
+// TokenBucket allows events per key at a steady rate with bursts of up to
+// burst events.
+type TokenBucket[T comparable] struct {
+	rate  float64
+	burst float64
+	// fill is how long an empty bucket takes to fill up. A bucket idle for
+	// longer is indistinguishable from a new one, so it may be dropped.
+	fill  time.Duration
+	cache *cache.Cache[T, *bucket]
+	now   func() time.Time
+}
+
+// NewTokenBucket returns a limiter refilling each key's bucket at rate
+// tokens per second up to burst tokens.
+func NewTokenBucket[T cache.Hashable](rate float64, burst int) *TokenBucket[T] {
+	fill := time.Duration(math.Ceil(float64(burst) / rate * float64(time.Second)))
+	return &TokenBucket[T]{
+		rate:  rate,
+		burst: float64(burst),
+		fill:  fill,
+		cache: cache.NewCache[T, *bucket](2 * fill),
+		now:   time.Now,
+	}
+}
+
+// Close stops the cache's cleanup routine.
+func (l *TokenBucket[T]) Close() {
+	l.cache.StopCleanup()
+}
+
+func (l *TokenBucket[T]) Allow(key T) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n tokens are available for key now, taking them if
+// so.
+func (l *TokenBucket[T]) AllowN(key T, n int) bool {
+	now := l.now()
+	b, ok := l.cache.Get(key)
+	if !ok {
+		fresh := &bucket{tokens: l.burst, last: now, extended: now}
+		if l.cache.SetIfVersion(key, fresh, 0) {
+			b = fresh
+		} else if b, ok = l.cache.Get(key); !ok {
+			b = fresh
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if now.Sub(b.extended) > l.fill {
+		// Keep the bucket for at least another fill period so it is not
+		// forgotten while it still holds a debt.
+		b.extended = now
+		l.cache.Expire(key, 2*l.fill)
+	}
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNow struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeNow) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeNow) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func TestFixedWindow(t *testing.T) {
+	clock := &fakeNow{now: time.Now().Truncate(time.Minute)}
+	l := NewFixedWindow[string](3, time.Minute)
+	defer l.Close()
+	l.now = clock.Now
+
+	for range 3 {
+		assert.True(t, l.Allow("ip1"))
+	}
+	assert.False(t, l.Allow("ip1"))
+	assert.True(t, l.Allow("ip2"), "Expected keys to be limited independently")
+	assert.False(t, l.AllowN("ip2", 3), "Expected a rejected batch not to be counted")
+	assert.True(t, l.AllowN("ip2", 2))
+
+	clock.Advance(time.Minute)
+	assert.True(t, l.Allow("ip1"), "Expected a new window to reset the count")
+}
+
+func TestFixedWindowConcurrent(t *testing.T) {
+	l := NewFixedWindow[int](100, time.Hour)
+	defer l.Close()
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if l.Allow(1) {
+					allowed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(100), allowed.Load())
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeNow{now: time.Now()}
+	l := NewTokenBucket[string](10, 5)
+	defer l.Close()
+	l.now = clock.Now
+
+	for range 5 {
+		assert.True(t, l.Allow("user"))
+	}
+	assert.False(t, l.Allow("user"), "Expected the burst to be exhausted")
+
+	clock.Advance(250 * time.Millisecond)
+	assert.True(t, l.AllowN("user", 2), "Expected tokens to refill at the rate")
+	assert.False(t, l.Allow("user"))
+
+	clock.Advance(time.Hour)
+	assert.True(t, l.AllowN("user", 5))
+	assert.False(t, l.Allow("user"), "Expected refills to be capped at the burst")
+}
@@ -0,0 +1,35 @@
+package cache
+
+// ReadOnlyCache is a view of a cache that cannot modify it.
+type ReadOnlyCache[T comparable, V any] interface {
+	Getter[T, V]
+	Len() int
+	Range(fn func(key T, value V) bool)
+}
+
+// frozen wraps a cache so that the view cannot be type-asserted back to the
+// mutable *Cache.
+type frozen[T comparable, V any] struct {
+	c *Cache[T, V]
+}
+
+// Freeze returns a read-only view of c, safe to hand to code that must not
+// modify or clear the cache. The view is live: it sees later changes made
+// through c.
+func (c *Cache[T, V]) Freeze() ReadOnlyCache[T, V] {
+	return frozen[T, V]{c}
+}
+
+func (f frozen[T, V]) Get(key T) (V, bool) {
+	return f.c.Get(key)
+}
+
+func (f frozen[T, V]) Len() int {
+	return f.c.Len()
+}
+
+func (f frozen[T, V]) Range(fn func(key T, value V) bool) {
+	f.c.Range(fn)
+}
+
+var _ ReadOnlyCache[int, int] = (*Cache[int, int])(nil)
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFreeze(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("a", 1)
+
+	view := cache.Freeze()
+	_, mutable := view.(interface{ Clear() })
+	assert.False(t, mutable, "Expected the view not to expose mutation")
+	_, unwrapped := view.(*Cache[string, int])
+	assert.False(t, unwrapped)
+
+	cache.Set("b", 2)
+	value, found := view.Get("b")
+	assert.True(t, found, "Expected the view to be live")
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 2, view.Len())
+
+	sum := 0
+	view.Range(func(_ string, v int) bool {
+		sum += v
+		return true
+	})
+	assert.Equal(t, 3, sum)
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+)
+
+// Bus carries invalidation messages between the instances of a service. A
+// message published by one instance must be delivered to the handlers of
+// every subscribed instance, including, harmlessly, the publisher itself.
+type Bus interface {
+	Publish(ctx context.Context, msg []byte) error
+	// Subscribe calls handle for every message published on the bus until
+	// cancel is called.
+	Subscribe(handle func(msg []byte)) (cancel func(), err error)
+}
+
+// invalidation is the message a Replicator exchanges. Op uses the append-only
+// log op codes.
+type invalidation[T comparable] struct {
+	Origin string
+	Op     uint8
+	Key    T
+}
+
+// Replicator keeps a cache coherent with its replicas on other instances:
+// every Set, Delete, Expire and Clear made through the cache's API is
+// published on a Bus as an invalidation, and invalidations received from
+// other instances evict the key locally, or clear the cache. Values are
+// never sent; replicas reload evicted keys on their next miss.
+//
+// Remote invalidations are reported to Subscribe listeners but are not
+// written to the append-only log, propagated to a backend or published
+// again.
+type Replicator[T comparable, V any] struct {
+	c       *Cache[T, V]
+	bus     Bus
+	id      string
+	queue   chan invalidation[T]
+	stop    chan struct{}
+	done    chan struct{}
+	cancel  func()
+	once    sync.Once
+	lastErr atomic.Pointer[error]
+}
+
+// replicatorQueue is the number of invalidations buffered for publishing;
+// writers block once it is full.
+const replicatorQueue = 1024
+
+// NewReplicator subscribes c to bus and starts publishing its changes. Close
+// the Replicator to detach it.
+func NewReplicator[T comparable, V any](c *Cache[T, V], bus Bus) (*Replicator[T, V], error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	r := &Replicator[T, V]{
+		c:     c,
+		bus:   bus,
+		id:    hex.EncodeToString(id[:]),
+		queue: make(chan invalidation[T], replicatorQueue),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	cancel, err := bus.Subscribe(r.receive)
+	if err != nil {
+		return nil, err
+	}
+	r.cancel = cancel
+	go r.run()
+	c.replicator.Store(r)
+	return r, nil
+}
+
+// replicate hands a local change to the attached Replicator, if any.
+func (c *Cache[T, V]) replicate(op uint8, key T) {
+	if r := c.replicator.Load(); r != nil {
+		r.publish(op, key)
+	}
+}
+
+func (r *Replicator[T, V]) publish(op uint8, key T) {
+	select {
+	case r.queue <- invalidation[T]{Origin: r.id, Op: op, Key: key}:
+	case <-r.stop:
+	}
+}
+
+func (r *Replicator[T, V]) run() {
+	defer close(r.done)
+	for {
+		select {
+		case msg := <-r.queue:
+			r.send(msg)
+		case <-r.stop:
+			// Publish what was queued before Close.
+			for {
+				select {
+				case msg := <-r.queue:
+					r.send(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Replicator[T, V]) send(msg invalidation[T]) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(msg); err != nil {
+		r.record(err)
+		return
+	}
+	r.record(r.bus.Publish(context.Background(), buf.Bytes()))
+}
+
+func (r *Replicator[T, V]) receive(data []byte) {
+	var msg invalidation[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&msg); err != nil {
+		r.record(err)
+		return
+	}
+	if msg.Origin == r.id {
+		return
+	}
+	switch msg.Op {
+	case aofSet, aofDelete:
+		r.c.remove(r.c.canonical(msg.Key), nil)
+	case aofClear:
+		r.c.clear()
+	}
+}
+
+func (r *Replicator[T, V]) record(err error) {
+	if err != nil {
+		r.lastErr.Store(&err)
+	}
+}
+
+// LastError returns the most recent error publishing or decoding an
+// invalidation, or nil.
+func (r *Replicator[T, V]) LastError() error {
+	if err := r.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Close detaches the Replicator from the cache and the bus after publishing
+// any queued invalidations.
+func (r *Replicator[T, V]) Close() error {
+	r.once.Do(func() {
+		r.c.replicator.CompareAndSwap(r, nil)
+		r.cancel()
+		close(r.stop)
+		<-r.done
+	})
+	return nil
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryBus delivers every message to every subscriber synchronously.
+type memoryBus struct {
+	mu       sync.Mutex
+	handlers map[int]func([]byte)
+	next     int
+}
+
+func (b *memoryBus) Publish(_ context.Context, msg []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, h := range b.handlers {
+		h(msg)
+	}
+	return nil
+}
+
+func (b *memoryBus) Subscribe(handle func([]byte)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.handlers == nil {
+		b.handlers = make(map[int]func([]byte))
+	}
+	id := b.next
+	b.next++
+	b.handlers[id] = handle
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}, nil
+}
+
+func TestReplicator(t *testing.T) {
+	bus := &memoryBus{}
+	a := NewCache[string, int](time.Minute)
+	b := NewCache[string, int](time.Minute)
+	defer a.StopCleanup()
+	defer b.StopCleanup()
+	a.Set("k", 1)
+	ra, err := NewReplicator(a, bus)
+	assert.NoError(t, err)
+	rb, err := NewReplicator(b, bus)
+	assert.NoError(t, err)
+
+	b.Set("k", 2)
+	assert.Eventually(t, func() bool {
+		_, found := a.Get("k")
+		return !found
+	}, time.Second, time.Millisecond, "Expected a write on b to evict a's copy")
+	value, found := b.Get("k")
+	assert.True(t, found, "Expected a write not to evict its own copy")
+	assert.Equal(t, 2, value)
+
+	a.Delete("k")
+	assert.Eventually(t, func() bool {
+		_, found := b.Get("k")
+		return !found
+	}, time.Second, time.Millisecond)
+
+	b.Set("x", 1)
+	b.Set("y", 1)
+	a.Clear()
+	assert.Eventually(t, func() bool { return b.Len() == 0 }, time.Second, time.Millisecond)
+
+	load := func(string) (int, error) { return 1, nil }
+	_, err = b.GetOrLoad("loaded", load)
+	assert.NoError(t, err)
+	_, err = a.GetOrLoad("loaded", load)
+	assert.NoError(t, err)
+	assert.NoError(t, rb.Close())
+	assert.NoError(t, ra.Close())
+	_, found = b.Get("loaded")
+	assert.True(t, found, "Expected loads not to invalidate replicas")
+	assert.NoError(t, ra.LastError())
+}
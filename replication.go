@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/gob"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replicaBuffer is the number of operations queued for a replica before it
+// is considered too slow and disconnected to resynchronize.
+const replicaBuffer = 4096
+
+// followRetry is the delay between a follower's reconnection attempts.
+const followRetry = time.Second
+
+// stream fans the operation log out to connected replicas.
+type stream[T comparable] struct {
+	l        net.Listener
+	mu       sync.Mutex
+	replicas map[*replica[T]]struct{}
+	lastErr  atomic.Pointer[error]
+}
+
+type replica[T comparable] struct {
+	ch chan aofRecord[T]
+}
+
+func (s *stream[T]) record(err error) {
+	if err != nil {
+		s.lastErr.Store(&err)
+	}
+}
+
+// broadcast queues rec for every replica. The caller holds s.mu. Replicas
+// whose queue is full are dropped; they resynchronize when they reconnect.
+func (s *stream[T]) broadcast(rec aofRecord[T]) {
+	for r := range s.replicas {
+		select {
+		case r.ch <- rec:
+		default:
+			delete(s.replicas, r)
+			close(r.ch)
+		}
+	}
+}
+
+// serveReplicas accepts replica connections until StopCleanup.
+func (c *Cache[T, V]) serveReplicas() {
+	s := c.stream
+	c.background(func() {
+		<-c.stopCleanup
+		s.l.Close()
+	})
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			select {
+			case <-c.stopCleanup:
+			default:
+				s.record(err)
+			}
+			return
+		}
+		c.background(func() { c.feedReplica(conn) })
+	}
+}
+
+// feedReplica sends a snapshot of the cache followed by every operation
+// logged after it.
+func (c *Cache[T, V]) feedReplica(conn net.Conn) {
+	s := c.stream
+	defer conn.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-c.stopCleanup:
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	r := &replica[T]{ch: make(chan aofRecord[T], replicaBuffer)}
+	var snap bytes.Buffer
+	s.mu.Lock()
+	err := c.SaveTo(&snap)
+	if err == nil {
+		if s.replicas == nil {
+			s.replicas = make(map[*replica[T]]struct{})
+		}
+		s.replicas[r] = struct{}{}
+	}
+	s.mu.Unlock()
+	if err != nil {
+		s.record(err)
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.replicas, r)
+		s.mu.Unlock()
+	}()
+
+	w := bufio.NewWriter(conn)
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snap.Bytes()); err != nil {
+		return
+	}
+	for {
+		if len(r.ch) == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		select {
+		case rec, ok := <-r.ch:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(rec); err != nil {
+				return
+			}
+		case <-c.stopCleanup:
+			return
+		}
+	}
+}
+
+// Follow makes c a warm standby of the primary at addr, which serves
+// replication with WithReplication. The cache is replaced with a snapshot of
+// the primary and then kept up to date with every Set, Delete, Expire and
+// Clear applied there, reconnecting and resynchronizing after failures until
+// ctx is done. Follow returns ctx.Err(); connection errors are reported by
+// LastReplicationError. To take over from the primary, cancel ctx and use c.
+func (c *Cache[T, V]) Follow(ctx context.Context, addr string) error {
+	c.following.Add(1)
+	defer c.following.Add(-1)
+	for {
+		c.recordReplication(c.follow(ctx, addr))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(followRetry):
+		}
+	}
+}
+
+func (c *Cache[T, V]) follow(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	dec := gob.NewDecoder(bufio.NewReader(conn))
+	var snap []byte
+	if err := dec.Decode(&snap); err != nil {
+		return err
+	}
+	c.clear()
+	if err := c.LoadFrom(bytes.NewReader(snap)); err != nil {
+		return err
+	}
+	c.synced.Add(1)
+	defer c.synced.Add(-1)
+	codec := c.valueCodec()
+	for {
+		var rec aofRecord[T]
+		if err := dec.Decode(&rec); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := c.apply(rec, codec, c.wallNow()); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Cache[T, V]) recordReplication(err error) {
+	if err != nil {
+		c.replErr.Store(&err)
+	}
+}
+
+// LastReplicationError returns the most recent error serving or following a
+// replication stream, or nil.
+func (c *Cache[T, V]) LastReplicationError() error {
+	if c.stream != nil {
+		if err := c.stream.lastErr.Load(); err != nil {
+			return *err
+		}
+	}
+	if err := c.replErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
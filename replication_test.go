@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheReplication(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := NewCache[string, int](time.Minute, WithReplication[string, int](l))
+	defer primary.StopCleanup()
+	primary.Set("before", 1)
+
+	standby := NewCache[string, int](time.Minute)
+	defer standby.StopCleanup()
+	standby.Set("stale", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- standby.Follow(ctx, l.Addr().String()) }()
+
+	has := func(key string, want int) func() bool {
+		return func() bool {
+			value, found := standby.Get(key)
+			return found && value == want
+		}
+	}
+	assert.Eventually(t, has("before", 1), time.Second, time.Millisecond, "Expected the snapshot to be replicated")
+	_, found := standby.Get("stale")
+	assert.False(t, found, "Expected the standby to be replaced by the snapshot")
+
+	primary.Set("a", 1)
+	primary.Set("a", 2)
+	primary.Set("b", 3)
+	primary.Delete("b")
+	assert.Eventually(t, has("a", 2), time.Second, time.Millisecond)
+	_, found = standby.Get("b")
+	assert.False(t, found, "Expected operations to be applied in order")
+
+	primary.Expire("a", time.Hour)
+	assert.Eventually(t, func() bool {
+		ttl, _ := standby.TTL("a")
+		return ttl > 2*time.Minute
+	}, time.Second, time.Millisecond)
+
+	primary.SoftDelete("a", time.Minute)
+	assert.Eventually(t, func() bool {
+		_, found := standby.Get("a")
+		return !found
+	}, time.Second, time.Millisecond, "Expected SoftDelete to be replicated")
+	primary.Undelete("a")
+	assert.Eventually(t, has("a", 2), time.Second, time.Millisecond, "Expected Undelete to be replicated")
+	g := primary.BeginGeneration()
+	g.Set("c", 4)
+	assert.NoError(t, primary.CommitGeneration(g))
+	assert.Eventually(t, has("c", 4), time.Second, time.Millisecond, "Expected generations to be replicated")
+	_, found = standby.Get("a")
+	assert.False(t, found)
+
+	primary.Clear()
+	assert.Eventually(t, func() bool { return standby.Len() == 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.NoError(t, primary.LastReplicationError())
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffFunc returns how long to wait before retry number attempt, counting
+// from 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff returns a BackoffFunc that doubles the delay on every
+// attempt, starting at base and capped at max, and picks a random delay up
+// to that bound ("full jitter") so that callers retrying together spread
+// out.
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := max
+		if shift := attempt - 1; shift < 62 && base<<shift > 0 && base<<shift < max {
+			d = base << shift
+		}
+		return time.Duration(rand.Int64N(int64(d) + 1))
+	}
+}
+
+type retryPolicy struct {
+	attempts int
+	backoff  BackoffFunc
+}
+
+// retry calls fn until it succeeds or the attempts configured with
+// WithLoaderRetry are used up, returning the last error.
+func retry[R any](p *retryPolicy, fn func() (R, error)) (R, error) {
+	result, err := fn()
+	if p == nil {
+		return result, err
+	}
+	for attempt := 1; err != nil && attempt < p.attempts; attempt++ {
+		if p.backoff != nil {
+			time.Sleep(p.backoff(attempt))
+		}
+		result, err = fn()
+	}
+	return result, err
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheLoaderRetry(t *testing.T) {
+	var delays []int
+	cache := NewCache[string, int](time.Minute, WithLoaderRetry[string, int](3, func(attempt int) time.Duration {
+		delays = append(delays, attempt)
+		return time.Millisecond
+	}))
+	defer cache.StopCleanup()
+
+	calls := 0
+	value, err := cache.GetOrLoad("k", func(string) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("transient")
+		}
+		return 7, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, value)
+	assert.Equal(t, []int{1, 2}, delays)
+
+	calls = 0
+	_, err = cache.GetOrLoad("other", func(string) (int, error) {
+		calls++
+		return 0, errors.New("down")
+	})
+	assert.EqualError(t, err, "down")
+	assert.Equal(t, 3, calls, "Expected attempts to bound the total number of calls")
+
+	calls = 0
+	_, err = cache.FetchMany([]string{"a"}, func([]string) (map[string]int, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("transient")
+		}
+		return map[string]int{"a": 1}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	for range 100 {
+		assert.LessOrEqual(t, backoff(1), 10*time.Millisecond)
+		assert.LessOrEqual(t, backoff(3), 40*time.Millisecond)
+		assert.LessOrEqual(t, backoff(100), 50*time.Millisecond)
+	}
+}
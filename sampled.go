@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// evictionSamples is how many keys sampledPolicy compares per eviction, the
+// default of Redis's maxmemory-samples.
+const evictionSamples = 5
+
+type sampledSlot[T comparable] struct {
+	// last is the policy clock at the key's latest access.
+	last uint64
+	key  T
+}
+
+// sampledPolicy implements EvictSampled. Keys are kept in an unordered slice
+// stamped with a logical clock on every access, which only takes atomic
+// writes; a victim is the least recently used of evictionSamples keys picked
+// at random.
+type sampledPolicy[T comparable] struct {
+	slots []sampledSlot[T]
+	index map[T]int
+	clock atomic.Uint64
+}
+
+func newSampledPolicy[T comparable]() *sampledPolicy[T] {
+	return &sampledPolicy[T]{index: make(map[T]int)}
+}
+
+func (p *sampledPolicy[T]) add(key T) {
+	if i, ok := p.index[key]; ok {
+		p.slots[i].last = p.clock.Add(1)
+		return
+	}
+	p.index[key] = len(p.slots)
+	p.slots = append(p.slots, sampledSlot[T]{last: p.clock.Add(1), key: key})
+}
+
+func (p *sampledPolicy[T]) access(key T) {
+	if i, ok := p.index[key]; ok {
+		atomic.StoreUint64(&p.slots[i].last, p.clock.Add(1))
+	}
+}
+
+func (p *sampledPolicy[T]) remove(key T) {
+	if i, ok := p.index[key]; ok {
+		p.release(i)
+	}
+}
+
+// release removes slot i by moving the last slot into its place.
+func (p *sampledPolicy[T]) release(i int) {
+	delete(p.index, p.slots[i].key)
+	last := len(p.slots) - 1
+	if i != last {
+		p.slots[i] = p.slots[last]
+		p.index[p.slots[i].key] = i
+	}
+	p.slots[last] = sampledSlot[T]{}
+	p.slots = p.slots[:last]
+}
+
+func (p *sampledPolicy[T]) victim() (T, bool) {
+	if len(p.slots) == 0 {
+		var zero T
+		return zero, false
+	}
+	best := rand.IntN(len(p.slots))
+	for range evictionSamples - 1 {
+		if i := rand.IntN(len(p.slots)); p.slots[i].last < p.slots[best].last {
+			best = i
+		}
+	}
+	key := p.slots[best].key
+	p.release(best)
+	return key, true
+}
+
+func (p *sampledPolicy[T]) has(key T) bool {
+	_, ok := p.index[key]
+	return ok
+}
+
+func (p *sampledPolicy[T]) len() int {
+	return len(p.slots)
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampledPolicy(t *testing.T) {
+	p := newSampledPolicy[int]()
+	for i := range 1000 {
+		p.add(i)
+	}
+	for i := 500; i < 1000; i++ {
+		p.access(i)
+	}
+	old := 0
+	for range 100 {
+		victim, ok := p.victim()
+		assert.True(t, ok)
+		if victim < 500 {
+			old++
+		}
+	}
+	assert.Greater(t, old, 80, "Expected mostly stale keys to be evicted")
+	assert.Equal(t, 900, p.len())
+
+	p.remove(999)
+	assert.False(t, p.has(999))
+	for p.len() > 0 {
+		p.victim()
+	}
+	_, ok := p.victim()
+	assert.False(t, ok)
+}
+
+func TestCacheEvictSampled(t *testing.T) {
+	cache := NewCache[int, int](time.Minute,
+		WithMaxEntries[int, int](50),
+		WithEvictionPolicy[int, int](EvictSampled),
+	)
+	defer cache.StopCleanup()
+
+	for i := range 500 {
+		cache.Set(i, i)
+	}
+	assert.Len(t, cache.Snapshot(), 50)
+	assert.Equal(t, uint64(450), cache.Stats().Evictions)
+}
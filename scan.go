@@ -0,0 +1,129 @@
+package cache
+
+import "cmp"
+
+// scanDefaultCount is the page size Scan uses when count is not positive,
+// as with Redis.
+const scanDefaultCount = 10
+
+// hashIndex keeps the hashes of the cache's keys in order, each with the keys
+// that share it, so that Scan resumes from a cursor without walking the
+// store. The first Scan builds it; writes maintain it from then on.
+type hashIndex[T comparable] struct {
+	// order holds the hashes; its lock also guards keys.
+	order *orderedIndex[uint64]
+	keys  map[uint64][]T
+}
+
+func (x *hashIndex[T]) add(key T, hash uint64) {
+	keys := x.keys[hash]
+	for _, k := range keys {
+		if k == key {
+			return
+		}
+	}
+	if len(keys) == 0 {
+		x.order.add(hash)
+	}
+	x.keys[hash] = append(keys, key)
+}
+
+func (x *hashIndex[T]) remove(key T, hash uint64) {
+	keys := x.keys[hash]
+	for i, k := range keys {
+		if k != key {
+			continue
+		}
+		if len(keys) == 1 {
+			delete(x.keys, hash)
+			x.order.remove(hash)
+		} else {
+			x.keys[hash] = append(keys[:i:i], keys[i+1:]...)
+		}
+		return
+	}
+}
+
+// hashIndex returns the cache's hash index, building it on first use.
+func (c *Cache[T, V]) hashIndex() *hashIndex[T] {
+	if x := c.hashes.Load(); x != nil {
+		return x
+	}
+	x := &hashIndex[T]{order: newOrderedIndex(cmp.Less[uint64]), keys: make(map[uint64][]T)}
+	x.order.mu.Lock()
+	defer x.order.mu.Unlock()
+	if !c.hashes.CompareAndSwap(nil, x) {
+		return c.hashes.Load()
+	}
+	// Writers index their keys from here on, waiting for the lock, so the
+	// keys added below and theirs end up agreeing with the store.
+	c.items().ForEach(func(key T, _ CachedItem[V]) bool {
+		x.add(key, c.hasher.hash(key))
+		return true
+	})
+	return x
+}
+
+// indexHash brings key's place in the hash index, if built, in line with the
+// store; see indexPath for why the store is read under the index lock.
+func (c *Cache[T, V]) indexHash(key T) {
+	x := c.hashes.Load()
+	if x == nil {
+		return
+	}
+	x.order.mu.Lock()
+	defer x.order.mu.Unlock()
+	if _, ok := c.items().Get(key); ok {
+		x.add(key, c.hasher.hash(key))
+	} else {
+		x.remove(key, c.hasher.hash(key))
+	}
+}
+
+// reindexHashes rebuilds the hash index, if built, after the store was
+// replaced.
+func (c *Cache[T, V]) reindexHashes(items Store[T, V]) {
+	x := c.hashes.Load()
+	if x == nil {
+		return
+	}
+	x.order.mu.Lock()
+	defer x.order.mu.Unlock()
+	x.order.reset()
+	x.keys = make(map[uint64][]T)
+	items.ForEach(func(key T, _ CachedItem[V]) bool {
+		x.add(key, c.hasher.hash(key))
+		return true
+	})
+}
+
+// Scan returns a page of about count keys, starting the walk at cursor 0
+// and continuing it with the returned cursor until that is 0 again, in the
+// style of Redis SCAN. Keys are visited in the order of their hashes, so
+// every key present for the whole walk is returned exactly once however the
+// cache changes in between; keys set or deleted meanwhile may or may not be
+// returned. No iteration is held open between calls. The first call builds
+// an index of the keys' hashes, which writes maintain from then on, so later
+// pages take time proportional to count. A page holds more than count keys
+// when several share a hash. Expired entries are skipped.
+func (c *Cache[T, V]) Scan(cursor uint64, count int) (keys []T, next uint64) {
+	if count <= 0 {
+		count = scanDefaultCount
+	}
+	x := c.hashIndex()
+	now := c.now()
+	x.order.mu.RLock()
+	defer x.order.mu.RUnlock()
+	n := x.order.find(cursor, nil).next[0]
+	for ; n != nil && len(keys) < count; n = n.next[0] {
+		for _, key := range x.keys[n.key] {
+			if item, ok := c.items().Get(key); ok && !c.expired(key, item, now) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if n == nil {
+		return keys, 0
+	}
+	return keys, n.key
+}
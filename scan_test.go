@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scanAll[T comparable, V any](c *Cache[T, V], count int, during func()) map[T]int {
+	seen := map[T]int{}
+	cursor := uint64(0)
+	for {
+		keys, next := c.Scan(cursor, count)
+		for _, key := range keys {
+			seen[key]++
+		}
+		if during != nil {
+			during()
+		}
+		if next == 0 {
+			return seen
+		}
+		cursor = next
+	}
+}
+
+func TestScan(t *testing.T) {
+	c := NewCache[string, int](time.Minute)
+	defer c.StopCleanup()
+	for i := range 1000 {
+		c.Set(strconv.Itoa(i), i)
+	}
+	keys, next := c.Scan(0, 0)
+	assert.Len(t, keys, 10)
+	assert.NotZero(t, next)
+
+	added := 0
+	seen := scanAll(c, 37, func() {
+		c.Set("new"+strconv.Itoa(added), 0)
+		added++
+	})
+	for i := range 1000 {
+		assert.Equal(t, 1, seen[strconv.Itoa(i)], "Expected every key present throughout to be returned once")
+	}
+	for key, n := range seen {
+		assert.Equal(t, 1, n, key)
+	}
+
+	empty := NewCache[string, int](time.Minute)
+	defer empty.StopCleanup()
+	keys, next = empty.Scan(0, 10)
+	assert.Empty(t, keys)
+	assert.Zero(t, next)
+}
+
+func TestScanCollisions(t *testing.T) {
+	c := NewCacheComparable[int, int](time.Minute, func(k int) uintptr { return uintptr(k / 10) })
+	defer c.StopCleanup()
+	for i := range 100 {
+		c.Set(i, i)
+	}
+	assert.NoError(t, c.SetWithTTL(1000, 0, time.Nanosecond))
+	item, _ := c.items().Get(1000)
+	assert.Eventually(t, func() bool { return c.now() >= item.expires }, time.Second, time.Millisecond)
+
+	keys, _ := c.Scan(0, 3)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, keys, "Expected keys sharing a hash to stay together")
+	seen := scanAll(c, 15, nil)
+	assert.Len(t, seen, 100, "Expected expired entries to be skipped")
+	for key, n := range seen {
+		assert.Equal(t, 1, n, key)
+	}
+}
+
+func TestScanIndexMaintained(t *testing.T) {
+	c := NewCache[int, int](time.Minute, WithMaxEntries[int, int](100))
+	defer c.StopCleanup()
+	for i := range 50 {
+		c.Set(i, i)
+	}
+	c.Scan(0, 10)
+	for i := range 10 {
+		c.Delete(i)
+	}
+	for i := 100; i < 200; i++ {
+		c.Set(i, i)
+	}
+	seen := scanAll(c, 7, nil)
+	assert.Len(t, seen, c.Len(), "Expected deletions, evictions and new keys to reach the index")
+	for i := 100; i < 200; i++ {
+		assert.Equal(t, 1, seen[i], i)
+	}
+
+	c.Clear()
+	keys, next := c.Scan(0, 10)
+	assert.Empty(t, keys)
+	assert.Zero(t, next)
+}
+
+func BenchmarkScan(b *testing.B) {
+	c := NewCache[int, int](time.Minute)
+	defer c.StopCleanup()
+	for i := range 100000 {
+		c.Set(i, i)
+	}
+	b.ResetTimer()
+	for range b.N {
+		scanAll(c, 100, nil)
+	}
+}
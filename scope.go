@@ -0,0 +1,78 @@
+package cache
+
+// scoped is a value remembered by a Scope. deleted marks keys the scope
+// itself removed, as opposed to misses read from the parent.
+type scoped[V any] struct {
+	value   V
+	found   bool
+	deleted bool
+}
+
+// Scope is a request-local overlay over a Cache created by RequestScope. It
+// remembers every value it has read, so repeated lookups of a key within a
+// request cost a map access and see a stable answer, and it keeps writes to
+// itself. A Scope is not safe for concurrent use.
+type Scope[T comparable, V any] struct {
+	parent  *Cache[T, V]
+	local   map[T]scoped[V]
+	cleared bool
+}
+
+// RequestScope returns an empty Scope over c, meant to be dropped when the
+// request ends.
+func (c *Cache[T, V]) RequestScope() *Scope[T, V] {
+	return &Scope[T, V]{parent: c, local: make(map[T]scoped[V])}
+}
+
+// Get returns the scope's own value for key, or else reads through to the
+// parent cache and remembers the result, including a miss.
+func (s *Scope[T, V]) Get(key T) (V, bool) {
+	key = s.parent.canonical(key)
+	if e, ok := s.local[key]; ok || s.cleared {
+		return e.value, e.found
+	}
+	value, found := s.parent.Get(key)
+	s.local[key] = scoped[V]{value: value, found: found}
+	return value, found
+}
+
+// GetOrLoad is Get that loads missing values through the parent's
+// GetOrLoad, so they are shared with other requests. Keys the scope deleted,
+// and every key once it is cleared, are loaded by calling loader directly
+// and kept in the scope, since the parent's value is hidden from it.
+func (s *Scope[T, V]) GetOrLoad(key T, loader func(T) (V, error)) (V, error) {
+	key = s.parent.canonical(key)
+	e := s.local[key]
+	if e.found {
+		return e.value, nil
+	}
+	var value V
+	var err error
+	if e.deleted || s.cleared {
+		value, err = loader(key)
+	} else {
+		value, err = s.parent.GetOrLoad(key, loader)
+	}
+	if err == nil {
+		s.local[key] = scoped[V]{value: value, found: true}
+	}
+	return value, err
+}
+
+// Set stores value in the scope only.
+func (s *Scope[T, V]) Set(key T, value V) {
+	s.local[s.parent.canonical(key)] = scoped[V]{value: value, found: true}
+}
+
+// Delete hides key from the scope without touching the parent.
+func (s *Scope[T, V]) Delete(key T) {
+	s.local[s.parent.canonical(key)] = scoped[V]{deleted: true}
+}
+
+// Clear empties the scope and stops it from reading through to the parent.
+func (s *Scope[T, V]) Clear() {
+	clear(s.local)
+	s.cleared = true
+}
+
+var _ Cacher[int, int] = (*Scope[int, int])(nil)
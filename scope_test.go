@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestScope(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("shared", 1)
+
+	scope := cache.RequestScope()
+	value, found := scope.Get("shared")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	_, found = scope.Get("missing")
+	assert.False(t, found)
+
+	cache.Set("shared", 2)
+	cache.Set("missing", 3)
+	value, _ = scope.Get("shared")
+	assert.Equal(t, 1, value, "Expected reads to be stable within the scope")
+	_, found = scope.Get("missing")
+	assert.False(t, found, "Expected misses to be remembered too")
+
+	scope.Set("local", 4)
+	scope.Delete("shared")
+	_, found = cache.Get("local")
+	assert.False(t, found, "Expected writes to stay in the scope")
+	_, found = scope.Get("shared")
+	assert.False(t, found)
+	_, found = cache.Get("shared")
+	assert.True(t, found)
+
+	scope.Clear()
+	_, found = scope.Get("shared")
+	assert.False(t, found, "Expected a cleared scope not to read through")
+}
+
+func TestRequestScopeGetOrLoad(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	calls := 0
+	loader := func(string) (int, error) { calls++; return 5, nil }
+	scope := cache.RequestScope()
+	scope.GetOrLoad("k", loader)
+	value, err := scope.GetOrLoad("k", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, value)
+	assert.Equal(t, 1, calls)
+
+	value, found := cache.Get("k")
+	assert.True(t, found, "Expected loaded values to be shared through the parent")
+	assert.Equal(t, 5, value)
+}
+
+func TestRequestScopeGetOrLoadHidden(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("k", 1)
+	cache.Set("j", 2)
+
+	loader := func(string) (int, error) { return 9, nil }
+	scope := cache.RequestScope()
+	scope.Delete("k")
+	value, err := scope.GetOrLoad("k", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, value, "Expected a key deleted in the scope to be loaded rather than read from the parent")
+	value, _ = cache.Get("k")
+	assert.Equal(t, 1, value, "Expected the parent to be left alone")
+
+	scope.Clear()
+	value, _ = scope.GetOrLoad("j", loader)
+	assert.Equal(t, 9, value, "Expected a cleared scope not to read through to the parent")
+	value, _ = scope.Get("j")
+	assert.Equal(t, 9, value)
+	value, _ = cache.Get("j")
+	assert.Equal(t, 2, value)
+
+	scope = cache.RequestScope()
+	_, found := scope.Get("missing")
+	assert.False(t, found)
+	scope.GetOrLoad("missing", loader)
+	value, found = cache.Get("missing")
+	assert.True(t, found, "Expected remembered misses to still load through the parent")
+	assert.Equal(t, 9, value)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import "sync/atomic"
+
+type clockSlot[T comparable] struct {
+	key  T
+	ref  uint32
+	used bool
+}
+
+// clockPolicy implements EvictClock. Keys live in a ring of slots; access
+// only sets the slot's reference bit, atomically, so it may run under the
+// evictor's read lock. The hand clears set bits as it passes and evicts the
+// first key whose bit is already clear.
+type clockPolicy[T comparable] struct {
+	slots []clockSlot[T]
+	index map[T]int
+	free  []int
+	hand  int
+}
+
+func newClockPolicy[T comparable]() *clockPolicy[T] {
+	return &clockPolicy[T]{index: make(map[T]int)}
+}
+
+func (p *clockPolicy[T]) add(key T) {
+	if i, ok := p.index[key]; ok {
+		p.slots[i].ref = 1
+		return
+	}
+	slot := clockSlot[T]{key: key, ref: 1, used: true}
+	if n := len(p.free); n > 0 {
+		i := p.free[n-1]
+		p.free = p.free[:n-1]
+		p.slots[i] = slot
+		p.index[key] = i
+		return
+	}
+	p.index[key] = len(p.slots)
+	p.slots = append(p.slots, slot)
+}
+
+func (p *clockPolicy[T]) access(key T) {
+	if i, ok := p.index[key]; ok {
+		if ref := &p.slots[i].ref; atomic.LoadUint32(ref) == 0 {
+			atomic.StoreUint32(ref, 1)
+		}
+	}
+}
+
+func (p *clockPolicy[T]) remove(key T) {
+	if i, ok := p.index[key]; ok {
+		p.release(i)
+	}
+}
+
+func (p *clockPolicy[T]) release(i int) {
+	delete(p.index, p.slots[i].key)
+	p.slots[i] = clockSlot[T]{}
+	p.free = append(p.free, i)
+}
+
+func (p *clockPolicy[T]) victim() (T, bool) {
+	if len(p.index) == 0 {
+		var zero T
+		return zero, false
+	}
+	for {
+		if p.hand >= len(p.slots) {
+			p.hand = 0
+		}
+		s := &p.slots[p.hand]
+		p.hand++
+		if !s.used {
+			continue
+		}
+		if atomic.LoadUint32(&s.ref) != 0 {
+			atomic.StoreUint32(&s.ref, 0)
+			continue
+		}
+		key := s.key
+		p.release(p.hand - 1)
+		return key, true
+	}
+}
+
+func (p *clockPolicy[T]) has(key T) bool {
+	_, ok := p.index[key]
+	return ok
+}
+
+func (p *clockPolicy[T]) len() int {
+	return len(p.index)
+}
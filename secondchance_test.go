@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockPolicy(t *testing.T) {
+	p := newClockPolicy[string]()
+	for _, key := range []string{"a", "b", "c"} {
+		p.add(key)
+	}
+	// The first sweep clears every reference bit and evicts the oldest key.
+	victim, ok := p.victim()
+	assert.True(t, ok)
+	assert.Equal(t, "a", victim)
+
+	p.access("b")
+	victim, _ = p.victim()
+	assert.Equal(t, "c", victim, "Expected referenced keys to get a second chance")
+
+	p.add("d")
+	p.remove("b")
+	assert.False(t, p.has("b"))
+	victim, _ = p.victim()
+	assert.Equal(t, "d", victim)
+	_, ok = p.victim()
+	assert.False(t, ok)
+	assert.Equal(t, 0, p.len())
+}
+
+func TestCacheEvictClock(t *testing.T) {
+	cache := NewCache[string, int](time.Minute,
+		WithMaxEntries[string, int](100),
+		WithEvictionPolicy[string, int](EvictClock),
+	)
+	defer cache.StopCleanup()
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := strconv.Itoa(g*1000 + i)
+				cache.Set(key, i)
+				cache.Get(key)
+				cache.Get("hot")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, len(cache.Snapshot()), 100)
+	assert.Equal(t, "clock", EvictClock.String())
+}
@@ -0,0 +1,270 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// maxRelativeExptime is the largest exptime memcached treats as relative
+// seconds; larger values are absolute Unix timestamps.
+const maxRelativeExptime = 30 * 24 * 60 * 60
+
+// maxKeyLen is memcached's limit on the length of a key.
+const maxKeyLen = 250
+
+// DefaultMaxValueSize is the largest value Memcached accepts by default,
+// matching memcached's default item size limit.
+const DefaultMaxValueSize = 1 << 20
+
+// Memcached serves the memcached text protocol commands get, gets, set,
+// delete, flush_all, stats, version and quit from a cache.
+//
+// Flags sent with set are accepted but not stored, so get always reports
+// flags of 0. An exptime of 0 uses the cache's TTL. Keys longer than 250
+// bytes are rejected, and connections sending a line longer than 64 KiB
+// are closed.
+type Memcached struct {
+	base
+	c            *cache.Cache[string, []byte]
+	MaxValueSize int
+	started      time.Time
+	// mu serializes set, delete and flush_all, so that delete reports
+	// whether it removed the item against every write made through the
+	// server.
+	mu sync.Mutex
+
+	cmdGet, cmdSet, hits, misses atomic.Uint64
+}
+
+// NewMemcached returns a memcached protocol server backed by c.
+func NewMemcached(c *cache.Cache[string, []byte]) *Memcached {
+	return &Memcached{c: c, MaxValueSize: DefaultMaxValueSize, started: time.Now()}
+}
+
+// ListenAndServe listens on the TCP address addr and calls Serve.
+func (s *Memcached) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until it fails or Close is called. It
+// always returns a non-nil error.
+func (s *Memcached) Serve(l net.Listener) error {
+	return s.serve(l, s.handle)
+}
+
+func (s *Memcached) handle(r *bufio.Reader, w *bufio.Writer) {
+	for {
+		line, err := readLine(r)
+		if errors.Is(err, errLineTooLong) {
+			fmt.Fprint(w, "CLIENT_ERROR line too long\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			fmt.Fprint(w, "ERROR\r\n")
+		} else if !validKeys(fields) {
+			fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		} else if fields[0] == "quit" {
+			w.Flush()
+			return
+		} else if err := s.exec(fields, r, w); err != nil {
+			w.Flush()
+			return
+		}
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// validKeys reports whether the keys of a get or delete command f are short
+// enough; set checks its own, as it must skip the data block.
+func validKeys(f []string) bool {
+	var keys []string
+	switch f[0] {
+	case "get", "gets":
+		keys = f[1:]
+	case "delete":
+		keys = f[1:min(2, len(f))]
+	}
+	for _, key := range keys {
+		if len(key) > maxKeyLen {
+			return false
+		}
+	}
+	return true
+}
+
+// exec runs one command. It returns an error only when the connection can
+// no longer be used.
+func (s *Memcached) exec(f []string, r *bufio.Reader, w *bufio.Writer) error {
+	switch f[0] {
+	case "get", "gets":
+		for _, key := range f[1:] {
+			s.cmdGet.Add(1)
+			value, ok := s.c.Get(key)
+			if !ok {
+				s.misses.Add(1)
+				continue
+			}
+			s.hits.Add(1)
+			fmt.Fprintf(w, "VALUE %s 0 %d", key, len(value))
+			if f[0] == "gets" {
+				fmt.Fprint(w, " 0")
+			}
+			fmt.Fprint(w, "\r\n")
+			w.Write(value)
+			fmt.Fprint(w, "\r\n")
+		}
+		fmt.Fprint(w, "END\r\n")
+	case "set":
+		return s.set(f, r, w)
+	case "delete":
+		if len(f) < 2 {
+			fmt.Fprint(w, "ERROR\r\n")
+			return nil
+		}
+		s.mu.Lock()
+		_, ok := s.c.Get(f[1])
+		if ok {
+			s.c.Delete(f[1])
+		}
+		s.mu.Unlock()
+		if noreply(f, 2) {
+			return nil
+		}
+		if ok {
+			fmt.Fprint(w, "DELETED\r\n")
+		} else {
+			fmt.Fprint(w, "NOT_FOUND\r\n")
+		}
+	case "flush_all":
+		s.mu.Lock()
+		s.c.Clear()
+		s.mu.Unlock()
+		if !noreply(f, len(f)-1) {
+			fmt.Fprint(w, "OK\r\n")
+		}
+	case "stats":
+		s.stats(w)
+	case "version":
+		fmt.Fprint(w, "VERSION memorycache\r\n")
+	default:
+		fmt.Fprint(w, "ERROR\r\n")
+	}
+	return nil
+}
+
+// set handles "set <key> <flags> <exptime> <bytes> [noreply]".
+func (s *Memcached) set(f []string, r *bufio.Reader, w *bufio.Writer) error {
+	if len(f) < 5 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return nil
+	}
+	exptime, err1 := strconv.ParseInt(f[3], 10, 64)
+	n, err2 := strconv.Atoi(f[4])
+	if _, err := strconv.ParseUint(f[2], 10, 32); err != nil || err1 != nil || err2 != nil || n < 0 {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+	if len(f[1]) > maxKeyLen || n > s.MaxValueSize {
+		if len(f[1]) > maxKeyLen {
+			fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		} else {
+			fmt.Fprint(w, "SERVER_ERROR object too large for cache\r\n")
+		}
+		// Skip the data block so the connection stays in sync.
+		_, err := r.Discard(n + 2)
+		return err
+	}
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		fmt.Fprint(w, "CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+	s.cmdSet.Add(1)
+	value := data[:n:n]
+	var err error
+	s.mu.Lock()
+	switch ttl := exptimeTTL(exptime); {
+	case ttl < 0:
+		s.c.Delete(f[1])
+	case ttl == 0:
+		err = s.c.TrySet(f[1], value)
+	default:
+		err = s.c.SetWithTTL(f[1], value, ttl)
+	}
+	s.mu.Unlock()
+	if noreply(f, 5) {
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(w, "SERVER_ERROR %s\r\n", err)
+		return nil
+	}
+	fmt.Fprint(w, "STORED\r\n")
+	return nil
+}
+
+// exptimeTTL converts a memcached exptime to a TTL: zero for the default TTL
+// and negative for an already expired item.
+func exptimeTTL(exptime int64) time.Duration {
+	switch {
+	case exptime == 0:
+		return 0
+	case exptime < 0:
+		return -1
+	case exptime <= maxRelativeExptime:
+		return time.Duration(exptime) * time.Second
+	}
+	ttl := time.Until(time.Unix(exptime, 0))
+	if ttl <= 0 {
+		return -1
+	}
+	return ttl
+}
+
+func noreply(f []string, i int) bool {
+	return i > 0 && i < len(f) && f[i] == "noreply"
+}
+
+func (s *Memcached) stats(w *bufio.Writer) {
+	stat := func(name string, value any) {
+		fmt.Fprintf(w, "STAT %s %v\r\n", name, value)
+	}
+	stat("pid", os.Getpid())
+	stat("uptime", int64(time.Since(s.started)/time.Second))
+	stat("time", time.Now().Unix())
+	stat("curr_connections", s.connections())
+	stat("curr_items", s.c.Len())
+	stat("cmd_get", s.cmdGet.Load())
+	stat("cmd_set", s.cmdSet.Load())
+	stat("get_hits", s.hits.Load())
+	stat("get_misses", s.misses.Load())
+	fmt.Fprint(w, "END\r\n")
+}
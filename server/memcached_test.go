@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/stretchr/testify/assert"
+)
+
+// client sends raw protocol text and reads reply lines.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *client) send(format string, args ...any) {
+	c.t.Helper()
+	if _, err := fmt.Fprintf(c.conn, format, args...); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) line() string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	s, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return strings.TrimSuffix(s, "\r\n")
+}
+
+func startMemcached(t *testing.T, c *cache.Cache[string, []byte]) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewMemcached(c)
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+	t.Cleanup(func() {
+		s.Close()
+		assert.ErrorIs(t, <-done, ErrServerClosed)
+	})
+	return l.Addr().String()
+}
+
+func TestMemcached(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	cl := dial(t, startMemcached(t, c))
+
+	cl.send("set a 0 0 5\r\nhello\r\n")
+	assert.Equal(t, "STORED", cl.line())
+	cl.send("set b 0 0 3 noreply\r\nbye\r\n")
+
+	cl.send("get a b missing\r\n")
+	assert.Equal(t, "VALUE a 0 5", cl.line())
+	assert.Equal(t, "hello", cl.line())
+	assert.Equal(t, "VALUE b 0 3", cl.line())
+	assert.Equal(t, "bye", cl.line())
+	assert.Equal(t, "END", cl.line())
+
+	value, _ := c.Get("a")
+	assert.Equal(t, []byte("hello"), value, "Expected values to be shared with the cache")
+
+	cl.send("delete a\r\ndelete a\r\n")
+	assert.Equal(t, "DELETED", cl.line())
+	assert.Equal(t, "NOT_FOUND", cl.line())
+
+	cl.send("flush_all\r\n")
+	assert.Equal(t, "OK", cl.line())
+	assert.Equal(t, 0, c.Len())
+
+	cl.send("stats\r\n")
+	stats := map[string]string{}
+	for l := cl.line(); l != "END"; l = cl.line() {
+		f := strings.Fields(l)
+		stats[f[1]] = f[2]
+	}
+	assert.Equal(t, "3", stats["cmd_get"])
+	assert.Equal(t, "2", stats["get_hits"])
+	assert.Equal(t, "1", stats["curr_connections"])
+
+	cl.send("bogus\r\n")
+	assert.Equal(t, "ERROR", cl.line())
+}
+
+func TestMemcachedExptime(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	cl := dial(t, startMemcached(t, c))
+
+	cl.send("set short 0 1 1\r\nx\r\n")
+	assert.Equal(t, "STORED", cl.line())
+	cl.send("set gone 0 -1 1\r\nx\r\n")
+	assert.Equal(t, "STORED", cl.line())
+
+	cl.send("get gone\r\n")
+	assert.Equal(t, "END", cl.line(), "Expected a negative exptime to expire immediately")
+
+	time.Sleep(1100 * time.Millisecond)
+	cl.send("get short\r\n")
+	assert.Equal(t, "END", cl.line(), "Expected exptime to override the cache TTL")
+}
+
+func TestMemcachedTooLarge(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewMemcached(c)
+	s.MaxValueSize = 4
+	go s.Serve(l)
+	defer s.Close()
+	cl := dial(t, l.Addr().String())
+
+	cl.send("set k 0 0 5\r\nhello\r\nget k\r\n")
+	assert.Equal(t, "SERVER_ERROR object too large for cache", cl.line())
+	assert.Equal(t, "END", cl.line(), "Expected the connection to stay in sync")
+}
+
+func TestMemcachedLimits(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	cl := dial(t, startMemcached(t, c))
+
+	long := strings.Repeat("k", maxKeyLen+1)
+	cl.send("set %s 0 0 1\r\nx\r\nget %s\r\n", long, long)
+	assert.Equal(t, "CLIENT_ERROR bad command line format", cl.line())
+	assert.Equal(t, "CLIENT_ERROR bad command line format", cl.line())
+	cl.send("delete %s\r\nget k\r\n", long)
+	assert.Equal(t, "CLIENT_ERROR bad command line format", cl.line())
+	assert.Equal(t, "END", cl.line(), "Expected the connection to stay in sync")
+	assert.Zero(t, c.Len())
+
+	cl.send("get %s\r\n", strings.Repeat("k ", maxLineLen))
+	assert.Equal(t, "CLIENT_ERROR line too long", cl.line())
+	cl.conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := cl.r.ReadString('\n')
+	assert.Error(t, err, "Expected the connection to be closed")
+}
@@ -0,0 +1,475 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// maxBulkLen bounds the size of a single RESP bulk string a client may send.
+const maxBulkLen = 512 << 20
+
+// maxArgs bounds the number of arguments of a RESP command.
+const maxArgs = 1 << 20
+
+// bulkChunk is the most buffer readBulk sets aside before data arrives.
+const bulkChunk = 64 << 10
+
+var errProtocol = errors.New("server: protocol error")
+
+// respArity holds the argument count of each command, including the command
+// name; negative values are minimums.
+var respArity = map[string]int{
+	"PING": -1, "ECHO": 2, "GET": 2, "SET": -3, "DEL": -2, "EXISTS": -2,
+	"EXPIRE": 3, "PEXPIRE": 3, "TTL": 2, "PTTL": 2, "INCR": 2, "DECR": 2,
+	"INCRBY": 3, "DECRBY": 3, "KEYS": 2, "DBSIZE": 1, "FLUSHDB": -1,
+	"FLUSHALL": -1, "COMMAND": -1, "INFO": -1,
+}
+
+// RESP serves a subset of the Redis protocol from a cache: PING, ECHO, GET,
+// SET (with EX, PX, NX and XX), DEL, EXISTS, EXPIRE, PEXPIRE, TTL, PTTL,
+// INCR, INCRBY, DECR, DECRBY, KEYS, DBSIZE, FLUSHDB, FLUSHALL, COMMAND, INFO
+// and QUIT. Every entry has a TTL, so TTL never reports -1. INFO reports the
+// cache's Stats in the Redis format, ignoring any section argument. As with
+// Redis, a command line longer than 64 KiB or a bulk string longer than 512
+// MiB is a protocol error that closes the connection.
+type RESP struct {
+	base
+	c *cache.Cache[string, []byte]
+	// mu serializes the commands that write, so that read-modify-write
+	// ones such as INCR, SET NX and DEL are atomic with respect to every
+	// write made through the server. Writes made through the Go API are
+	// not serialized with them.
+	mu sync.Mutex
+}
+
+// NewRESP returns a Redis protocol server backed by c.
+func NewRESP(c *cache.Cache[string, []byte]) *RESP {
+	return &RESP{c: c}
+}
+
+// ListenAndServe listens on the TCP address addr and calls Serve.
+func (s *RESP) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until it fails or Close is called. It
+// always returns a non-nil error.
+func (s *RESP) Serve(l net.Listener) error {
+	return s.serve(l, s.handle)
+}
+
+func (s *RESP) handle(r *bufio.Reader, w *bufio.Writer) {
+	for {
+		args, err := readCommand(r)
+		if errors.Is(err, errProtocol) || errors.Is(err, errLineTooLong) {
+			writeError(w, "ERR Protocol error")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if strings.EqualFold(args[0], "QUIT") {
+			writeSimple(w, "OK")
+			w.Flush()
+			return
+		}
+		s.exec(args, w)
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommand reads a command sent either as a RESP array of bulk strings or
+// as an inline command line.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 || n > maxArgs {
+		return nil, errProtocol
+	}
+	// Sizes announced by the client are not trusted for allocation up
+	// front, as the data may never arrive.
+	args := make([]string, 0, min(n, 64))
+	for range n {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, errProtocol
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, errProtocol
+		}
+		arg, err := readBulk(r, size)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// readBulk reads a bulk string of size bytes and its line ending, growing
+// the buffer as the data arrives.
+func readBulk(r *bufio.Reader, size int) (string, error) {
+	var b strings.Builder
+	b.Grow(min(size, bulkChunk))
+	if _, err := io.CopyN(&b, r, int64(size)); err != nil {
+		return "", err
+	}
+	var crlf [2]byte
+	if _, err := io.ReadFull(r, crlf[:]); err != nil {
+		return "", err
+	}
+	if crlf != [2]byte{'\r', '\n'} {
+		return "", errProtocol
+	}
+	return b.String(), nil
+}
+
+func (s *RESP) exec(args []string, w *bufio.Writer) {
+	cmd := strings.ToUpper(args[0])
+	want, ok := respArity[cmd]
+	if !ok {
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+	if want > 0 && len(args) != want || want < 0 && len(args) < -want {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(cmd)))
+		return
+	}
+	switch cmd {
+	case "PING":
+		if len(args) > 1 {
+			writeBulk(w, []byte(args[1]))
+		} else {
+			writeSimple(w, "PONG")
+		}
+	case "ECHO":
+		writeBulk(w, []byte(args[1]))
+	case "GET":
+		value, ok := s.c.Get(args[1])
+		if !ok {
+			writeNull(w)
+			return
+		}
+		writeBulk(w, value)
+	case "SET":
+		s.set(args, w)
+	case "DEL", "EXISTS":
+		if cmd == "DEL" {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+		}
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := s.c.Get(key); ok {
+				n++
+				if cmd == "DEL" {
+					s.c.Delete(key)
+				}
+			}
+		}
+		writeInt(w, int64(n))
+	case "EXPIRE", "PEXPIRE":
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		unit := time.Second
+		if cmd == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if n <= 0 {
+			_, ok := s.c.Get(args[1])
+			s.c.Delete(args[1])
+			writeBool(w, ok)
+			return
+		}
+		writeBool(w, s.c.Expire(args[1], time.Duration(n)*unit))
+	case "TTL", "PTTL":
+		ttl, ok := s.c.TTL(args[1])
+		switch {
+		case !ok:
+			writeInt(w, -2)
+		case cmd == "TTL":
+			writeInt(w, int64((ttl+time.Second/2)/time.Second))
+		default:
+			writeInt(w, int64(ttl/time.Millisecond))
+		}
+	case "INCR", "DECR", "INCRBY", "DECRBY":
+		by := int64(1)
+		if len(args) == 3 {
+			n, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				writeError(w, "ERR value is not an integer or out of range")
+				return
+			}
+			by = n
+		}
+		if strings.HasPrefix(cmd, "DECR") {
+			by = -by
+		}
+		s.incr(args[1], by, w)
+	case "KEYS":
+		var keys []string
+		s.c.Range(func(key string, _ []byte) bool {
+			if globMatch(args[1], key) {
+				keys = append(keys, key)
+			}
+			return true
+		})
+		fmt.Fprintf(w, "*%d\r\n", len(keys))
+		for _, key := range keys {
+			writeBulk(w, []byte(key))
+		}
+	case "DBSIZE":
+		writeInt(w, int64(s.c.Len()))
+	case "FLUSHDB", "FLUSHALL":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.c.Clear()
+		writeSimple(w, "OK")
+	case "INFO":
+		stats := s.c.Stats()
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Stats\r\nconnected_clients:%d\r\nkeyspace_hits:%d\r\nkeyspace_misses:%d\r\nevicted_keys:%d\r\n",
+			s.connections(), stats.Hits, stats.Misses, stats.Evictions)
+		fmt.Fprintf(&b, "# Keyspace\r\ndb0:keys=%d\r\n", s.c.Len())
+		writeBulk(w, []byte(b.String()))
+	case "COMMAND":
+		// Clients such as redis-cli probe COMMAND DOCS on connect; an empty
+		// reply makes them fall back to plain behaviour.
+		fmt.Fprint(w, "*0\r\n")
+	}
+}
+
+// set handles SET key value [EX seconds | PX milliseconds] [NX | XX].
+func (s *RESP) set(args []string, w *bufio.Writer) {
+	var ttl time.Duration
+	var nx, xx bool
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 == len(args) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			i++
+			n, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			ttl = time.Duration(n) * time.Second
+			if opt == "PX" {
+				ttl = time.Duration(n) * time.Millisecond
+			}
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	if nx && xx {
+		writeError(w, "ERR syntax error")
+		return
+	}
+	key, value := args[1], []byte(args[2])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if nx || xx {
+		if _, exists := s.c.Get(key); exists == nx {
+			writeNull(w)
+			return
+		}
+	}
+	var err error
+	if ttl > 0 {
+		err = s.c.SetWithTTL(key, value, ttl)
+	} else {
+		err = s.c.TrySet(key, value)
+	}
+	if err != nil {
+		writeError(w, "ERR "+err.Error())
+		return
+	}
+	writeSimple(w, "OK")
+}
+
+// incr adds by to the integer stored at key, keeping its remaining TTL.
+func (s *RESP) incr(key string, by int64, w *bufio.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	value, ok := s.c.Get(key)
+	if ok {
+		var err error
+		if n, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+	}
+	if by > 0 && n > 1<<63-1-by || by < 0 && n < -1<<63-by {
+		writeError(w, "ERR increment or decrement would overflow")
+		return
+	}
+	n += by
+	var err error
+	if ttl, ok := s.c.TTL(key); ok && ttl > 0 {
+		err = s.c.SetWithTTL(key, strconv.AppendInt(nil, n, 10), ttl)
+	} else {
+		err = s.c.TrySet(key, strconv.AppendInt(nil, n, 10))
+	}
+	if err != nil {
+		writeError(w, "ERR "+err.Error())
+		return
+	}
+	writeInt(w, n)
+}
+
+// globMatch reports whether s matches the Redis glob pattern: * matches any
+// bytes, / included, ? matches one byte, [...] matches a set of bytes or
+// ranges, negated by a leading ^, and a backslash escapes the next byte.
+func globMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	// starPx and starSx are where to resume after the last *, with sx one
+	// further along each time the bytes after it fail to match.
+	starPx, starSx := -1, 0
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				starPx, starSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			case '[':
+				if sx < len(s) {
+					if n, ok := matchClass(pattern[px:], s[sx]); ok {
+						px += n
+						sx++
+						continue
+					}
+				}
+			default:
+				n := 1
+				if c == '\\' && px+1 < len(pattern) {
+					c, n = pattern[px+1], 2
+				}
+				if sx < len(s) && s[sx] == c {
+					px += n
+					sx++
+					continue
+				}
+			}
+		}
+		if starPx >= 0 && starSx <= len(s) {
+			px, sx = starPx, starSx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// matchClass matches b against the bracket expression at the start of p,
+// returning its length. Like Redis, an unterminated class runs to the end
+// of the pattern and reversed ranges are accepted.
+func matchClass(p string, b byte) (int, bool) {
+	i, negate, match := 1, false, false
+	if i < len(p) && p[i] == '^' {
+		negate = true
+		i++
+	}
+	for i < len(p) && p[i] != ']' {
+		switch {
+		case p[i] == '\\' && i+1 < len(p):
+			match = match || p[i+1] == b
+			i += 2
+		case i+2 < len(p) && p[i+1] == '-':
+			lo, hi := p[i], p[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			match = match || lo <= b && b <= hi
+			i += 3
+		default:
+			match = match || p[i] == b
+			i++
+		}
+	}
+	if i < len(p) {
+		i++
+	}
+	return i, match != negate
+}
+
+func writeSimple(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "+%s\r\n", s)
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "-%s\r\n", msg)
+}
+
+func writeInt(w *bufio.Writer, n int64) {
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+func writeBool(w *bufio.Writer, b bool) {
+	if b {
+		writeInt(w, 1)
+	} else {
+		writeInt(w, 0)
+	}
+}
+
+func writeBulk(w *bufio.Writer, b []byte) {
+	fmt.Fprintf(w, "$%d\r\n", len(b))
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+	"github.com/stretchr/testify/assert"
+)
+
+func startRESP(t *testing.T, c *cache.Cache[string, []byte]) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewRESP(c)
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+	t.Cleanup(func() {
+		s.Close()
+		assert.ErrorIs(t, <-done, ErrServerClosed)
+	})
+	return l.Addr().String()
+}
+
+// do sends args as a RESP array and returns the first reply line.
+func (c *client) do(args ...string) string {
+	c.t.Helper()
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	c.send("%s", b.String())
+	return c.line()
+}
+
+func TestRESP(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	cl := dial(t, startRESP(t, c))
+
+	assert.Equal(t, "+PONG", cl.do("PING"))
+	assert.Equal(t, "+OK", cl.do("SET", "k", "hello world"))
+	assert.Equal(t, "$11", cl.do("get", "k"))
+	assert.Equal(t, "hello world", cl.line())
+	assert.Equal(t, "$-1", cl.do("GET", "missing"))
+
+	assert.Equal(t, "$-1", cl.do("SET", "k", "x", "NX"))
+	assert.Equal(t, "+OK", cl.do("SET", "n", "x", "NX"))
+
+	assert.Equal(t, ":1", cl.do("INCR", "counter"))
+	assert.Equal(t, ":11", cl.do("INCRBY", "counter", "10"))
+	assert.Equal(t, ":9", cl.do("DECRBY", "counter", "2"))
+	assert.Equal(t, "-ERR value is not an integer or out of range", cl.do("INCR", "k"))
+
+	assert.Equal(t, "*2", cl.do("KEYS", "[kn]"))
+	got := []string{cl.line(), cl.line(), cl.line(), cl.line()}
+	assert.ElementsMatch(t, []string{"$1", "$1", "k", "n"}, got)
+
+	assert.Equal(t, ":2", cl.do("DEL", "k", "n", "missing"))
+	assert.Equal(t, ":1", cl.do("DBSIZE"))
+	assert.Equal(t, "+OK", cl.do("FLUSHALL"))
+	assert.Equal(t, ":0", cl.do("EXISTS", "counter"))
+
+	assert.Regexp(t, `^\$\d+$`, cl.do("INFO"))
+	info := []string{cl.line(), cl.line(), cl.line(), cl.line(), cl.line(), cl.line(), cl.line(), cl.line()}
+	assert.Equal(t, "# Stats", info[0])
+	assert.Equal(t, "connected_clients:1", info[1])
+	assert.Regexp(t, `^keyspace_hits:\d+$`, info[2])
+	assert.Equal(t, "db0:keys=0", info[6])
+
+	assert.Equal(t, "-ERR unknown command 'NOPE'", cl.do("NOPE"))
+	assert.Equal(t, "-ERR wrong number of arguments for 'get' command", cl.do("GET"))
+
+	cl.send("PING\r\n")
+	assert.Equal(t, "+PONG", cl.line(), "Expected inline commands to work")
+}
+
+func TestRESPConcurrentDel(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	addr := startRESP(t, c)
+	clients := []*client{dial(t, addr), dial(t, addr), dial(t, addr), dial(t, addr)}
+
+	for range 50 {
+		assert.Equal(t, "+OK", clients[0].do("SET", "k", "v"))
+		var wg sync.WaitGroup
+		var deleted atomic.Int64
+		for _, cl := range clients {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if cl.do("DEL", "k") == ":1" {
+					deleted.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int64(1), deleted.Load(), "Expected exactly one DEL to remove the key")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"*", "user/1", true},
+		{"user:*", "user:1/2", true},
+		{"user:*", "users", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "heeeello", true},
+		{"h*llo*", "hello world", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[c-a]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	} {
+		assert.Equal(t, tt.want, globMatch(tt.pattern, tt.s), "%q ~ %q", tt.pattern, tt.s)
+	}
+}
+
+func TestRESPExpiry(t *testing.T) {
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	cl := dial(t, startRESP(t, c))
+
+	assert.Equal(t, ":-2", cl.do("TTL", "k"))
+	assert.Equal(t, "+OK", cl.do("SET", "k", "v", "EX", "100"))
+	assert.Equal(t, ":100", cl.do("TTL", "k"))
+	assert.Equal(t, ":1", cl.do("EXPIRE", "k", "5"))
+	assert.Equal(t, ":5", cl.do("TTL", "k"))
+	assert.Equal(t, ":0", cl.do("EXPIRE", "missing", "5"))
+
+	assert.Equal(t, "+OK", cl.do("SET", "short", "v", "PX", "30"))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, "$-1", cl.do("GET", "short"))
+
+	assert.Equal(t, ":1", cl.do("PEXPIRE", "k", "0"), "Expected a non-positive expiry to delete the key")
+	assert.Equal(t, "$-1", cl.do("GET", "k"))
+}
+
+func TestRESPLimits(t *testing.T) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readCommand(bufio.NewReader(strings.NewReader("*1\r\n$536870912\r\nshort")))
+	runtime.ReadMemStats(&after)
+	assert.Error(t, err)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "Expected announced sizes not to be allocated up front")
+
+	_, err = readCommand(bufio.NewReader(strings.NewReader("*1\r\n$2\r\nabcd\r\n")))
+	assert.ErrorIs(t, err, errProtocol, "Expected bulk strings to end with CRLF")
+
+	c := cache.NewCache[string, []byte](time.Minute)
+	defer c.StopCleanup()
+	cl := dial(t, startRESP(t, c))
+	cl.send("PING %s\r\n", strings.Repeat("x", maxLineLen))
+	assert.Equal(t, "-ERR Protocol error", cl.line(), "Expected long lines to be rejected")
+}
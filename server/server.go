@@ -0,0 +1,141 @@
+// Package server serves a cache over wire protocols spoken by existing
+// clients, so programs in other languages can use an embedded cache as a
+// lightweight drop-in for memcached or Redis.
+package server
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// maxLineLen bounds a command line a client may send, as Redis does for
+// inline commands; the connection is closed after a longer one.
+const maxLineLen = 64 << 10
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("server: closed")
+
+var errLineTooLong = errors.New("server: line too long")
+
+// base tracks the listeners and connections of a server so Close can shut
+// them all down.
+type base struct {
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// serve accepts connections on l and runs handle for each on its own
+// goroutine until l fails or the server is closed.
+func (b *base) serve(l net.Listener, handle func(*bufio.Reader, *bufio.Writer)) error {
+	if !b.track(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer b.untrack(l)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if b.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if !b.trackConn(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer b.untrackConn(conn)
+			handle(bufio.NewReaderSize(conn, maxLineLen), bufio.NewWriter(conn))
+		}()
+	}
+}
+
+func (b *base) track(l net.Listener) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	if b.listeners == nil {
+		b.listeners = make(map[net.Listener]struct{})
+	}
+	b.listeners[l] = struct{}{}
+	return true
+}
+
+func (b *base) untrack(l net.Listener) {
+	b.mu.Lock()
+	delete(b.listeners, l)
+	b.mu.Unlock()
+}
+
+func (b *base) trackConn(c net.Conn) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	if b.conns == nil {
+		b.conns = make(map[net.Conn]struct{})
+	}
+	b.conns[c] = struct{}{}
+	return true
+}
+
+func (b *base) untrackConn(c net.Conn) {
+	b.mu.Lock()
+	delete(b.conns, c)
+	b.mu.Unlock()
+	c.Close()
+}
+
+func (b *base) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// connections returns the number of open client connections.
+func (b *base) connections() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.conns)
+}
+
+// Close stops every listener and closes every connection, waiting for
+// in-flight commands to finish.
+func (b *base) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	for l := range b.listeners {
+		l.Close()
+	}
+	for c := range b.conns {
+		c.Close()
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+	return nil
+}
+
+// readLine reads a line without its line ending, failing with errLineTooLong
+// rather than buffering a line longer than r's buffer.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errLineTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
@@ -0,0 +1,26 @@
+module github.com/NikoMalik/MemoryCache/sessions/gorillasessions
+
+go 1.22.5
+
+require (
+	github.com/NikoMalik/MemoryCache v0.0.0-20261015125824-b7bb35fa1880
+	github.com/gorilla/securecookie v1.1.2
+	github.com/gorilla/sessions v1.2.2
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/alphadose/haxmap v1.4.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/exp v0.0.0-20221031165847-c99f073a8326 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Development builds use the enclosing checkout; users of this module get
+// the version required above.
+replace github.com/NikoMalik/MemoryCache => ../..
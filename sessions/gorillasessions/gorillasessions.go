@@ -0,0 +1,89 @@
+// Package gorillasessions implements a github.com/gorilla/sessions backend
+// on top of the cache-backed session Store.
+package gorillasessions
+
+import (
+	"maps"
+	"net/http"
+	"time"
+
+	cachesessions "github.com/NikoMalik/MemoryCache/sessions"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// Store implements sessions.Store from github.com/gorilla/sessions. The
+// cookie only carries the signed session ID; values stay in the cache.
+type Store struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options
+	store   *cachesessions.Store[map[any]any]
+}
+
+// New returns a store whose sessions expire after being idle for ttl.
+// keyPairs are passed to securecookie.CodecsFromPairs to sign, and
+// optionally encrypt, the session ID cookie.
+func New(ttl time.Duration, keyPairs ...[]byte) *Store {
+	return &Store{
+		Codecs:  securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{Path: "/", MaxAge: int(ttl / time.Second), HttpOnly: true},
+		store:   cachesessions.NewStore[map[any]any](ttl),
+	}
+}
+
+// Close stops the cache's cleanup routine.
+func (s *Store) Close() {
+	s.store.Close()
+}
+
+// Get returns the named session for r, caching it in the request's registry.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the session identified by r's cookie, or a new session if the
+// cookie is missing or the session has expired.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	if err := securecookie.DecodeMulti(name, cookie.Value, &session.ID, s.Codecs...); err != nil {
+		return session, err
+	}
+	if values, ok := s.store.Get(session.ID); ok {
+		session.Values = maps.Clone(values)
+		session.IsNew = false
+	}
+	return session, nil
+}
+
+// Save stores the session values and sets the ID cookie. A negative MaxAge
+// destroys the session and deletes the cookie.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			s.store.Destroy(session.ID)
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+	if session.ID == "" {
+		session.ID = cachesessions.NewID()
+	}
+	if err := s.store.Save(session.ID, maps.Clone(session.Values)); err != nil {
+		return err
+	}
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+var _ sessions.Store = (*Store)(nil)
@@ -0,0 +1,44 @@
+package gorillasessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	s := New(time.Minute, []byte("0123456789abcdef0123456789abcdef"))
+	defer s.Close()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := s.Get(r, "sid")
+	assert.NoError(t, err)
+	assert.True(t, session.IsNew)
+	session.Values["user"] = "alice"
+	w := httptest.NewRecorder()
+	assert.NoError(t, session.Save(r, w))
+	cookie := w.Result().Cookies()[0]
+	assert.NotContains(t, cookie.Value, "alice", "Expected values to stay on the server")
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	session, err = s.Get(r, "sid")
+	assert.NoError(t, err)
+	assert.False(t, session.IsNew)
+	assert.Equal(t, "alice", session.Values["user"])
+
+	session.Options.MaxAge = -1
+	assert.NoError(t, session.Save(r, httptest.NewRecorder()))
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	session, _ = s.Get(r, "sid")
+	assert.True(t, session.IsNew, "Expected a destroyed session to be gone")
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "sid", Value: "forged"})
+	_, err = s.Get(r, "sid")
+	assert.Error(t, err, "Expected unsigned cookies to be rejected")
+}
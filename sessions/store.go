@@ -0,0 +1,56 @@
+// Package sessions stores server-side web sessions in a cache. The
+// gorillasessions module adapts Store to github.com/gorilla/sessions.
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// Store keeps session data of type V under random IDs. Expiration is rolling:
+// every Get pushes the deadline of the session back by the store's TTL, so
+// only idle sessions expire.
+type Store[V any] struct {
+	cache *cache.Cache[string, V]
+	ttl   time.Duration
+}
+
+func NewStore[V any](ttl time.Duration) *Store[V] {
+	return &Store[V]{cache: cache.NewCache[string, V](ttl), ttl: ttl}
+}
+
+// Close stops the cache's cleanup routine.
+func (s *Store[V]) Close() {
+	s.cache.StopCleanup()
+}
+
+// Get returns the data of session id and extends its lifetime.
+func (s *Store[V]) Get(id string) (V, bool) {
+	value, ok := s.cache.Get(id)
+	if ok {
+		s.cache.Expire(id, s.ttl)
+	}
+	return value, ok
+}
+
+// Save stores value as the data of session id.
+func (s *Store[V]) Save(id string, value V) error {
+	return s.cache.SetWithTTL(id, value, s.ttl)
+}
+
+// Destroy ends session id.
+func (s *Store[V]) Destroy(id string) {
+	s.cache.Delete(id)
+}
+
+// NewID returns a random session ID suitable for use in a cookie.
+func NewID() string {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
@@ -0,0 +1,31 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreRollingExpiration(t *testing.T) {
+	s := NewStore[string](100 * time.Millisecond)
+	defer s.Close()
+
+	id := NewID()
+	assert.NotEqual(t, id, NewID())
+	assert.NoError(t, s.Save(id, "alice"))
+	for range 4 {
+		time.Sleep(40 * time.Millisecond)
+		user, ok := s.Get(id)
+		assert.True(t, ok, "Expected reads to keep the session alive")
+		assert.Equal(t, "alice", user)
+	}
+	time.Sleep(150 * time.Millisecond)
+	_, ok := s.Get(id)
+	assert.False(t, ok, "Expected an idle session to expire")
+
+	s.Save(id, "bob")
+	s.Destroy(id)
+	_, ok = s.Get(id)
+	assert.False(t, ok)
+}
@@ -0,0 +1,158 @@
+// Package simulate replays key access traces against caches with different
+// eviction policies and capacities and reports their hit ratios, so both can
+// be chosen offline before changing a production configuration.
+package simulate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+// Op is the kind of operation an Access records.
+type Op uint8
+
+const (
+	// OpGet is a read. Misses are followed by a write of the key, as with
+	// GetOrLoad.
+	OpGet Op = iota
+	OpSet
+	OpDelete
+)
+
+// Access is one operation of a trace. Keys only need to be distinct, so
+// traces usually hold key hashes.
+type Access struct {
+	Op  Op
+	Key uint64
+}
+
+// Config is a cache configuration to simulate.
+type Config struct {
+	Policy   cache.EvictionPolicy
+	Capacity int
+}
+
+// Result reports how a configuration fared on a trace.
+type Result struct {
+	Config Config
+	Reads  uint64
+	Hits   uint64
+}
+
+// HitRatio returns the fraction of reads that hit, or 0 without reads.
+func (r Result) HitRatio() float64 {
+	if r.Reads == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Reads)
+}
+
+// Configs returns every combination of policies and capacities.
+func Configs(policies []cache.EvictionPolicy, capacities []int) []Config {
+	configs := make([]Config, 0, len(policies)*len(capacities))
+	for _, p := range policies {
+		for _, n := range capacities {
+			configs = append(configs, Config{Policy: p, Capacity: n})
+		}
+	}
+	return configs
+}
+
+// Run replays trace against a cache for each of configs, concurrently, and
+// returns the results in the order of configs. Entries never expire during a
+// simulation; only capacity limits remove them.
+func Run(trace []Access, configs []Config) []Result {
+	results := make([]Result, len(configs))
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = replay(trace, cfg)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func replay(trace []Access, cfg Config) Result {
+	c := cache.NewCache[uint64, struct{}](24*time.Hour,
+		cache.WithMaxEntries[uint64, struct{}](cfg.Capacity),
+		cache.WithEvictionPolicy[uint64, struct{}](cfg.Policy),
+	)
+	defer c.StopCleanup()
+	r := Result{Config: cfg}
+	for _, a := range trace {
+		switch a.Op {
+		case OpGet:
+			r.Reads++
+			if _, ok := c.Get(a.Key); ok {
+				r.Hits++
+			} else {
+				c.Set(a.Key, struct{}{})
+			}
+		case OpSet:
+			c.Set(a.Key, struct{}{})
+		case OpDelete:
+			c.Delete(a.Key)
+		}
+	}
+	return r
+}
+
+// ReadKeys reads a trace of reads from r, one key per line.
+func ReadKeys(r io.Reader) ([]Access, error) {
+	seed := maphash.MakeSeed()
+	var trace []Access
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		trace = append(trace, Access{Op: OpGet, Key: maphash.Bytes(seed, s.Bytes())})
+	}
+	return trace, s.Err()
+}
+
+// ReadTrace reads a trace written by cache.WithTraceRecorder. Hits and misses
+// both become OpGet, since the simulated caches decide for themselves.
+func ReadTrace(r io.Reader) ([]Access, error) {
+	var trace []Access
+	tr := cache.NewTraceReader(r)
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return trace, nil
+		}
+		if err != nil {
+			return trace, err
+		}
+		a := Access{Key: rec.Key}
+		switch rec.Op {
+		case cache.TraceHit, cache.TraceMiss:
+			a.Op = OpGet
+		case cache.TraceSet:
+			a.Op = OpSet
+		case cache.TraceDelete:
+			a.Op = OpDelete
+		default:
+			continue
+		}
+		trace = append(trace, a)
+	}
+}
+
+// WriteReport writes results to w as a table.
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "policy\tcapacity\treads\thits\thit ratio\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f%%\t\n", r.Config.Policy, r.Config.Capacity, r.Reads, r.Hits, 100*r.HitRatio())
+	}
+	return tw.Flush()
+}
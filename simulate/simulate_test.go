@@ -0,0 +1,80 @@
+package simulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+func TestRun(t *testing.T) {
+	var cyclic []Access
+	for range 10 {
+		for key := range uint64(20) {
+			cyclic = append(cyclic, Access{Op: OpGet, Key: key})
+		}
+	}
+	results := Run(cyclic, Configs([]cache.EvictionPolicy{cache.EvictLRU}, []int{10, 20}))
+	assert.Len(t, results, 2)
+	assert.Equal(t, Config{Policy: cache.EvictLRU, Capacity: 10}, results[0].Config)
+	assert.Equal(t, uint64(200), results[0].Reads)
+	assert.Zero(t, results[0].Hits, "Expected a cyclic scan larger than the cache to defeat LRU")
+	assert.Equal(t, uint64(180), results[1].Hits, "Expected only cold misses with enough capacity")
+	assert.InDelta(t, 0.9, results[1].HitRatio(), 1e-9)
+
+	// Hot keys read twice in a row, separated by scans of new keys.
+	var scans []Access
+	next := uint64(100)
+	for range 50 {
+		for key := range uint64(5) {
+			scans = append(scans, Access{OpGet, key}, Access{OpGet, key})
+		}
+		for range 10 {
+			scans = append(scans, Access{OpGet, next})
+			next++
+		}
+	}
+	results = Run(scans, Configs([]cache.EvictionPolicy{cache.EvictLRU, cache.EvictSLRU}, []int{10}))
+	assert.Greater(t, results[1].Hits, results[0].Hits, "Expected SLRU to resist the scans")
+}
+
+func TestRunWritesAndDeletes(t *testing.T) {
+	trace := []Access{{OpSet, 1}, {OpGet, 1}, {OpDelete, 1}, {OpGet, 1}, {OpGet, 1}}
+	r := Run(trace, []Config{{Policy: cache.EvictLRU, Capacity: 10}})[0]
+	assert.Equal(t, uint64(3), r.Reads)
+	assert.Equal(t, uint64(2), r.Hits)
+}
+
+func TestReadTrace(t *testing.T) {
+	var buf bytes.Buffer
+	c := cache.NewCache[string, int](time.Minute, cache.WithTraceRecorder[string, int](&buf, 1))
+	c.GetOrLoad("a", func(string) (int, error) { return 1, nil })
+	c.Get("a")
+	c.Delete("a")
+	c.StopCleanup()
+
+	trace, err := ReadTrace(&buf)
+	assert.NoError(t, err)
+	var ops []Op
+	for _, a := range trace {
+		ops = append(ops, a.Op)
+	}
+	assert.Equal(t, []Op{OpGet, OpSet, OpGet, OpDelete}, ops)
+}
+
+func TestReadKeysAndReport(t *testing.T) {
+	trace, err := ReadKeys(strings.NewReader("a\nb\na\n"))
+	assert.NoError(t, err)
+	assert.Len(t, trace, 3)
+	assert.Equal(t, trace[0].Key, trace[2].Key)
+	assert.NotEqual(t, trace[0].Key, trace[1].Key)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReport(&buf, Run(trace, []Config{{Policy: cache.EvictClock, Capacity: 2}})))
+	assert.Contains(t, buf.String(), "clock")
+	assert.Contains(t, buf.String(), "33.33%")
+}
@@ -0,0 +1,90 @@
+package cache
+
+// slruProtectedShare is the percentage of tracked keys the protected segment
+// of slruPolicy may hold.
+const slruProtectedShare = 80
+
+// slruPolicy implements EvictSLRU. New keys enter a probation segment and move
+// to a protected segment when hit again. Before choosing a victim, the
+// protected segment is cut back to slruProtectedShare percent of the keys by
+// demoting its least recently used keys to probation. Victims come from probation first, so a scan of
+// keys read once cannot flush the keys that are read repeatedly.
+type slruPolicy[T comparable] struct {
+	nodes     map[T]*lruNode[T]
+	probation lruList[T]
+	protected lruList[T]
+}
+
+func newSLRUPolicy[T comparable]() *slruPolicy[T] {
+	p := &slruPolicy[T]{nodes: make(map[T]*lruNode[T])}
+	p.probation.init()
+	p.protected.init()
+	return p
+}
+
+func (p *slruPolicy[T]) add(key T) {
+	if _, ok := p.nodes[key]; ok {
+		p.access(key)
+		return
+	}
+	n := &lruNode[T]{key: key}
+	p.nodes[key] = n
+	p.probation.pushFront(n)
+}
+
+func (p *slruPolicy[T]) access(key T) {
+	n, ok := p.nodes[key]
+	if !ok {
+		return
+	}
+	if n.protected {
+		p.protected.moveToFront(n)
+		return
+	}
+	p.probation.unlink(n)
+	n.protected = true
+	p.protected.pushFront(n)
+}
+
+func (p *slruPolicy[T]) remove(key T) {
+	if n, ok := p.nodes[key]; ok {
+		p.segment(n).unlink(n)
+		delete(p.nodes, key)
+	}
+}
+
+func (p *slruPolicy[T]) segment(n *lruNode[T]) *lruList[T] {
+	if n.protected {
+		return &p.protected
+	}
+	return &p.probation
+}
+
+func (p *slruPolicy[T]) victim() (T, bool) {
+	for p.protected.n*100 > len(p.nodes)*slruProtectedShare {
+		demoted := p.protected.back()
+		p.protected.unlink(demoted)
+		demoted.protected = false
+		p.probation.pushFront(demoted)
+	}
+	n := p.probation.back()
+	if n == nil {
+		n = p.protected.back()
+	}
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	p.segment(n).unlink(n)
+	delete(p.nodes, n.key)
+	return n.key, true
+}
+
+func (p *slruPolicy[T]) has(key T) bool {
+	_, ok := p.nodes[key]
+	return ok
+}
+
+func (p *slruPolicy[T]) len() int {
+	return len(p.nodes)
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLRUPolicy(t *testing.T) {
+	p := newSLRUPolicy[string]()
+	for _, key := range []string{"a", "b", "c"} {
+		p.add(key)
+	}
+	p.access("a")
+	victim, ok := p.victim()
+	assert.True(t, ok)
+	assert.Equal(t, "b", victim, "Expected probation keys to be evicted first")
+	victim, _ = p.victim()
+	assert.Equal(t, "c", victim)
+	victim, _ = p.victim()
+	assert.Equal(t, "a", victim)
+	_, ok = p.victim()
+	assert.False(t, ok)
+}
+
+func TestCacheEvictSLRUScanResistance(t *testing.T) {
+	for _, tc := range []struct {
+		policy EvictionPolicy
+		kept   bool
+	}{
+		{EvictLRU, false},
+		{EvictSLRU, true},
+	} {
+		cache := NewCache[int, int](time.Minute,
+			WithMaxEntries[int, int](10),
+			WithEvictionPolicy[int, int](tc.policy),
+		)
+		for i := range 5 {
+			cache.Set(i, i)
+			cache.Get(i)
+		}
+		for i := 100; i < 200; i++ {
+			cache.Set(i, i)
+		}
+		kept := 0
+		for i := range 5 {
+			if _, ok := cache.Get(i); ok {
+				kept++
+			}
+		}
+		assert.Equal(t, tc.kept, kept == 5, tc.policy.String())
+		cache.StopCleanup()
+	}
+}
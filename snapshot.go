@@ -0,0 +1,66 @@
+package cache
+
+// snapshotAttempts bounds how often Snapshot and Clone retry a copy that
+// raced with writers.
+const snapshotAttempts = 4
+
+// copyItems copies the store, retrying while writes race the copy. If writes
+// keep racing, the last attempt is returned; every item in it was then live
+// at some point during the call.
+func (c *Cache[T, V]) copyItems() map[T]CachedItem[V] {
+	var items map[T]CachedItem[V]
+	for i := 0; i < snapshotAttempts; i++ {
+		if tc := c.committing.Load(); tc != nil {
+			<-tc.done
+		}
+		before := c.writes.Load()
+		items = make(map[T]CachedItem[V], c.items().Len())
+		c.items().ForEach(func(key T, item CachedItem[V]) bool {
+			items[key] = item
+			return true
+		})
+		if c.writes.Load() == before {
+			break
+		}
+	}
+	return items
+}
+
+// Snapshot returns a point-in-time copy of the live entries. Writes that
+// keep racing the copy can make it a best-effort one; see copyItems.
+func (c *Cache[T, V]) Snapshot() map[T]V {
+	now := c.now()
+	items := c.copyItems()
+	values := make(map[T]V, len(items))
+	for key, item := range items {
+		if c.expired(key, item, now) {
+			continue
+		}
+		if value, ok := c.value(item); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// Clone returns an independent cache holding a point-in-time copy of c's
+// entries with their deadlines, and c's TTL and in-memory options as for
+// Namespace. Call StopCleanup on the clone when done with it.
+func (c *Cache[T, V]) Clone() *Cache[T, V] {
+	clone := newCache(c.ttl, c.hasher, c.storeFactory, []Option[T, V]{c.inherit})
+	for key, item := range c.copyItems() {
+		if item.blob.seq != 0 {
+			value, ok := c.value(item)
+			if !ok {
+				continue
+			}
+			fresh := clone.newItem(value, item.source)
+			item.Value, item.blob = fresh.Value, fresh.blob
+		}
+		if item.access != nil {
+			item.access = item.access.clone()
+		}
+		clone.store(key, item, nil)
+	}
+	return clone
+}
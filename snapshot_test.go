@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheSnapshot(t *testing.T) {
+	cache := NewCache[int, int](time.Minute)
+	defer cache.StopCleanup()
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i)
+	}
+	snap := cache.Snapshot()
+	cache.Set(0, -1)
+	assert.Len(t, snap, 100)
+	assert.Equal(t, 0, snap[0], "Expected the snapshot not to see later writes")
+}
+
+func TestCacheSnapshotConsistent(t *testing.T) {
+	cache := NewCache[int, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set(0, 0)
+	cache.Set(1, 0)
+
+	// The keys sum to zero except in the brief window between a writer's two
+	// Sets, so a torn copy is the only common way to see a non-zero sum.
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cache.Set(0, i)
+			cache.Set(1, -i)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	consistent := 0
+	for i := 0; i < 200; i++ {
+		snap := cache.Snapshot()
+		if snap[0]+snap[1] == 0 {
+			consistent++
+		}
+	}
+	close(stop)
+	wg.Wait()
+	assert.Greater(t, consistent, 150)
+}
+
+func TestCacheClone(t *testing.T) {
+	cache := NewCache[string, []int](time.Minute, WithByteStorage[string, []int](GobCodec[[]int]{}))
+	defer cache.StopCleanup()
+	cache.Set("k", []int{1, 2})
+	assert.NoError(t, cache.SetWithTTL("short", []int{3}, time.Hour))
+
+	clone := cache.Clone()
+	defer clone.StopCleanup()
+	cache.Clear()
+
+	value, found := clone.Get("k")
+	assert.True(t, found, "Expected the clone to be independent")
+	assert.Equal(t, []int{1, 2}, value)
+	ttl, _ := clone.TTL("short")
+	assert.Greater(t, ttl, 59*time.Minute, "Expected the clone to keep deadlines")
+}
+
+func TestCacheCloneAccessStats(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithAccessTracking[string, int]())
+	defer cache.StopCleanup()
+	cache.Set("k", 1)
+	cache.Get("k")
+
+	clone := cache.Clone()
+	defer clone.StopCleanup()
+	clone.Get("k")
+	clone.Get("k")
+	info, _ := cache.Inspect("k")
+	assert.Equal(t, uint64(1), info.Hits, "Expected reads of the clone not to count for the original")
+	info, _ = clone.Inspect("k")
+	assert.Equal(t, uint64(3), info.Hits)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// SnapshotStore keeps snapshots somewhere that outlives the machine, such as
+// object storage; see the s3snapshot package. Names sort in the order the
+// snapshots were taken.
+type SnapshotStore interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	List(ctx context.Context) ([]string, error)
+}
+
+// ErrNoSnapshot is returned by RestoreLatest when there is nothing to
+// restore.
+var ErrNoSnapshot = errors.New("cache: no snapshot to restore")
+
+// snapshotNameLayout gives names that sort chronologically.
+const snapshotNameLayout = "snapshot-20060102T150405.000000000Z"
+
+type uploader struct {
+	store    SnapshotStore
+	interval time.Duration
+	lastErr  atomic.Pointer[error]
+	// failing reports whether the latest attempt failed.
+	failing atomic.Bool
+}
+
+func (u *uploader) record(err error) {
+	u.failing.Store(err != nil)
+	if err != nil {
+		u.lastErr.Store(&err)
+	}
+}
+
+// UploadSnapshot writes a snapshot, as SaveTo would, to the store configured
+// with WithSnapshotUploads and returns its name.
+func (c *Cache[T, V]) UploadSnapshot(ctx context.Context) (string, error) {
+	if c.uploads == nil {
+		return "", ErrNoSnapshot
+	}
+	name := c.wallNow().UTC().Format(snapshotNameLayout)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.SaveTo(pw))
+	}()
+	err := c.uploads.store.Put(ctx, name, pr)
+	// Unblock SaveTo if Put gave up early.
+	pr.Close()
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// RestoreLatest loads the newest snapshot in the store configured with
+// WithSnapshotUploads, as LoadFrom would. It returns ErrNoSnapshot if the
+// store holds none.
+func (c *Cache[T, V]) RestoreLatest(ctx context.Context) error {
+	if c.uploads == nil {
+		return ErrNoSnapshot
+	}
+	names, err := c.uploads.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return ErrNoSnapshot
+	}
+	r, err := c.uploads.store.Get(ctx, slices.Max(names))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return c.LoadFrom(r)
+}
+
+func (c *Cache[T, V]) runUploads() {
+	ticker := c.newTicker(c.uploads.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			_, err := c.UploadSnapshot(context.Background())
+			c.uploads.record(err)
+		case <-c.stopCleanup:
+			_, err := c.UploadSnapshot(context.Background())
+			c.uploads.record(err)
+			return
+		}
+	}
+}
+
+// LastUploadError returns the most recent error from scheduled snapshot
+// uploads, or nil.
+func (c *Cache[T, V]) LastUploadError() error {
+	if c.uploads == nil {
+		return nil
+	}
+	if err := c.uploads.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memSnapshotStore struct {
+	mu        sync.Mutex
+	snapshots map[string][]byte
+}
+
+func (s *memSnapshotStore) Put(ctx context.Context, name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[name] = data
+	return nil
+}
+
+func (s *memSnapshotStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(s.snapshots[name])), nil
+}
+
+func (s *memSnapshotStore) List(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range s.snapshots {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func TestCacheUploadAndRestoreLatest(t *testing.T) {
+	store := &memSnapshotStore{snapshots: map[string][]byte{}}
+	ctx := context.Background()
+
+	src := NewCache[string, int](time.Minute, WithSnapshotUploads[string, int](store, time.Hour))
+	defer src.StopCleanup()
+	src.Set("a", 1)
+	first, err := src.UploadSnapshot(ctx)
+	assert.NoError(t, err)
+	src.Set("a", 2)
+	second, err := src.UploadSnapshot(ctx)
+	assert.NoError(t, err)
+	assert.Less(t, first, second, "Expected names to sort chronologically")
+
+	dst := NewCache[string, int](time.Minute, WithSnapshotUploads[string, int](&memSnapshotStore{snapshots: map[string][]byte{}}, time.Hour))
+	defer dst.StopCleanup()
+	assert.ErrorIs(t, dst.RestoreLatest(ctx), ErrNoSnapshot)
+
+	dst.uploads.store = store
+	assert.NoError(t, dst.RestoreLatest(ctx))
+	value, _ := dst.Get("a")
+	assert.Equal(t, 2, value, "Expected the newest snapshot to be restored")
+}
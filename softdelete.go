@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type trashed[V any] struct {
+	item  CachedItem[V]
+	until time.Time
+}
+
+type trash[T comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[T]trashed[V]
+}
+
+// SoftDelete hides key from reads while keeping it restorable with Undelete
+// for the grace period. It reports whether the key was present.
+func (c *Cache[T, V]) SoftDelete(key T, grace time.Duration) bool {
+	key = c.canonical(key)
+	var item CachedItem[V]
+	ok := c.loggedIf(aofDelete, key, *new(V), 0, func() bool {
+		c.writes.Add(1)
+		var ok bool
+		item, ok = c.items().GetAndDel(key)
+		c.writes.Add(1)
+		return ok
+	})
+	if !ok {
+		return false
+	}
+	c.forget(key)
+	c.notify(EventDelete, key, item, nil)
+	c.trash.mu.Lock()
+	if c.trash.entries == nil {
+		c.trash.entries = make(map[T]trashed[V])
+	}
+	c.trash.entries[key] = trashed[V]{item: item, until: c.wallNow().Add(grace)}
+	c.trash.mu.Unlock()
+	return true
+}
+
+// Undelete restores a soft-deleted key if its grace period has not elapsed and
+// the key has not been set again in the meantime. SoftDelete is logged and
+// replicated as a Delete and Undelete as a Set with the remaining TTL, so
+// the trash itself does not survive a restart.
+func (c *Cache[T, V]) Undelete(key T) bool {
+	key = c.canonical(key)
+	c.trash.mu.Lock()
+	e, ok := c.trash.entries[key]
+	delete(c.trash.entries, key)
+	c.trash.mu.Unlock()
+	if !ok || c.wallNow().After(e.until) {
+		return false
+	}
+	value, ok := c.value(e.item)
+	if !ok {
+		return false
+	}
+	ttl := max(time.Duration(e.item.expires-c.now()), time.Nanosecond)
+	return c.loggedIf(aofSet, key, value, ttl, func() bool {
+		_, ok := c.reinsert(key, e.item)
+		return ok
+	})
+}
+
+func (c *Cache[T, V]) purgeTrash(now time.Time) {
+	c.trash.mu.Lock()
+	for key, e := range c.trash.entries {
+		if now.After(e.until) {
+			delete(c.trash.entries, key)
+		}
+	}
+	c.trash.mu.Unlock()
+}
+
+func (c *Cache[T, V]) emptyTrash() {
+	c.trash.mu.Lock()
+	c.trash.entries = nil
+	c.trash.mu.Unlock()
+}
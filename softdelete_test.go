@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheSoftDeleteAndUndelete(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	cache.Set(1, "test1")
+
+	assert.True(t, cache.SoftDelete(1, time.Minute))
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected soft-deleted key to be hidden")
+
+	assert.True(t, cache.Undelete(1))
+	value, found := cache.Get(1)
+	assert.True(t, found, "Expected key to be restored")
+	assert.Equal(t, "test1", value)
+
+	assert.False(t, cache.SoftDelete(2, time.Minute))
+	assert.False(t, cache.Undelete(2))
+}
+
+func TestCacheUndeleteAfterGrace(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	cache.Set(1, "test1")
+
+	cache.SoftDelete(1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, cache.Undelete(1), "Expected undelete to fail after the grace window")
+	_, found := cache.Get(1)
+	assert.False(t, found)
+}
+
+func TestCacheUndeleteKeepsNewerValue(t *testing.T) {
+	cache := NewCache[int, string](time.Minute)
+	cache.Set(1, "old")
+
+	cache.SoftDelete(1, time.Minute)
+	cache.Set(1, "new")
+	assert.False(t, cache.Undelete(1))
+
+	value, _ := cache.Get(1)
+	assert.Equal(t, "new", value)
+}
+
+func TestCacheUndeleteIndexes(t *testing.T) {
+	cache := NewCache[string, string](time.Minute,
+		WithIndex[string, string]("v", func(v string) string { return v }),
+		WithHierarchy[string, string]("/"),
+	)
+	defer cache.StopCleanup()
+	cache.Set("a/1", "A")
+	_, version, _ := cache.GetVersioned("a/1")
+
+	cache.SoftDelete("a/1", time.Minute)
+	assert.Empty(t, cache.GetByIndex("v", "A"))
+	assert.True(t, cache.Undelete("a/1"))
+	assert.Equal(t, []string{"A"}, cache.GetByIndex("v", "A"), "Expected undeleted entries to be indexed")
+	_, restored, _ := cache.GetVersioned("a/1")
+	assert.Greater(t, restored, version, "Expected Undelete to count as a write")
+	assert.Equal(t, 1, DeleteSubtree(cache, "a"))
+}
@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpillStore holds the entries WithSpill moves out of memory on eviction.
+// Values arrive encoded by the cache's codec. Implementations must be safe for
+// concurrent use; an adapter over badger or bbolt only needs to encode keys.
+type SpillStore[T comparable] interface {
+	// Put stores data under key, replacing any previous entry. A store that
+	// is full may drop the entry instead.
+	Put(key T, data []byte, expires time.Time) error
+	// Take removes and returns the entry stored under key.
+	Take(key T) (data []byte, expires time.Time, ok bool, err error)
+	Delete(key T) error
+	Clear() error
+	Close() error
+}
+
+type spiller[T comparable] struct {
+	store   SpillStore[T]
+	lastErr atomic.Pointer[error]
+}
+
+func (s *spiller[T]) record(err error) {
+	if err != nil {
+		s.lastErr.Store(&err)
+	}
+}
+
+// spill writes an evicted item to the spill store.
+func (c *Cache[T, V]) spill(key T, item CachedItem[V]) {
+	value, ok := c.value(item)
+	if !ok {
+		return
+	}
+	data, err := c.valueCodec().Encode(value)
+	if err != nil {
+		c.stats.codecErrors.Add(1)
+		c.spiller.record(err)
+		return
+	}
+	if c.sealer != nil {
+		data = c.sealer.seal(data, nil)
+	}
+	expires := c.wallNow().Add(time.Duration(item.expires - c.now()))
+	c.spiller.record(c.spiller.store.Put(key, data, expires))
+}
+
+// unspill drops the spilled copy of key once memory holds a newer one.
+func (c *Cache[T, V]) unspill(key T) {
+	c.spiller.record(c.spiller.store.Delete(key))
+}
+
+// recall moves the entry under key back from the spill store into memory.
+// A concurrent Set wins over the spilled value.
+func (c *Cache[T, V]) recall(key T) (CachedItem[V], bool) {
+	data, expires, ok, err := c.spiller.store.Take(key)
+	c.spiller.record(err)
+	remaining := expires.Sub(c.wallNow())
+	if !ok || remaining <= 0 {
+		return CachedItem[V]{}, false
+	}
+	if c.sealer != nil {
+		if data, err = c.sealer.open(data, nil); err != nil {
+			c.spiller.record(err)
+			return CachedItem[V]{}, false
+		}
+	}
+	value, err := c.valueCodec().Decode(data)
+	if err != nil {
+		c.stats.codecErrors.Add(1)
+		c.spiller.record(err)
+		return CachedItem[V]{}, false
+	}
+	item := c.newItem(value, SourceSnapshot)
+	item.expires, item.explicit = c.now()+int64(remaining), true
+	item, _ = c.reinsert(key, item)
+	return item, true
+}
+
+// LastSpillError returns the most recent error from writing to or reading
+// from the spill store, or nil.
+func (c *Cache[T, V]) LastSpillError() error {
+	if c.spiller == nil {
+		return nil
+	}
+	if err := c.spiller.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+type spillSlot struct {
+	off     int64
+	n       int64
+	expires int64
+}
+
+// FileSpill is a SpillStore that appends values to a single file and keeps
+// their offsets in an in-memory index. Space taken by replaced, deleted and
+// expired values is reclaimed by rewriting the file once it outweighs the
+// live data. Keys are not persisted, so the file is only scratch space and is
+// truncated when opened.
+type FileSpill[T comparable] struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	f        *os.File
+	size     int64
+	live     int64
+	index    map[T]spillSlot
+	// earliest is at most the earliest deadline in index, so Put only looks
+	// for expired values once one may have expired.
+	earliest int64
+}
+
+// minSpillCompaction keeps small spill files from being rewritten over and
+// over.
+const minSpillCompaction = 1 << 20
+
+// NewFileSpill creates or truncates the spill file at path. Once maxBytes of
+// live values are stored, Put drops further entries; zero means no limit.
+func NewFileSpill[T comparable](path string, maxBytes int64) (*FileSpill[T], error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSpill[T]{path: path, maxBytes: maxBytes, f: f, index: make(map[T]spillSlot), earliest: math.MaxInt64}, nil
+}
+
+func (s *FileSpill[T]) Put(key T, data []byte, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return os.ErrClosed
+	}
+	s.drop(key)
+	if now := time.Now().UnixNano(); now >= s.earliest {
+		s.dropExpired(now)
+	}
+	if s.maxBytes > 0 && s.live+int64(len(data)) > s.maxBytes {
+		return nil
+	}
+	if err := s.maybeCompact(); err != nil {
+		return err
+	}
+	if _, err := s.f.WriteAt(data, s.size); err != nil {
+		return err
+	}
+	s.index[key] = spillSlot{off: s.size, n: int64(len(data)), expires: expires.UnixNano()}
+	s.size += int64(len(data))
+	s.live += int64(len(data))
+	s.earliest = min(s.earliest, expires.UnixNano())
+	return nil
+}
+
+func (s *FileSpill[T]) Take(key T) ([]byte, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.index[key]
+	if !ok {
+		return nil, time.Time{}, false, nil
+	}
+	s.drop(key)
+	data := make([]byte, slot.n)
+	if _, err := s.f.ReadAt(data, slot.off); err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return data, time.Unix(0, slot.expires), true, nil
+}
+
+func (s *FileSpill[T]) Delete(key T) error {
+	s.mu.Lock()
+	s.drop(key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *FileSpill[T]) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return os.ErrClosed
+	}
+	s.index = make(map[T]spillSlot)
+	s.size, s.live, s.earliest = 0, 0, math.MaxInt64
+	return s.f.Truncate(0)
+}
+
+// Close closes and removes the spill file.
+func (s *FileSpill[T]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return errors.Join(err, os.Remove(s.path))
+}
+
+// Len returns the number of entries in the spill file.
+func (s *FileSpill[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+func (s *FileSpill[T]) drop(key T) {
+	if slot, ok := s.index[key]; ok {
+		delete(s.index, key)
+		s.live -= slot.n
+	}
+}
+
+// dropExpired stops counting values expired at now as live, which lets
+// maybeCompact reclaim their space and keeps them from filling maxBytes.
+func (s *FileSpill[T]) dropExpired(now int64) {
+	s.earliest = math.MaxInt64
+	for key, slot := range s.index {
+		if slot.expires <= now {
+			s.drop(key)
+		} else {
+			s.earliest = min(s.earliest, slot.expires)
+		}
+	}
+}
+
+// maybeCompact rewrites the file without dead values once they take up more
+// than half of it.
+func (s *FileSpill[T]) maybeCompact() error {
+	if s.size < minSpillCompaction || s.size-s.live < s.live {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	index := make(map[T]spillSlot, len(s.index))
+	var size int64
+	for key, slot := range s.index {
+		if _, err := io.Copy(tmp, io.NewSectionReader(s.f, slot.off, slot.n)); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		slot.off = size
+		index[key] = slot
+		size += slot.n
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.f.Close()
+	s.f, s.index, s.size, s.live = tmp, index, size, size
+	return nil
+}
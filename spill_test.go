@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSpill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill")
+	s, err := NewFileSpill[string](path, 0)
+	assert.NoError(t, err)
+
+	expires := time.Now().Add(time.Hour)
+	assert.NoError(t, s.Put("a", []byte("one"), expires))
+	assert.NoError(t, s.Put("b", []byte("two"), expires))
+	assert.NoError(t, s.Put("a", []byte("three"), expires))
+	assert.Equal(t, 2, s.Len())
+
+	data, got, ok, err := s.Take("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "three", string(data))
+	assert.Equal(t, expires.UnixNano(), got.UnixNano())
+	_, _, ok, _ = s.Take("a")
+	assert.False(t, ok, "Expected Take to remove the entry")
+
+	assert.NoError(t, s.Delete("b"))
+	assert.Zero(t, s.Len())
+
+	assert.NoError(t, s.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Expected Close to remove the spill file")
+}
+
+func TestFileSpillLimitAndCompaction(t *testing.T) {
+	s, err := NewFileSpill[int](filepath.Join(t.TempDir(), "spill"), 3<<20)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	value := make([]byte, 1<<20)
+	expires := time.Now().Add(time.Hour)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, s.Put(i, value, expires))
+	}
+	assert.Equal(t, 3, s.Len(), "Expected entries beyond maxBytes to be dropped")
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, s.Put(0, value, expires))
+	}
+	assert.LessOrEqual(t, s.size, int64(6<<20), "Expected dead values to be compacted away")
+	data, _, ok, err := s.Take(2)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, data, 1<<20)
+}
+
+func TestFileSpillReclaimsExpired(t *testing.T) {
+	s, err := NewFileSpill[int](filepath.Join(t.TempDir(), "spill"), 3<<20)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	value := make([]byte, 1<<20)
+	soon := time.Now().Add(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.Put(i, value, soon))
+	}
+	time.Sleep(20 * time.Millisecond)
+	later := time.Now().Add(time.Hour)
+	for i := 10; i < 20; i++ {
+		assert.NoError(t, s.Put(i, value, later))
+		_, _, ok, err := s.Take(i)
+		assert.NoError(t, err)
+		assert.True(t, ok, "Expected expired values not to count against maxBytes")
+	}
+	assert.LessOrEqual(t, s.size, int64(6<<20), "Expected expired values to be compacted away")
+}
+
+func TestCacheSpill(t *testing.T) {
+	s, err := NewFileSpill[string](filepath.Join(t.TempDir(), "spill"), 0)
+	assert.NoError(t, err)
+	cache := NewCache[string, int](time.Minute, WithMaxEntries[string, int](2), WithSpill[string, int](s))
+	defer cache.StopCleanup()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, 1, s.Len(), "Expected the evicted entry to be spilled")
+
+	value, found := cache.Get("a")
+	assert.True(t, found, "Expected a miss to read the spilled entry back")
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 1, s.Len(), "Expected recalling to spill another entry")
+
+	cache.Set("d", 4)
+	cache.Delete("a")
+	cache.Delete("b")
+	cache.Delete("c")
+	_, found = cache.Get("a")
+	assert.False(t, found, "Expected Delete to drop the spilled copy")
+	assert.Zero(t, s.Len())
+
+	cache.Set("e", 5)
+	cache.Set("f", 6)
+	cache.Clear()
+	assert.Zero(t, s.Len(), "Expected Clear to empty the spill store")
+	assert.NoError(t, cache.LastSpillError())
+}
+
+func TestCacheSpillExpired(t *testing.T) {
+	s, err := NewFileSpill[string](filepath.Join(t.TempDir(), "spill"), 0)
+	assert.NoError(t, err)
+	cache := NewCache[string, int](time.Minute, WithMaxEntries[string, int](1), WithSpill[string, int](s))
+	defer cache.StopCleanup()
+
+	cache.SetWithTTL("a", 1, 20*time.Millisecond)
+	cache.Set("b", 2)
+	time.Sleep(30 * time.Millisecond)
+	_, found := cache.Get("a")
+	assert.False(t, found, "Expected spilled entries to keep their deadline")
+}
+
+func TestCacheSpillRecallIndexes(t *testing.T) {
+	s, err := NewFileSpill[string](filepath.Join(t.TempDir(), "spill"), 0)
+	assert.NoError(t, err)
+	cache := NewCache[string, string](time.Minute,
+		WithMaxEntries[string, string](1),
+		WithSpill[string, string](s),
+		WithIndex[string, string]("v", func(v string) string { return v }),
+		WithOrderedKeys[string, string](),
+		WithHierarchy[string, string]("/"),
+	)
+	defer cache.StopCleanup()
+	cache.Scan(0, 10)
+
+	cache.Set("a/1", "A")
+	cache.Set("b/1", "B")
+	_, found := cache.Get("a/1")
+	assert.True(t, found)
+
+	assert.Equal(t, []string{"A"}, cache.GetByIndex("v", "A"), "Expected recalled entries to be indexed")
+	var keys []string
+	RangeBetween(cache, "a", "z", func(key, _ string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []string{"a/1"}, keys)
+	scanned, _ := cache.Scan(0, 10)
+	assert.Equal(t, []string{"a/1"}, scanned)
+	assert.Equal(t, 1, DeleteSubtree(cache, "a"))
+	assert.Zero(t, cache.Len())
+}
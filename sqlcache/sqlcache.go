@@ -0,0 +1,158 @@
+// Package sqlcache caches the results of database/sql queries.
+package sqlcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+
+	cache "github.com/NikoMalik/MemoryCache"
+)
+
+func init() {
+	gob.Register(time.Time{})
+}
+
+// Result holds the rows of a query as scanned into *any, so values have the
+// types the driver returns: int64, float64, bool, []byte, string, time.Time
+// or nil.
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Queryer is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Cache caches query results encoded with a codec, so every hit returns a
+// fresh copy that callers may modify.
+type Cache struct {
+	cache *cache.Cache[string, Result]
+}
+
+// New returns a Cache whose cleanup routine runs every interval. Results are
+// encoded with gob unless codec is non-nil.
+func New(interval time.Duration, codec cache.Codec[Result]) *Cache {
+	if codec == nil {
+		codec = cache.GobCodec[Result]{}
+	}
+	return &Cache{cache: cache.NewCache[string, Result](interval, cache.WithByteStorage[string, Result](codec))}
+}
+
+// Close stops the cache's cleanup routine.
+func (c *Cache) Close() {
+	c.cache.StopCleanup()
+}
+
+// CachedQuery runs query against db unless a result for the same statement
+// and arguments was cached less than ttl ago. Errors are not cached.
+func (c *Cache) CachedQuery(ctx context.Context, db Queryer, ttl time.Duration, query string, args ...any) (Result, error) {
+	key := Key(query, args...)
+	if res, ok := c.cache.Get(key); ok {
+		return res, nil
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := scan(rows)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := c.cache.SetWithTTL(key, res, ttl); err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// Invalidate drops the cached result of query with args.
+func (c *Cache) Invalidate(query string, args ...any) {
+	c.cache.Delete(Key(query, args...))
+}
+
+// InvalidateQuery drops the cached results of query for every argument list.
+func (c *Cache) InvalidateQuery(query string) int {
+	return cache.DeletePrefix(c.cache, statementHash(query)+":")
+}
+
+// InvalidateAll drops every cached result, for instance after a migration.
+func (c *Cache) InvalidateAll() {
+	c.cache.Clear()
+}
+
+// Key returns the cache key of query with args. It is the hash of the
+// statement followed by the hash of the arguments, so all results of one
+// statement share a prefix. Arguments are hashed by the value the driver
+// receives: pointers are followed and driver.Valuer implementations called,
+// so two pointers to equal values give the same key and a pointer to a value
+// changed since gives a different one.
+func Key(query string, args ...any) string {
+	h := sha256.New()
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			fmt.Fprintf(h, "@%s=", named.Name)
+			arg = named.Value
+		}
+		arg = argValue(arg)
+		fmt.Fprintf(h, "%T:%#v\x00", arg, arg)
+	}
+	return statementHash(query) + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// argValue resolves arg to the value database/sql would pass to the driver.
+func argValue(arg any) any {
+	for {
+		if v, ok := arg.(driver.Valuer); ok {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				return nil
+			}
+			value, err := v.Value()
+			if err != nil {
+				return fmt.Sprintf("error %v", err)
+			}
+			return value
+		}
+		rv := reflect.ValueOf(arg)
+		if rv.Kind() != reflect.Pointer {
+			return arg
+		}
+		if rv.IsNil() {
+			return nil
+		}
+		arg = rv.Elem().Interface()
+	}
+}
+
+func statementHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:16])
+}
+
+func scan(rows *sql.Rows) (Result, error) {
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{Columns: cols}
+	for rows.Next() {
+		row := make([]any, len(cols))
+		dest := make([]any, len(cols))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return Result{}, err
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, rows.Err()
+}
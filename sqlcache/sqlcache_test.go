@@ -0,0 +1,107 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var queries atomic.Int32
+
+// fakeDriver answers every query with two rows echoing its first argument.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	queries.Add(1)
+	return &fakeRows{rows: [][]driver.Value{{int64(1), args[0]}, {int64(2), nil}}}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (*fakeRows) Columns() []string { return []string{"id", "name"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("sqlcache-fake", fakeDriver{})
+}
+
+func TestCachedQuery(t *testing.T) {
+	db, err := sql.Open("sqlcache-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	c := New(time.Minute, nil)
+	defer c.Close()
+	ctx := context.Background()
+	queries.Store(0)
+
+	const q = "SELECT id, name FROM users WHERE name = ?"
+	res, err := c.CachedQuery(ctx, db, time.Minute, q, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, res.Columns)
+	assert.Equal(t, [][]any{{int64(1), "alice"}, {int64(2), nil}}, res.Rows)
+
+	res.Rows[0][1] = "mallory"
+	res, _ = c.CachedQuery(ctx, db, time.Minute, q, "alice")
+	assert.Equal(t, "alice", res.Rows[0][1], "Expected hits to return an unshared copy")
+	assert.Equal(t, int32(1), queries.Load())
+
+	c.CachedQuery(ctx, db, time.Minute, q, "bob")
+	assert.Equal(t, int32(2), queries.Load(), "Expected arguments to be part of the key")
+
+	c.Invalidate(q, "alice")
+	c.CachedQuery(ctx, db, time.Minute, q, "alice")
+	c.CachedQuery(ctx, db, time.Minute, q, "bob")
+	assert.Equal(t, int32(3), queries.Load())
+
+	assert.Equal(t, 2, c.InvalidateQuery(q))
+	c.CachedQuery(ctx, db, time.Minute, q, "bob")
+	assert.Equal(t, int32(4), queries.Load())
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key("q", 1, "a"), Key("q", 1, "a"))
+	assert.NotEqual(t, Key("q", 1), Key("q", "1"), "Expected argument types to be part of the key")
+	assert.NotEqual(t, Key("q", 1), Key("r", 1))
+
+	n := 1
+	assert.Equal(t, Key("q", 1), Key("q", &n), "Expected pointers to be followed")
+	key := Key("q", &n)
+	n = 2
+	assert.NotEqual(t, key, Key("q", &n), "Expected the pointed-to value to be part of the key")
+	assert.Equal(t, Key("q", "x"), Key("q", sql.NullString{String: "x", Valid: true}), "Expected driver.Valuer to be called")
+	assert.Equal(t, Key("q", nil), Key("q", (*sql.NullString)(nil)))
+	assert.NotEqual(t, Key("q", sql.Named("a", 1)), Key("q", sql.Named("b", 1)))
+}
@@ -0,0 +1,25 @@
+package cache
+
+// sweepAt returns when the cleanup routine may delete item: its deadline,
+// extended by the grace period of WithStaleOnError.
+func (c *Cache[T, V]) sweepAt(item CachedItem[V]) int64 {
+	return item.expires + int64(c.maxStale)
+}
+
+// stale returns the value under key if it has expired no more than maxStale
+// ago.
+func (c *Cache[T, V]) stale(key T) (V, bool) {
+	var zero V
+	if c.maxStale <= 0 {
+		return zero, false
+	}
+	item, ok := c.items().Get(key)
+	if !ok || c.now() > c.sweepAt(item) {
+		return zero, false
+	}
+	value, ok := c.value(item)
+	if !ok || !c.validCached(key, value) {
+		return zero, false
+	}
+	return value, true
+}
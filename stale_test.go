@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStaleOnError(t *testing.T) {
+	cache := NewCache[string, int](20*time.Millisecond, WithStaleOnError[string, int](time.Minute), WithExactTime[string, int]())
+	defer cache.StopCleanup()
+
+	ok := func(string) (int, error) { return 1, nil }
+	down := func(string) (int, error) { return 0, errors.New("origin down") }
+	cache.GetOrLoad("k", ok)
+	time.Sleep(60 * time.Millisecond)
+
+	_, found := cache.Get("k")
+	assert.False(t, found, "Expected Get not to serve expired entries")
+	value, err := cache.GetOrLoad("k", down)
+	assert.NoError(t, err, "Expected the stale value to mask the loader failure")
+	assert.Equal(t, 1, value)
+
+	values, err := cache.FetchMany([]string{"k"}, func([]string) (map[string]int, error) {
+		return nil, errors.New("origin down")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"k": 1}, values)
+
+	_, err = cache.GetOrLoad("never", down)
+	assert.Error(t, err, "Expected keys without a stale value to fail")
+	_, err = cache.FetchMany([]string{"k", "never"}, func([]string) (map[string]int, error) {
+		return nil, errors.New("origin down")
+	})
+	assert.Error(t, err)
+}
+
+func TestCacheStaleOnErrorGrace(t *testing.T) {
+	cache := NewCache[string, int](10*time.Millisecond, WithStaleOnError[string, int](20*time.Millisecond), WithExactTime[string, int]())
+	defer cache.StopCleanup()
+
+	cache.GetOrLoad("k", func(string) (int, error) { return 1, nil })
+	time.Sleep(60 * time.Millisecond)
+	_, err := cache.GetOrLoad("k", func(string) (int, error) { return 0, errors.New("origin down") })
+	assert.Error(t, err, "Expected values older than the grace period to be dropped")
+}
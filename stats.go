@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+type Stats struct {
+	Hits               uint64
+	Misses             uint64
+	ValidationFailures uint64
+	BackendErrors      uint64
+	CodecErrors        uint64
+	EventsDropped      uint64
+	Evictions          uint64
+	HooksDropped       uint64
+	Rejections         uint64
+}
+
+// HitRatio returns the fraction of lookups that hit, or 0 before any lookup.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type counters struct {
+	hits               stripedCounter
+	misses             stripedCounter
+	validationFailures stripedCounter
+	backendErrors      stripedCounter
+	codecErrors        stripedCounter
+	eventsDropped      stripedCounter
+	evictions          stripedCounter
+	hooksDropped       stripedCounter
+	rejections         stripedCounter
+}
+
+func (s *counters) init() {
+	n := counterStripes()
+	for _, c := range []*stripedCounter{
+		&s.hits, &s.misses, &s.validationFailures, &s.backendErrors,
+		&s.codecErrors, &s.eventsDropped, &s.evictions, &s.hooksDropped,
+		&s.rejections,
+	} {
+		c.cells = make([]counterCell, n)
+	}
+}
+
+// counterStripes returns a power of two close to GOMAXPROCS, capped at 64.
+func counterStripes() int {
+	n := runtime.GOMAXPROCS(0)
+	if n > 64 {
+		n = 64
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+type counterCell struct {
+	n atomic.Uint64
+	_ [64 - 8]byte
+}
+
+// stripedCounter spreads increments over cache-line sized cells so that
+// goroutines on different cores rarely contend for the same line; Load sums
+// them. Go does not expose the current P, so a cell is picked with the
+// runtime's per-thread random generator, which costs a few nanoseconds.
+type stripedCounter struct {
+	cells []counterCell
+}
+
+func (s *stripedCounter) Add(delta uint64) {
+	s.cells[rand.Uint32()&uint32(len(s.cells)-1)].n.Add(delta)
+}
+
+func (s *stripedCounter) Load() uint64 {
+	var sum uint64
+	for i := range s.cells {
+		sum += s.cells[i].n.Load()
+	}
+	return sum
+}
+
+func (c *Cache[T, V]) Stats() Stats {
+	return Stats{
+		Hits:               c.stats.hits.Load(),
+		Misses:             c.stats.misses.Load(),
+		ValidationFailures: c.stats.validationFailures.Load(),
+		BackendErrors:      c.stats.backendErrors.Load(),
+		CodecErrors:        c.stats.codecErrors.Load(),
+		EventsDropped:      c.stats.eventsDropped.Load(),
+		Evictions:          c.stats.evictions.Load(),
+		HooksDropped:       c.stats.hooksDropped.Load(),
+		Rejections:         c.stats.rejections.Load(),
+	}
+}
+
+// recordRead counts a lookup of key as a hit or a miss and traces it.
+func (c *Cache[T, V]) recordRead(key T, hit bool) {
+	if hit {
+		c.stats.hits.Add(1)
+	} else {
+		c.stats.misses.Add(1)
+	}
+	c.traceRead(key, hit)
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStatsHitsAndMisses(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("b")
+	cache.GetOrLoad("b", func(string) (int, error) { return 2, nil })
+	cache.GetOrLoad("b", func(string) (int, error) { return 2, nil })
+	cache.FetchMany([]string{"a", "c"}, func(keys []string) (map[string]int, error) { return nil, nil })
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(3), stats.Misses)
+	assert.InDelta(t, 0.5, stats.HitRatio(), 1e-9)
+	assert.Zero(t, Stats{}.HitRatio())
+}
+
+func TestStripedCounter(t *testing.T) {
+	var c counters
+	c.init()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				c.hits.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(8000), c.hits.Load())
+}
+
+func BenchmarkStripedCounter(b *testing.B) {
+	var c counters
+	c.init()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.hits.Add(1)
+		}
+	})
+}
+
+func BenchmarkAtomicCounter(b *testing.B) {
+	var n atomic.Uint64
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n.Add(1)
+		}
+	})
+}
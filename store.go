@@ -0,0 +1,407 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/alphadose/haxmap"
+)
+
+// Store is the map a Cache keeps its items in. Implementations must be safe
+// for concurrent use, and ForEach must tolerate fn mutating the store.
+type Store[T comparable, V any] interface {
+	Get(key T) (CachedItem[V], bool)
+	Set(key T, item CachedItem[V])
+	// Swap replaces the item under an existing key and returns the old one.
+	// It does nothing and reports false if key is absent.
+	Swap(key T, item CachedItem[V]) (CachedItem[V], bool)
+	GetOrSet(key T, item CachedItem[V]) (CachedItem[V], bool)
+	GetAndDel(key T) (CachedItem[V], bool)
+	// CompareAndSwap replaces the item under key only if the stored one has
+	// the given version, reporting whether it did.
+	CompareAndSwap(key T, version uint64, item CachedItem[V]) bool
+	// CompareAndDelete removes key only if the stored item has the given
+	// version, reporting whether it did.
+	CompareAndDelete(key T, version uint64) bool
+	Del(key T)
+	ForEach(fn func(key T, item CachedItem[V]) bool)
+	Len() int
+}
+
+// Grower is implemented by stores that can reserve room for more items ahead
+// of time, avoiding incremental resizes while they are filled.
+type Grower interface {
+	// Grow ensures the store can hold another n items without resizing.
+	Grow(n int)
+}
+
+// Compacter is implemented by stores whose memory use does not shrink on its
+// own as items are removed.
+type Compacter interface {
+	// Compact resizes internal structures to fit the current item count.
+	Compact()
+}
+
+// NewHaxmapStore returns the default lock-free Store backed by haxmap.
+// Items are stored by value: haxmap already boxes every value it holds, so
+// storing pointers would cost a second allocation per Set.
+func NewHaxmapStore[T hashable, V any]() Store[T, V] {
+	return newHaxmapStore[T, V](0)
+}
+
+// newHaxmapStore returns a haxmap store sized for capacity items, or the
+// historical default size if capacity is zero. haxmap resizes once its index
+// is half full, so the index is allocated at twice the capacity.
+func newHaxmapStore[T hashable, V any](capacity int) Store[T, V] {
+	size := uintptr(iter0 * elementNum0)
+	if capacity > 0 {
+		size = roundUpPow2(uintptr(capacity) * 2)
+	}
+	s := &haxmapStore[T, V]{Map: haxmap.New[T, CachedItem[V]](size)}
+	s.size.Store(size)
+	return s
+}
+
+type haxmapStore[T hashable, V any] struct {
+	*haxmap.Map[T, CachedItem[V]]
+	size atomic.Uintptr
+}
+
+func (s *haxmapStore[T, V]) Del(key T) {
+	s.Map.Del(key)
+}
+
+// CompareAndSwap uses haxmap's CompareAndSwap, which compares items with
+// reflect.DeepEqual. Versions are unique per write, so a mismatch under an
+// unchanged version means the value is not deeply equal to itself, a func or
+// a NaN; the item is then swapped and put back if it turns out to have
+// changed in between.
+func (s *haxmapStore[T, V]) CompareAndSwap(key T, version uint64, item CachedItem[V]) bool {
+	for {
+		old, ok := s.Map.Get(key)
+		if !ok || old.version != version {
+			return false
+		}
+		if s.Map.CompareAndSwap(key, old, item) {
+			return true
+		}
+		if cur, ok := s.Map.Get(key); ok && cur.version == version {
+			old, ok := s.Map.Swap(key, item)
+			if ok && old.version != version {
+				s.Map.Swap(key, old)
+			}
+			return ok && old.version == version
+		}
+	}
+}
+
+// CompareAndDelete removes the item and puts it back unless it had the given
+// version, since haxmap has no conditional delete. A write landing between
+// the two wins over the restored item.
+func (s *haxmapStore[T, V]) CompareAndDelete(key T, version uint64) bool {
+	old, ok := s.Map.GetAndDel(key)
+	if !ok {
+		return false
+	}
+	if old.version != version {
+		s.Map.GetOrSet(key, old)
+		return false
+	}
+	return true
+}
+
+func (s *haxmapStore[T, V]) Len() int {
+	return int(s.Map.Len())
+}
+
+// Grow resizes the index up front. haxmap.Grow also shrinks, so sizes at or
+// below the largest one requested so far are ignored.
+func (s *haxmapStore[T, V]) Grow(n int) {
+	want := roundUpPow2(uintptr(s.Len()+n) * 2)
+	for {
+		size := s.size.Load()
+		if want <= size {
+			return
+		}
+		if s.size.CompareAndSwap(size, want) {
+			s.Map.Grow(want)
+			return
+		}
+	}
+}
+
+// Compact shrinks the index to fit the current item count. haxmap rebuilds
+// the index concurrently with readers and writers and grows it back if
+// items were added meanwhile.
+func (s *haxmapStore[T, V]) Compact() {
+	size := roundUpPow2(uintptr(s.Len()) * 2)
+	if size < 8 {
+		size = 8
+	}
+	s.size.Store(size)
+	s.Map.Grow(size)
+}
+
+func roundUpPow2(n uintptr) uintptr {
+	p := uintptr(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+type mapShard[T comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[T]CachedItem[V]
+}
+
+type shardedMapStore[T comparable, V any] struct {
+	hasher keyHasher[T]
+	shards []mapShard[T, V]
+}
+
+// NewShardedMapStore returns a stdlib-only Store made of n Go maps, each
+// guarded by its own RWMutex.
+func NewShardedMapStore[T hashable, V any](n int) Store[T, V] {
+	return newShardedMapStore[T, V](n, newKeyHasher[T](), 0)
+}
+
+// NewShardedMapStoreFunc is like NewShardedMapStore for any comparable key
+// type, using hash to pick each key's shard.
+func NewShardedMapStoreFunc[T comparable, V any](n int, hash func(T) uintptr) Store[T, V] {
+	return newShardedMapStore[T, V](n, hasherFunc(hash), 0)
+}
+
+func newShardedMapStore[T comparable, V any](n int, hasher keyHasher[T], capacity int) Store[T, V] {
+	if n < 1 {
+		n = 1
+	}
+	s := &shardedMapStore[T, V]{hasher: hasher, shards: make([]mapShard[T, V], n)}
+	for i := range s.shards {
+		s.shards[i].items = make(map[T]CachedItem[V], capacity/n)
+	}
+	return s
+}
+
+func (s *shardedMapStore[T, V]) shard(key T) *mapShard[T, V] {
+	return &s.shards[s.hasher.hash(key)%uint64(len(s.shards))]
+}
+
+func (s *shardedMapStore[T, V]) Get(key T) (CachedItem[V], bool) {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	item, ok := sh.items[key]
+	sh.mu.RUnlock()
+	return item, ok
+}
+
+func (s *shardedMapStore[T, V]) Set(key T, item CachedItem[V]) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	sh.items[key] = item
+	sh.mu.Unlock()
+}
+
+func (s *shardedMapStore[T, V]) Swap(key T, item CachedItem[V]) (CachedItem[V], bool) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	old, ok := sh.items[key]
+	if ok {
+		sh.items[key] = item
+	}
+	return old, ok
+}
+
+func (s *shardedMapStore[T, V]) GetOrSet(key T, item CachedItem[V]) (CachedItem[V], bool) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if old, ok := sh.items[key]; ok {
+		return old, true
+	}
+	sh.items[key] = item
+	return item, false
+}
+
+func (s *shardedMapStore[T, V]) GetAndDel(key T) (CachedItem[V], bool) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	item, ok := sh.items[key]
+	delete(sh.items, key)
+	return item, ok
+}
+
+func (s *shardedMapStore[T, V]) CompareAndSwap(key T, version uint64, item CachedItem[V]) bool {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if old, ok := sh.items[key]; !ok || old.version != version {
+		return false
+	}
+	sh.items[key] = item
+	return true
+}
+
+func (s *shardedMapStore[T, V]) CompareAndDelete(key T, version uint64) bool {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if old, ok := sh.items[key]; !ok || old.version != version {
+		return false
+	}
+	delete(sh.items, key)
+	return true
+}
+
+func (s *shardedMapStore[T, V]) Del(key T) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	delete(sh.items, key)
+	sh.mu.Unlock()
+}
+
+// ForEach visits a copy of each shard so fn may mutate the store.
+func (s *shardedMapStore[T, V]) ForEach(fn func(T, CachedItem[V]) bool) {
+	type pair struct {
+		key  T
+		item CachedItem[V]
+	}
+	var buf []pair
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		buf = buf[:0]
+		for key, item := range sh.items {
+			buf = append(buf, pair{key, item})
+		}
+		sh.mu.RUnlock()
+		for _, p := range buf {
+			if !fn(p.key, p.item) {
+				return
+			}
+		}
+	}
+}
+
+func (s *shardedMapStore[T, V]) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		n += len(sh.items)
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// Grow rebuilds each shard's map with room for its share of n more items.
+// Go maps cannot be grown in place, so this copies every item.
+func (s *shardedMapStore[T, V]) Grow(n int) {
+	if per := n / len(s.shards); per > 0 {
+		s.rebuild(per)
+	}
+}
+
+// Compact rebuilds each shard's map, since Go maps never release buckets
+// after deletes.
+func (s *shardedMapStore[T, V]) Compact() {
+	s.rebuild(0)
+}
+
+func (s *shardedMapStore[T, V]) rebuild(extra int) {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		items := make(map[T]CachedItem[V], len(sh.items)+extra)
+		for k, v := range sh.items {
+			items[k] = v
+		}
+		sh.items = items
+		sh.mu.Unlock()
+	}
+}
+
+type syncMapStore[T comparable, V any] struct {
+	m sync.Map
+}
+
+// NewSyncMapStore returns a stdlib-only Store backed by sync.Map, which suits
+// read-mostly workloads with a stable key set. Items are boxed so that Swap
+// and the conditional writes can compare them by identity.
+func NewSyncMapStore[T comparable, V any]() Store[T, V] {
+	return &syncMapStore[T, V]{}
+}
+
+func (s *syncMapStore[T, V]) Get(key T) (CachedItem[V], bool) {
+	v, ok := s.m.Load(key)
+	if !ok {
+		return CachedItem[V]{}, false
+	}
+	return *v.(*CachedItem[V]), true
+}
+
+func (s *syncMapStore[T, V]) Set(key T, item CachedItem[V]) {
+	s.m.Store(key, &item)
+}
+
+func (s *syncMapStore[T, V]) Swap(key T, item CachedItem[V]) (CachedItem[V], bool) {
+	for {
+		old, ok := s.m.Load(key)
+		if !ok {
+			return CachedItem[V]{}, false
+		}
+		if s.m.CompareAndSwap(key, old, &item) {
+			return *old.(*CachedItem[V]), true
+		}
+	}
+}
+
+func (s *syncMapStore[T, V]) GetOrSet(key T, item CachedItem[V]) (CachedItem[V], bool) {
+	v, loaded := s.m.LoadOrStore(key, &item)
+	return *v.(*CachedItem[V]), loaded
+}
+
+func (s *syncMapStore[T, V]) GetAndDel(key T) (CachedItem[V], bool) {
+	v, ok := s.m.LoadAndDelete(key)
+	if !ok {
+		return CachedItem[V]{}, false
+	}
+	return *v.(*CachedItem[V]), true
+}
+
+func (s *syncMapStore[T, V]) CompareAndSwap(key T, version uint64, item CachedItem[V]) bool {
+	old, ok := s.m.Load(key)
+	if !ok || old.(*CachedItem[V]).version != version {
+		return false
+	}
+	return s.m.CompareAndSwap(key, old, &item)
+}
+
+func (s *syncMapStore[T, V]) CompareAndDelete(key T, version uint64) bool {
+	old, ok := s.m.Load(key)
+	if !ok || old.(*CachedItem[V]).version != version {
+		return false
+	}
+	return s.m.CompareAndDelete(key, old)
+}
+
+func (s *syncMapStore[T, V]) Del(key T) {
+	s.m.Delete(key)
+}
+
+func (s *syncMapStore[T, V]) ForEach(fn func(T, CachedItem[V]) bool) {
+	s.m.Range(func(k, v any) bool {
+		return fn(k.(T), *v.(*CachedItem[V]))
+	})
+}
+
+func (s *syncMapStore[T, V]) Len() int {
+	n := 0
+	s.m.Range(func(any, any) bool {
+		n++
+		return true
+	})
+	return n
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testStores = map[string]func() Store[string, []string]{
+	"haxmap":  NewHaxmapStore[string, []string],
+	"sharded": func() Store[string, []string] { return NewShardedMapStore[string, []string](8) },
+	"syncmap": NewSyncMapStore[string, []string],
+}
+
+func TestStores(t *testing.T) {
+	for name, newStore := range testStores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			item := CachedItem[[]string]{Value: []string{"a"}}
+
+			_, swapped := s.Swap("k", item)
+			assert.False(t, swapped, "Expected Swap on a missing key to fail")
+
+			s.Set("k", item)
+			got, ok := s.Get("k")
+			assert.True(t, ok)
+			assert.Equal(t, item, got)
+
+			old, swapped := s.Swap("k", CachedItem[[]string]{Value: []string{"b"}})
+			assert.True(t, swapped)
+			assert.Equal(t, item, old)
+
+			actual, loaded := s.GetOrSet("k", item)
+			assert.True(t, loaded)
+			assert.Equal(t, []string{"b"}, actual.Value)
+
+			for i := 0; i < 10; i++ {
+				s.Set(strconv.Itoa(i), item)
+			}
+			assert.Equal(t, 11, s.Len())
+
+			s.ForEach(func(key string, _ CachedItem[[]string]) bool {
+				s.Del(key)
+				return true
+			})
+			assert.Equal(t, 0, s.Len(), "Expected ForEach to tolerate deletes")
+
+			s.Set("k", item)
+			_, ok = s.GetAndDel("k")
+			assert.True(t, ok)
+			_, ok = s.Get("k")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestStoresCompareAndSwap(t *testing.T) {
+	for name, newStore := range testStores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			assert.False(t, s.CompareAndSwap("k", 1, CachedItem[[]string]{version: 2}))
+			assert.False(t, s.CompareAndDelete("k", 1))
+
+			s.Set("k", CachedItem[[]string]{Value: []string{"a"}, version: 1})
+			assert.False(t, s.CompareAndSwap("k", 3, CachedItem[[]string]{version: 4}), "Expected a stale version to be refused")
+			assert.True(t, s.CompareAndSwap("k", 1, CachedItem[[]string]{Value: []string{"b"}, version: 2}))
+			got, _ := s.Get("k")
+			assert.Equal(t, []string{"b"}, got.Value)
+
+			assert.False(t, s.CompareAndDelete("k", 1), "Expected a stale version to be refused")
+			_, ok := s.Get("k")
+			assert.True(t, ok, "Expected a refused delete to keep the item")
+			assert.True(t, s.CompareAndDelete("k", 2))
+			_, ok = s.Get("k")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestHaxmapStoreCompareAndSwapFunc(t *testing.T) {
+	s := NewHaxmapStore[string, func()]()
+	s.Set("k", CachedItem[func()]{Value: func() {}, version: 1})
+	assert.True(t, s.CompareAndSwap("k", 1, CachedItem[func()]{Value: func() {}, version: 2}), "Expected values DeepEqual cannot compare to be swapped")
+	assert.False(t, s.CompareAndSwap("k", 1, CachedItem[func()]{version: 3}))
+}
+
+func TestCacheWithStore(t *testing.T) {
+	for name, newStore := range testStores {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, []string](50*time.Millisecond, WithStore[string, []string](newStore))
+			defer cache.StopCleanup()
+
+			cache.Set("k", []string{"v"})
+			value, found := cache.Get("k")
+			assert.True(t, found)
+			assert.Equal(t, []string{"v"}, value)
+
+			time.Sleep(150 * time.Millisecond)
+			_, found = cache.Get("k")
+			assert.False(t, found, "Expected expiry to work with every store")
+		})
+	}
+}
+
+func TestStoreGrow(t *testing.T) {
+	for name, newStore := range testStores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			s.Set("k", CachedItem[[]string]{Value: []string{"v"}})
+			if g, ok := s.(Grower); ok {
+				g.Grow(100000)
+			}
+			item, ok := s.Get("k")
+			assert.True(t, ok, "Expected Grow to keep existing items")
+			assert.Equal(t, []string{"v"}, item.Value)
+		})
+	}
+
+	s := newHaxmapStore[int, int](0).(*haxmapStore[int, int])
+	s.Grow(100000)
+	assert.Equal(t, uintptr(1<<18), s.size.Load())
+	s.Grow(10)
+	assert.Equal(t, uintptr(1<<18), s.size.Load(), "Expected Grow never to shrink the index")
+}
+
+func TestStoreCompact(t *testing.T) {
+	s := newHaxmapStore[int, int](0).(*haxmapStore[int, int])
+	for i := 0; i < 10000; i++ {
+		s.Set(i, CachedItem[int]{Value: i})
+	}
+	for i := 10; i < 10000; i++ {
+		s.Del(i)
+	}
+	s.Compact()
+	assert.Equal(t, uintptr(32), s.size.Load())
+	for i := 0; i < 10; i++ {
+		item, ok := s.Get(i)
+		assert.True(t, ok, "Expected Compact to keep remaining items")
+		assert.Equal(t, i, item.Value)
+	}
+}
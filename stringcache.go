@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+const stringShards = 64
+
+type stringEntry[V any] struct {
+	value   V
+	expires int64
+}
+
+type stringShard[V any] struct {
+	mu    sync.RWMutex
+	items map[string]stringEntry[V]
+	_     [96]byte // keep neighbouring shard locks off the same cache line
+}
+
+// StringCache is a Cache specialised for string keys. Keys are hashed with the
+// runtime's string hash to pick one of a fixed number of shards, and entries
+// are stored inline in plain Go maps rather than behind per-entry pointers.
+type StringCache[V any] struct {
+	seed        maphash.Seed
+	shards      [stringShards]stringShard[V]
+	ttl         time.Duration
+	stopCleanup chan struct{}
+}
+
+func NewStringCache[V any](ttl time.Duration) *StringCache[V] {
+	c := &StringCache[V]{
+		seed:        maphash.MakeSeed(),
+		ttl:         ttl,
+		stopCleanup: make(chan struct{}),
+	}
+	for i := range c.shards {
+		c.shards[i].items = make(map[string]stringEntry[V])
+	}
+	acquireCoarse()
+	go c.startCleanupRoutine()
+	return c
+}
+
+func (c *StringCache[V]) shard(key string) *stringShard[V] {
+	return &c.shards[maphash.String(c.seed, key)%stringShards]
+}
+
+func (c *StringCache[V]) Set(key string, value V) {
+	s := c.shard(key)
+	s.mu.Lock()
+	s.items[key] = stringEntry[V]{value: value, expires: coarseNow() + int64(c.ttl)}
+	s.mu.Unlock()
+}
+
+func (c *StringCache[V]) Get(key string) (V, bool) {
+	s := c.shard(key)
+	s.mu.RLock()
+	e, ok := s.items[key]
+	s.mu.RUnlock()
+	return e.value, ok
+}
+
+func (c *StringCache[V]) Delete(key string) {
+	s := c.shard(key)
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+}
+
+func (c *StringCache[V]) Clear() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		clear(s.items)
+		s.mu.Unlock()
+	}
+}
+
+func (c *StringCache[V]) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		n += len(s.items)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+func (c *StringCache[V]) startCleanupRoutine() {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stopCleanup:
+			return
+		}
+	}
+}
+
+func (c *StringCache[V]) cleanup() {
+	now := coarseNow()
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		for key, e := range s.items {
+			if now > e.expires {
+				delete(s.items, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (c *StringCache[V]) StopCleanup() {
+	close(c.stopCleanup)
+	releaseCoarse()
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringCacheSetAndGet(t *testing.T) {
+	cache := NewStringCache[int](time.Minute)
+	defer cache.StopCleanup()
+
+	cache.Set("a", 1)
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 1, cache.Len())
+
+	cache.Delete("a")
+	_, found = cache.Get("a")
+	assert.False(t, found)
+
+	cache.Set("b", 2)
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestStringCacheCleanup(t *testing.T) {
+	cache := NewStringCache[int](50 * time.Millisecond)
+	defer cache.StopCleanup()
+
+	cache.Set("a", 1)
+	time.Sleep(150 * time.Millisecond)
+	_, found := cache.Get("a")
+	assert.False(t, found, "Expected entry to be cleaned up")
+}
+
+func benchKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkGenericStringSet(b *testing.B) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	keys := benchKeys(1 << 16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Set(keys[i&(len(keys)-1)], i)
+		}
+	})
+}
+
+func BenchmarkStringCacheSet(b *testing.B) {
+	cache := NewStringCache[int](time.Minute)
+	defer cache.StopCleanup()
+	keys := benchKeys(1 << 16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Set(keys[i&(len(keys)-1)], i)
+		}
+	})
+}
+
+func BenchmarkGenericStringGet(b *testing.B) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	keys := benchKeys(1 << 16)
+	for i, key := range keys {
+		cache.Set(key, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(keys[i&(len(keys)-1)])
+		}
+	})
+}
+
+func BenchmarkStringCacheGet(b *testing.B) {
+	cache := NewStringCache[int](time.Minute)
+	defer cache.StopCleanup()
+	keys := benchKeys(1 << 16)
+	for i, key := range keys {
+		cache.Set(key, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(keys[i&(len(keys)-1)])
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package cache
+
+// Tier is the minimal store a Tiered cache can use as its second level.
+// *Cache implements it; adapters for Redis, disk, etc. only need these three
+// methods.
+type Tier[T comparable, V any] interface {
+	Get(key T) (V, bool)
+	Set(key T, value V)
+	Delete(key T)
+}
+
+// Tiered chains an in-memory L1 cache in front of a second-level store.
+// Misses in L1 fall through to L2 and hits there are promoted to L1.
+type Tiered[T comparable, V any] struct {
+	l1        *Cache[T, V]
+	l2        Tier[T, V]
+	writeBoth bool
+}
+
+// NewTiered composes l1 and l2. When writeBoth is set, Set writes to both
+// levels; otherwise writes only go to L1. Delete always removes the key from
+// both levels.
+func NewTiered[T comparable, V any](l1 *Cache[T, V], l2 Tier[T, V], writeBoth bool) *Tiered[T, V] {
+	return &Tiered[T, V]{l1: l1, l2: l2, writeBoth: writeBoth}
+}
+
+func (t *Tiered[T, V]) Get(key T) (V, bool) {
+	if value, ok := t.l1.Get(key); ok {
+		return value, true
+	}
+	value, ok := t.l2.Get(key)
+	if ok {
+		t.l1.set(t.l1.canonical(key), value, SourceRemote, 0, PriorityNormal, nil)
+	}
+	return value, ok
+}
+
+func (t *Tiered[T, V]) Set(key T, value V) {
+	t.l1.Set(key, value)
+	if t.writeBoth {
+		t.l2.Set(key, value)
+	}
+}
+
+func (t *Tiered[T, V]) Delete(key T) {
+	t.l1.Delete(key)
+	t.l2.Delete(key)
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTieredPromotesL2Hits(t *testing.T) {
+	l1 := NewCache[int, string](time.Minute, WithProvenance[int, string]())
+	l2 := NewCache[int, string](time.Minute)
+	tiered := NewTiered[int, string](l1, l2, false)
+
+	l2.Set(1, "remote")
+	value, found := tiered.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "remote", value)
+
+	entry, found := l1.GetEntry(1)
+	assert.True(t, found, "Expected L2 hit to be promoted to L1")
+	assert.Equal(t, SourceRemote, entry.Source)
+
+	_, found = tiered.Get(2)
+	assert.False(t, found)
+}
+
+func TestTieredWrites(t *testing.T) {
+	l1 := NewCache[int, string](time.Minute)
+	l2 := NewCache[int, string](time.Minute)
+
+	NewTiered[int, string](l1, l2, false).Set(1, "local")
+	_, found := l2.Get(1)
+	assert.False(t, found, "Expected write to stay in L1")
+
+	tiered := NewTiered[int, string](l1, l2, true)
+	tiered.Set(2, "both")
+	_, found = l2.Get(2)
+	assert.True(t, found, "Expected write to fan out to L2")
+
+	tiered.Delete(2)
+	_, found = l1.Get(2)
+	assert.False(t, found)
+	_, found = l2.Get(2)
+	assert.False(t, found)
+}
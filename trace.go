@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceOp is the kind of operation a TraceRecord describes.
+type TraceOp uint8
+
+const (
+	TraceHit TraceOp = iota + 1
+	TraceMiss
+	TraceSet
+	TraceDelete
+)
+
+// TraceRecord is one operation read back from a trace written by
+// WithTraceRecorder. Key is the hash of the key, which is only meaningful
+// within one trace.
+type TraceRecord struct {
+	Time time.Time
+	Op   TraceOp
+	Key  uint64
+}
+
+var ErrBadTrace = errors.New("cache: malformed trace")
+
+// A trace starts with traceMagic and the wall clock time in Unix nanoseconds,
+// followed by records of the nanoseconds since the previous record as a
+// uvarint, the op byte and the little-endian key hash.
+const traceMagic = "MCTRACE1"
+
+type tracer struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	rate    uint64
+	last    int64
+	buf     [binary.MaxVarintLen64 + 9]byte
+	lastErr atomic.Pointer[error]
+}
+
+func newTracer(w io.Writer, sampleRate int) *tracer {
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	return &tracer{w: bufio.NewWriter(w), rate: uint64(sampleRate)}
+}
+
+func (t *tracer) record(err error) {
+	if err != nil {
+		t.lastErr.Store(&err)
+	}
+}
+
+// start writes the header.
+func (t *tracer) start(wall time.Time, now int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last = now
+	var header [len(traceMagic) + 8]byte
+	copy(header[:], traceMagic)
+	binary.LittleEndian.PutUint64(header[len(traceMagic):], uint64(wall.UnixNano()))
+	_, err := t.w.Write(header[:])
+	t.record(err)
+}
+
+func (t *tracer) write(op TraceOp, hash uint64, now int64) {
+	if hash%t.rate != 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delta := now - t.last
+	if delta < 0 {
+		delta = 0
+	}
+	t.last += delta
+	n := binary.PutUvarint(t.buf[:], uint64(delta))
+	t.buf[n] = byte(op)
+	binary.LittleEndian.PutUint64(t.buf[n+1:], hash)
+	_, err := t.w.Write(t.buf[:n+9])
+	t.record(err)
+}
+
+func (t *tracer) flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(t.w.Flush())
+}
+
+// trace records op on key if tracing is enabled.
+func (c *Cache[T, V]) trace(op TraceOp, key T) {
+	if c.tracer != nil {
+		c.tracer.write(op, c.hasher.hash(key), c.nanotime())
+	}
+}
+
+// traceRead records a lookup of key as a hit or a miss.
+func (c *Cache[T, V]) traceRead(key T, hit bool) {
+	if c.tracer == nil {
+		return
+	}
+	if hit {
+		c.trace(TraceHit, key)
+	} else {
+		c.trace(TraceMiss, key)
+	}
+}
+
+// runTrace flushes the trace every second and once more on StopCleanup.
+func (c *Cache[T, V]) runTrace() {
+	ticker := c.newTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			c.tracer.flush()
+		case <-c.stopCleanup:
+			c.tracer.flush()
+			return
+		}
+	}
+}
+
+// LastTraceError returns the most recent error writing the access trace, or
+// nil.
+func (c *Cache[T, V]) LastTraceError() error {
+	if c.tracer == nil {
+		return nil
+	}
+	if err := c.tracer.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// TraceReader reads back a trace written by WithTraceRecorder.
+type TraceReader struct {
+	r       *bufio.Reader
+	now     time.Time
+	started bool
+}
+
+func NewTraceReader(r io.Reader) *TraceReader {
+	return &TraceReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next record, or io.EOF at the end of the trace.
+func (t *TraceReader) Next() (TraceRecord, error) {
+	if !t.started {
+		var header [len(traceMagic) + 8]byte
+		if _, err := io.ReadFull(t.r, header[:]); err != nil {
+			return TraceRecord{}, ErrBadTrace
+		}
+		if string(header[:len(traceMagic)]) != traceMagic {
+			return TraceRecord{}, ErrBadTrace
+		}
+		t.now = time.Unix(0, int64(binary.LittleEndian.Uint64(header[len(traceMagic):])))
+		t.started = true
+	}
+	delta, err := binary.ReadUvarint(t.r)
+	if err == io.EOF {
+		return TraceRecord{}, io.EOF
+	}
+	if err != nil {
+		return TraceRecord{}, ErrBadTrace
+	}
+	var rec [9]byte
+	if _, err := io.ReadFull(t.r, rec[:]); err != nil {
+		return TraceRecord{}, ErrBadTrace
+	}
+	t.now = t.now.Add(time.Duration(delta))
+	return TraceRecord{
+		Time: t.now,
+		Op:   TraceOp(rec[0]),
+		Key:  binary.LittleEndian.Uint64(rec[1:]),
+	}, nil
+}
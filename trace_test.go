@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readTrace(t *testing.T, r io.Reader) []TraceRecord {
+	t.Helper()
+	var records []TraceRecord
+	tr := NewTraceReader(r)
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		assert.NoError(t, err)
+		if err != nil {
+			return records
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestCacheTraceRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+	cache := NewCache[string, int](time.Minute, WithTraceRecorder[string, int](&buf, 1))
+	cache.Set("a", 1)
+	cache.Get("a")
+	cache.Get("b")
+	cache.GetOrLoad("b", func(string) (int, error) { return 2, nil })
+	cache.Delete("a")
+	cache.StopCleanup()
+	assert.NoError(t, cache.LastTraceError())
+
+	records := readTrace(t, &buf)
+	var ops []TraceOp
+	for _, rec := range records {
+		ops = append(ops, rec.Op)
+		assert.False(t, rec.Time.Before(start.Add(-time.Second)))
+	}
+	assert.Equal(t, []TraceOp{TraceSet, TraceHit, TraceMiss, TraceMiss, TraceSet, TraceDelete}, ops)
+	assert.Equal(t, records[0].Key, records[1].Key)
+	assert.NotEqual(t, records[0].Key, records[2].Key)
+}
+
+func TestCacheTraceSampling(t *testing.T) {
+	var buf bytes.Buffer
+	cache := NewCache[int, int](time.Minute, WithTraceRecorder[int, int](&buf, 4))
+	for i := range 1000 {
+		cache.Set(i, i)
+	}
+	cache.StopCleanup()
+	n := len(readTrace(t, &buf))
+	assert.Greater(t, n, 150)
+	assert.Less(t, n, 350)
+}
+
+func TestTraceReaderMalformed(t *testing.T) {
+	_, err := NewTraceReader(strings.NewReader("garbage")).Next()
+	assert.ErrorIs(t, err, ErrBadTrace)
+	_, err = NewTraceReader(strings.NewReader(traceMagic + "12345678\x05\x01")).Next()
+	assert.ErrorIs(t, err, ErrBadTrace)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import "time"
+
+// Expire gives the entry under key a new TTL counted from now and reports
+// whether the key was present. Like SetWithTTL, the new deadline is enforced
+// on read. Only the deadline changes: a write racing with Expire is kept,
+// and Expire then applies the new TTL to it.
+func (c *Cache[T, V]) Expire(key T, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+	key = c.canonical(key)
+	var last uint64
+	for {
+		item, value, ok := c.lookup(key)
+		// An unchanged version means the admission filter, not a racing
+		// write, turned the update away.
+		if !ok || item.version == last {
+			return false
+		}
+		last = item.version
+		next := item
+		next.expires, next.explicit = c.now()+int64(ttl), true
+		var pending pendingHooks
+		done := c.loggedIf(aofSet, key, value, ttl, func() bool {
+			_, ok := c.storeIf(key, item, next, &pending)
+			return ok
+		})
+		pending.run()
+		if done {
+			return true
+		}
+	}
+}
+
+// TTL returns the time left before the entry under key expires. Entries
+// past their deadline are reported missing even before the cleanup routine
+// removes them, unless pinned entries are exempt from expiry.
+func (c *Cache[T, V]) TTL(key T) (time.Duration, bool) {
+	key = c.canonical(key)
+	item, _, ok := c.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	left := time.Duration(item.expires - c.now())
+	if left <= 0 && !c.immortal(key) {
+		return 0, false
+	}
+	return left, true
+}
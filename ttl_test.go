@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheExpireAndTTL(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithExactTime[string, int]())
+	defer cache.StopCleanup()
+
+	_, found := cache.TTL("k")
+	assert.False(t, found)
+	assert.False(t, cache.Expire("k", time.Second), "Expected Expire to miss an absent key")
+
+	cache.Set("k", 1)
+	ttl, found := cache.TTL("k")
+	assert.True(t, found)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	assert.True(t, cache.Expire("k", 20*time.Millisecond))
+	ttl, _ = cache.TTL("k")
+	assert.LessOrEqual(t, ttl, 20*time.Millisecond)
+
+	time.Sleep(40 * time.Millisecond)
+	_, found = cache.Get("k")
+	assert.False(t, found, "Expected the new TTL to be enforced")
+}
+
+func TestCacheTTLHidesExpired(t *testing.T) {
+	cache := NewCache[string, int](20*time.Millisecond, WithExactTime[string, int]())
+	cache.StopCleanup()
+
+	cache.Set("k", 1)
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, 1, cache.Len(), "Expected the entry to remain unswept")
+	_, found := cache.TTL("k")
+	assert.False(t, found, "Expected TTL to report an expired entry missing")
+}
+
+func TestCacheExpireKeepsRacingSet(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	cache.Set("k", 0)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 1000; i++ {
+			cache.Set("k", i)
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		cache.Expire("k", time.Hour)
+	}
+	wg.Wait()
+	value, _ := cache.Get("k")
+	assert.Equal(t, 1000, value, "Expected Expire not to undo a racing Set")
+}
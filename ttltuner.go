@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// maxReuseKeys bounds the number of sampled keys whose last access is kept.
+const maxReuseKeys = 1 << 16
+
+// reuseTracker records the time between consecutive reads of a sample of
+// keys in a histogram with power-of-two microsecond buckets.
+type reuseTracker[T comparable] struct {
+	rate    uint64
+	mu      sync.Mutex
+	last    map[T]int64
+	first   uint64
+	buckets [65]uint64
+}
+
+func newReuseTracker[T comparable](rate int) *reuseTracker[T] {
+	if rate < 1 {
+		rate = 1
+	}
+	return &reuseTracker[T]{rate: uint64(rate), last: make(map[T]int64)}
+}
+
+func (r *reuseTracker[T]) record(key T, hash uint64, now int64) {
+	if hash%r.rate != 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.last[key]
+	switch {
+	case ok:
+		r.buckets[bits.Len64(uint64(now-prev)/uint64(time.Microsecond))]++
+	case len(r.last) < maxReuseKeys:
+		r.first++
+	default:
+		return
+	}
+	r.last[key] = now
+}
+
+func (r *reuseTracker[T]) estimate(ttl time.Duration) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := r.first
+	for _, n := range r.buckets {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	limit := float64(ttl / time.Microsecond)
+	var hits float64
+	for i, n := range r.buckets {
+		lo, hi := 0.0, 1.0
+		if i > 0 {
+			lo, hi = float64(uint64(1)<<(i-1)), float64(uint64(1)<<i)
+		}
+		switch {
+		case hi <= limit:
+			hits += float64(n)
+		case lo < limit:
+			hits += float64(n) * (limit - lo) / (hi - lo)
+		}
+	}
+	return hits / float64(total)
+}
+
+// analyze records a read of key for EstimateHitRatio.
+func (c *Cache[T, V]) analyze(key T) {
+	if c.reuse != nil {
+		c.reuse.record(key, c.hasher.hash(key), c.now())
+	}
+}
+
+// EstimateHitRatio estimates the fraction of reads that would have been hits
+// had entries lived for ttl, from the reads recorded since the cache was
+// created with WithTTLAnalysis. A read counts as a hit if the same key was
+// read less than ttl earlier, which models entries that are reloaded on a
+// miss and, for keys read steadily, slightly overestimates a fixed TTL. It
+// returns 0 when analysis is disabled or nothing has been recorded.
+func (c *Cache[T, V]) EstimateHitRatio(ttl time.Duration) float64 {
+	if c.reuse == nil {
+		return 0
+	}
+	return c.reuse.estimate(ttl)
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReuseTrackerEstimate(t *testing.T) {
+	r := newReuseTracker[string](1)
+	now := int64(0)
+	read := func(key string, after time.Duration) {
+		now += int64(after)
+		r.record(key, 0, now)
+	}
+	read("a", 0)
+	for range 9 {
+		read("a", 10*time.Millisecond)
+	}
+	read("b", 0)
+	for range 10 {
+		read("b", time.Minute)
+	}
+
+	assert.InDelta(t, 0, r.estimate(time.Millisecond), 0.001)
+	assert.InDelta(t, 9.0/21, r.estimate(time.Second), 0.001, "Expected only the frequent key to hit")
+	assert.InDelta(t, 19.0/21, r.estimate(time.Hour), 0.001, "Expected only first reads to miss")
+}
+
+func TestCacheEstimateHitRatio(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithTTLAnalysis[string, int](1))
+	defer cache.StopCleanup()
+
+	assert.Zero(t, cache.EstimateHitRatio(time.Minute))
+	for range 4 {
+		cache.Get("k")
+	}
+	assert.InDelta(t, 0.75, cache.EstimateHitRatio(time.Hour), 0.001)
+
+	plain := NewCache[string, int](time.Minute)
+	defer plain.StopCleanup()
+	plain.Get("k")
+	assert.Zero(t, plain.EstimateHitRatio(time.Hour))
+}
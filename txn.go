@@ -0,0 +1,259 @@
+package cache
+
+import "errors"
+
+// ErrTxnConflict is returned by Txn when a key the transaction read was
+// changed before it could commit.
+var ErrTxnConflict = errors.New("cache: transaction conflict")
+
+type txWrite[V any] struct {
+	value V
+	del   bool
+}
+
+// Tx is a transaction started by Txn. Its methods must only be called from
+// the function passed to Txn.
+type Tx[T comparable, V any] struct {
+	c      *Cache[T, V]
+	reads  map[T]uint64
+	writes map[T]txWrite[V]
+	order  []T
+}
+
+// Get returns the value of key as seen by the transaction: its own pending
+// write if any, or else the cached value, whose version is then checked at
+// commit.
+func (tx *Tx[T, V]) Get(key T) (V, bool) {
+	key = tx.c.canonical(key)
+	if w, ok := tx.writes[key]; ok {
+		return w.value, !w.del
+	}
+	item, value, ok := tx.c.lookup(key)
+	if _, seen := tx.reads[key]; !seen {
+		var version uint64
+		if ok {
+			version = item.version
+		}
+		tx.reads[key] = version
+	}
+	return value, ok
+}
+
+// Set stages a write of value under key.
+func (tx *Tx[T, V]) Set(key T, value V) {
+	tx.stage(key, txWrite[V]{value: value})
+}
+
+// Delete stages the deletion of key.
+func (tx *Tx[T, V]) Delete(key T) {
+	tx.stage(key, txWrite[V]{del: true})
+}
+
+func (tx *Tx[T, V]) stage(key T, w txWrite[V]) {
+	key = tx.c.canonical(key)
+	if _, ok := tx.writes[key]; !ok {
+		tx.order = append(tx.order, key)
+	}
+	tx.writes[key] = w
+}
+
+// txnCommit is a transaction whose writes are being applied. Lookups of its
+// keys wait until done is closed, so they see all of its writes or none.
+type txnCommit[T comparable] struct {
+	keys map[T]struct{}
+	done chan struct{}
+}
+
+func (tc *txnCommit[T]) wait(key T) {
+	if _, ok := tc.keys[key]; ok {
+		<-tc.done
+	}
+}
+
+// Txn runs fn and then commits the writes it staged, unless fn returns an
+// error. The commit fails with ErrTxnConflict, applying nothing, if any key
+// fn read has been written or deleted since; callers typically retry.
+//
+// A commit applies all of its writes or none: each one is a compare-and-swap
+// against the entry the commit checked, and if any write lands in between,
+// those already applied are rolled back. Lookups of the keys being written
+// wait for the commit to finish, so readers never see part of it. With
+// write-through, the backend is written before the cache and restored to
+// what it held if a backend write or the commit fails. With WithWriteBuffer,
+// pending writes are applied before the read versions are checked, and the
+// commit's own writes bypass the buffer.
+func (c *Cache[T, V]) Txn(fn func(tx *Tx[T, V]) error) error {
+	tx := &Tx[T, V]{c: c, reads: make(map[T]uint64), writes: make(map[T]txWrite[V])}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	var pending pendingHooks
+	defer pending.run()
+	c.txn.Lock()
+	defer c.txn.Unlock()
+	c.flushBuffer()
+	// olds holds the stored items the writes replace, expired ones included,
+	// which the compare-and-swaps are made against.
+	olds := make(map[T]CachedItem[V], len(tx.order))
+	for key, version := range tx.reads {
+		if _, written := tx.writes[key]; !written && c.currentVersion(key) != version {
+			return ErrTxnConflict
+		}
+	}
+	for _, key := range tx.order {
+		item, _, ok := c.lookup(key)
+		current := item.version
+		if !ok {
+			current = 0
+		}
+		if version, read := tx.reads[key]; read && current != version {
+			return ErrTxnConflict
+		}
+		olds[key] = item
+	}
+	undo := func() {}
+	if c.writeThrough {
+		var err error
+		if undo, err = c.writeTxnThrough(tx); err != nil {
+			return err
+		}
+	}
+	var recs []aofRecord[T]
+	if c.aof != nil || c.stream != nil {
+		for _, key := range tx.order {
+			w := tx.writes[key]
+			if rec, ok := c.aofRecord(txnOp(w), key, w.value, 0); ok {
+				recs = append(recs, rec)
+			}
+		}
+	}
+	tc := &txnCommit[T]{keys: make(map[T]struct{}, len(tx.order)), done: make(chan struct{})}
+	for _, key := range tx.order {
+		tc.keys[key] = struct{}{}
+	}
+	c.committing.Store(tc)
+	applied := c.logAll(recs, func() bool {
+		return c.commitTxn(tx, olds, &pending)
+	})
+	c.committing.Store(nil)
+	close(tc.done)
+	if !applied {
+		undo()
+		return ErrTxnConflict
+	}
+	for _, key := range tx.order {
+		w := tx.writes[key]
+		c.replicate(txnOp(w), key)
+		if c.writeBehind != nil {
+			c.writeBehind.enqueue(key, writeOp[V]{value: w.value, del: w.del})
+		}
+	}
+	return nil
+}
+
+func txnOp[V any](w txWrite[V]) uint8 {
+	if w.del {
+		return aofDelete
+	}
+	return aofSet
+}
+
+// commitTxn applies the writes of tx over the items in olds, reporting
+// whether all of them landed. If one does not, or a key tx only read has
+// changed, the writes applied so far are rolled back and their hooks
+// dropped.
+func (c *Cache[T, V]) commitTxn(tx *Tx[T, V], olds map[T]CachedItem[V], pending *pendingHooks) bool {
+	var hooks pendingHooks
+	stored := make(map[T]CachedItem[V], len(tx.order))
+	rollback := func(keys []T) {
+		var dropped pendingHooks
+		for _, key := range keys {
+			old := olds[key]
+			switch item, set := stored[key]; {
+			case !set:
+				if old.version != 0 {
+					c.reinsert(key, old)
+				}
+			case old.version == 0:
+				c.removeIf(key, item, &dropped)
+			default:
+				c.storeIf(key, item, old, &dropped)
+			}
+		}
+	}
+	for i, key := range tx.order {
+		w, old := tx.writes[key], olds[key]
+		ok := true
+		switch {
+		case w.del && old.version != 0:
+			ok = c.removeIf(key, old, &hooks)
+		case w.del:
+			_, present := c.items().Get(key)
+			ok = !present
+		default:
+			var item CachedItem[V]
+			if item, ok = c.storeIf(key, old, c.newItem(w.value, SourceSet), &hooks); ok {
+				stored[key] = item
+			}
+		}
+		if !ok {
+			rollback(tx.order[:i])
+			return false
+		}
+	}
+	for key, version := range tx.reads {
+		if _, written := tx.writes[key]; !written && c.currentVersion(key) != version {
+			rollback(tx.order)
+			return false
+		}
+	}
+	*pending = append(*pending, hooks...)
+	return true
+}
+
+// writeTxnThrough writes the writes of tx to the backend, returning a
+// function that restores what the backend held before. If a write fails,
+// the backend is restored and the error returned.
+func (c *Cache[T, V]) writeTxnThrough(tx *Tx[T, V]) (func(), error) {
+	type previous struct {
+		key     T
+		value   V
+		present bool
+	}
+	var done []previous
+	undo := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			p := done[i]
+			var err error
+			if p.present {
+				err = c.backend.Store(p.key, p.value)
+			} else {
+				err = c.backend.Delete(p.key)
+			}
+			if err != nil {
+				c.stats.backendErrors.Add(1)
+			}
+		}
+	}
+	for _, key := range tx.order {
+		value, err := c.backend.Load(key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			c.stats.backendErrors.Add(1)
+			undo()
+			return nil, err
+		}
+		p := previous{key: key, value: value, present: err == nil}
+		if w := tx.writes[key]; w.del {
+			err = c.backend.Delete(key)
+		} else {
+			err = c.backend.Store(key, w.value)
+		}
+		if err != nil {
+			c.stats.backendErrors.Add(1)
+			undo()
+			return nil, err
+		}
+		done = append(done, p)
+	}
+	return undo, nil
+}
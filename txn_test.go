@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheTxn(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("from", 10)
+
+	err := cache.Txn(func(tx *Tx[string, int]) error {
+		from, _ := tx.Get("from")
+		to, _ := tx.Get("to")
+		tx.Set("from", from-3)
+		tx.Set("to", to+3)
+		value, _ := tx.Get("to")
+		assert.Equal(t, 3, value, "Expected reads to see the transaction's own writes")
+		_, found := cache.Get("to")
+		assert.False(t, found, "Expected writes to be staged until commit")
+		return nil
+	})
+	assert.NoError(t, err)
+	from, _ := cache.Get("from")
+	to, _ := cache.Get("to")
+	assert.Equal(t, 7, from)
+	assert.Equal(t, 3, to)
+
+	boom := errors.New("boom")
+	err = cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Delete("from")
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+	_, found := cache.Get("from")
+	assert.True(t, found, "Expected a failed transaction to apply nothing")
+}
+
+func TestCacheTxnConflict(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+	cache.Set("k", 1)
+
+	err := cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Get("k")
+		tx.Get("absent")
+		cache.Set("k", 2)
+		tx.Set("other", 1)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrTxnConflict)
+	_, found := cache.Get("other")
+	assert.False(t, found)
+
+	err = cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Get("absent")
+		cache.Set("absent", 1)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrTxnConflict, "Expected creating a key read as absent to conflict")
+}
+
+func TestCacheTxnCounter(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := cache.Txn(func(tx *Tx[string, int]) error {
+					n, _ := tx.Get("n")
+					tx.Set("n", n+1)
+					return nil
+				})
+				if !errors.Is(err, ErrTxnConflict) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	n, _ := cache.Get("n")
+	assert.Equal(t, 20, n, "Expected no lost updates between transactions")
+}
+
+// keyFailingBackend fails writes of one key.
+type keyFailingBackend struct {
+	*mapBackend[string, int]
+	fail string
+}
+
+func (b keyFailingBackend) Store(key string, value int) error {
+	if key == b.fail {
+		return errors.New("backend down")
+	}
+	return b.mapBackend.Store(key, value)
+}
+
+func TestCacheTxnWriteThroughAllOrNothing(t *testing.T) {
+	backend := keyFailingBackend{mapBackend: newMapBackend[string, int](), fail: "b"}
+	cache := NewCache[string, int](time.Minute, WithWriteThrough[string, int](backend))
+	defer cache.StopCleanup()
+	cache.Set("a", 1)
+
+	err := cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("a", 2)
+		tx.Set("b", 2)
+		return nil
+	})
+	assert.Error(t, err)
+	value, _ := cache.Get("a")
+	assert.Equal(t, 1, value, "Expected a failed commit to leave the cache untouched")
+	value, _ = backend.get("a")
+	assert.Equal(t, 1, value, "Expected the backend writes already made to be undone")
+}
+
+func TestCacheTxnRollsBackOnRacingWrite(t *testing.T) {
+	s := &racingStore{Store: NewShardedMapStore[string, int](1)}
+	cache := NewCache[string, int](time.Minute, WithStore(func() Store[string, int] { return s }))
+	defer cache.StopCleanup()
+	cache.Set("a", 1)
+	cache.Set("b", 1)
+
+	// The write to b lands after the commit checked b, right before it
+	// writes a.
+	s.race = func() { cache.Set("b", 5) }
+	err := cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("a", 2)
+		tx.Set("b", 2)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrTxnConflict)
+	a, _ := cache.Get("a")
+	b, _ := cache.Get("b")
+	assert.Equal(t, 1, a, "Expected the write to a to be rolled back")
+	assert.Equal(t, 5, b)
+}
+
+func TestCacheTxnHidesPartialCommit(t *testing.T) {
+	s := &racingStore{Store: NewShardedMapStore[string, int](1)}
+	cache := NewCache[string, int](time.Minute, WithStore(func() Store[string, int] { return s }))
+	defer cache.StopCleanup()
+	cache.Set("a", 1)
+	cache.Set("b", 1)
+
+	// Read both keys while the commit has written a but not yet b.
+	seen := make(chan [2]int, 1)
+	read := func() {
+		go func() {
+			a, _ := cache.Get("a")
+			b, _ := cache.Get("b")
+			seen <- [2]int{a, b}
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+	s.race = func() { s.race = read }
+	assert.NoError(t, cache.Txn(func(tx *Tx[string, int]) error {
+		tx.Set("a", 2)
+		tx.Set("b", 2)
+		return nil
+	}))
+	assert.Equal(t, [2]int{2, 2}, <-seen, "Expected readers to wait for the commit")
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidValue = errors.New("cache: invalid value")
+
+func (c *Cache[T, V]) validate(value V) error {
+	if c.validator == nil {
+		return nil
+	}
+	if err := c.validator(value); err != nil {
+		c.stats.validationFailures.Add(1)
+		return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+	return nil
+}
+
+// validCached reports whether a cached value may be served. Values rejected by
+// the validator are removed so they are reloaded rather than served again.
+func (c *Cache[T, V]) validCached(key T, value V) bool {
+	if !c.validateOnRead || c.validate(value) == nil {
+		return true
+	}
+	c.writes.Add(1)
+	c.items().Del(key)
+	c.writes.Add(1)
+	c.forget(key)
+	return false
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nonEmpty(v string) error {
+	if v == "" {
+		return errors.New("empty value")
+	}
+	return nil
+}
+
+func TestCacheValidatorRejectsLoaderResult(t *testing.T) {
+	cache := NewCache[int, string](time.Minute, WithValidator[int, string](nonEmpty))
+
+	_, err := cache.GetOrLoad(1, func(int) (string, error) {
+		return "", nil
+	})
+	assert.ErrorIs(t, err, ErrInvalidValue)
+
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected invalid value not to be cached")
+
+	values, err := cache.FetchMany([]int{2, 3}, func([]int) (map[int]string, error) {
+		return map[int]string{2: "two", 3: ""}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{2: "two"}, values)
+	assert.Equal(t, uint64(2), cache.Stats().ValidationFailures)
+}
+
+func TestCacheValidateOnRead(t *testing.T) {
+	cache := NewCache[int, string](time.Minute,
+		WithValidator[int, string](nonEmpty),
+		WithValidateOnRead[int, string](),
+	)
+
+	cache.Set(1, "")
+	_, found := cache.Get(1)
+	assert.False(t, found, "Expected invalid cached value not to be served")
+	assert.Equal(t, uint64(1), cache.Stats().ValidationFailures)
+
+	value, err := cache.GetOrLoad(1, func(int) (string, error) {
+		return "fresh", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "fresh", value)
+}
@@ -0,0 +1,40 @@
+package cache
+
+// GetVersioned is Get that also returns the version of the entry. Versions
+// increase every time a key is written, so a writer can pass the one it read
+// to SetIfVersion to detect that someone else got there first.
+func (c *Cache[T, V]) GetVersioned(key T) (V, uint64, bool) {
+	key = c.canonical(key)
+	item, value, ok := c.lookup(key)
+	if !ok {
+		return value, 0, false
+	}
+	return value, item.version, true
+}
+
+// SetIfVersion stores value under key only if the entry still has the given
+// version, reporting whether it did. Version 0 matches a missing key, making
+// the call an insert-if-absent. The check and the write are a single
+// compare-and-swap on the store, so any write landing in between makes it
+// fail. With WithWriteBuffer, the caller's own buffered writes are applied
+// first and the write itself is never buffered.
+func (c *Cache[T, V]) SetIfVersion(key T, value V, version uint64) bool {
+	key = c.canonical(key)
+	c.flushBuffer()
+	// An expired entry the cleanup routine has not removed yet counts as
+	// missing, but the swap still has to replace that very item.
+	item, _, ok := c.lookup(key)
+	if ok && item.version != version || !ok && version != 0 {
+		return false
+	}
+	return c.applySetIf(key, value, item, nil)
+}
+
+// currentVersion returns the version of the live entry under key, or 0.
+func (c *Cache[T, V]) currentVersion(key T) uint64 {
+	item, _, ok := c.lookup(key)
+	if !ok {
+		return 0
+	}
+	return item.version
+}
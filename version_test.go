@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheSetIfVersion(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	assert.True(t, cache.SetIfVersion("k", 1, 0), "Expected version 0 to insert a missing key")
+	assert.False(t, cache.SetIfVersion("k", 2, 0))
+
+	value, version, found := cache.GetVersioned("k")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.NotZero(t, version)
+
+	cache.Set("k", 3)
+	_, newer, _ := cache.GetVersioned("k")
+	assert.Greater(t, newer, version, "Expected versions to increase on every write")
+	assert.False(t, cache.SetIfVersion("k", 4, version), "Expected a stale version to be rejected")
+	assert.True(t, cache.SetIfVersion("k", 4, newer))
+
+	value, _ = cache.Get("k")
+	assert.Equal(t, 4, value)
+
+	cache.Delete("k")
+	assert.True(t, cache.SetIfVersion("k", 5, 0), "Expected version 0 to insert a deleted key")
+}
+
+func TestCacheSetIfVersionConcurrent(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n, version, _ := cache.GetVersioned("n")
+				if cache.SetIfVersion("n", n+1, version) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	n, _ := cache.Get("n")
+	assert.Equal(t, 20, n)
+}
+
+// racingStore runs race right before the first conditional write, standing
+// in for a write landing between a check and the write that depends on it.
+type racingStore struct {
+	Store[string, int]
+	race func()
+}
+
+func (s *racingStore) CompareAndSwap(key string, version uint64, item CachedItem[int]) bool {
+	s.racing()
+	return s.Store.CompareAndSwap(key, version, item)
+}
+
+func (s *racingStore) CompareAndDelete(key string, version uint64) bool {
+	s.racing()
+	return s.Store.CompareAndDelete(key, version)
+}
+
+func (s *racingStore) racing() {
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+}
+
+func TestCacheSetIfVersionRacingSet(t *testing.T) {
+	s := &racingStore{Store: NewShardedMapStore[string, int](1)}
+	cache := NewCache[string, int](time.Minute, WithStore(func() Store[string, int] { return s }))
+	defer cache.StopCleanup()
+
+	cache.Set("k", 1)
+	_, version, _ := cache.GetVersioned("k")
+	s.race = func() { cache.Set("k", 2) }
+	assert.False(t, cache.SetIfVersion("k", 3, version), "Expected a write landing after the check to win")
+	value, _ := cache.Get("k")
+	assert.Equal(t, 2, value)
+}
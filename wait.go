@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type waiters[T comparable] struct {
+	mu   sync.Mutex
+	keys map[T]chan struct{}
+	n    atomic.Int32
+}
+
+// WaitFor returns the value of key, blocking until it is set if it is not
+// cached yet. It returns ctx.Err() if ctx is done first. This lets consumers
+// treat a key as a future that some producer fulfils with Set or a load.
+func (c *Cache[T, V]) WaitFor(ctx context.Context, key T) (V, error) {
+	key = c.canonical(key)
+	for {
+		ready := c.waiters.wait(key)
+		if _, value, ok := c.lookup(key); ok {
+			return value, nil
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// wait returns a channel that is closed the next time key is stored.
+func (w *waiters[T]) wait(key T) <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, ok := w.keys[key]
+	if !ok {
+		if w.keys == nil {
+			w.keys = make(map[T]chan struct{})
+		}
+		ch = make(chan struct{})
+		w.keys[key] = ch
+		w.n.Add(1)
+	}
+	return ch
+}
+
+// wake releases everyone waiting for key.
+func (w *waiters[T]) wake(key T) {
+	if w.n.Load() == 0 {
+		return
+	}
+	w.mu.Lock()
+	if ch, ok := w.keys[key]; ok {
+		delete(w.keys, key)
+		w.n.Add(-1)
+		close(ch)
+	}
+	w.mu.Unlock()
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheWaitFor(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	cache.Set("ready", 1)
+	value, err := cache.WaitFor(context.Background(), "ready")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value, "Expected a cached key to return immediately")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cache.Set("job", 42)
+	}()
+	value, err = cache.WaitFor(context.Background(), "job")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = cache.WaitFor(ctx, "never")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+)
+
+// Warm fills the cache from seed, which calls yield once per entry, for
+// example while iterating over a database query or a file. Entries get the
+// default TTL and replace any already present; they are not written to a
+// backend. Once ctx is done further entries are ignored, and Warm returns
+// ctx.Err() after seed returns; seed can watch ctx itself to stop early.
+func (c *Cache[T, V]) Warm(ctx context.Context, seed func(yield func(T, V)) error) error {
+	err := seed(func(key T, value V) {
+		if ctx.Err() != nil {
+			return
+		}
+		c.set(c.canonical(key), value, SourceLoader, 0, PriorityNormal, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+type warmup[T comparable, V any] struct {
+	seed func(yield func(T, V)) error
+	done chan struct{}
+	err  error
+}
+
+// runWarmup warms the cache on behalf of WithWarmup, giving up when the cache
+// is stopped.
+func (c *Cache[T, V]) runWarmup() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopCleanup:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	c.warmup.err = c.Warm(ctx, c.warmup.seed)
+	close(c.warmup.done)
+}
+
+// Ready reports whether warming started by WithWarmup has finished,
+// successfully or not. Without WithWarmup the cache is always ready.
+func (c *Cache[T, V]) Ready() bool {
+	if c.warmup == nil {
+		return true
+	}
+	select {
+	case <-c.warmup.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitReady blocks until warming started by WithWarmup finishes and returns
+// its error, or returns ctx.Err() if ctx is done first.
+func (c *Cache[T, V]) WaitReady(ctx context.Context) error {
+	if c.warmup == nil {
+		return nil
+	}
+	select {
+	case <-c.warmup.done:
+		return c.warmup.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
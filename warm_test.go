@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheWarm(t *testing.T) {
+	cache := NewCache[string, int](time.Minute)
+	defer cache.StopCleanup()
+
+	err := cache.Warm(context.Background(), func(yield func(string, int)) error {
+		yield("a", 1)
+		yield("b", 2)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, cache.Snapshot())
+
+	boom := errors.New("boom")
+	assert.ErrorIs(t, cache.Warm(context.Background(), func(yield func(string, int)) error { return boom }), boom)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = cache.Warm(ctx, func(yield func(string, int)) error {
+		yield("c", 3)
+		cancel()
+		yield("d", 4)
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, found := cache.Get("d")
+	assert.False(t, found, "Expected entries after cancellation to be ignored")
+}
+
+func TestCacheWithWarmup(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewCache[string, int](time.Minute, WithWarmup[string, int](func(yield func(string, int)) error {
+		<-release
+		yield("a", 1)
+		return nil
+	}))
+	defer cache.StopCleanup()
+
+	assert.False(t, cache.Ready(), "Expected the cache not to be ready while warming")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cache.WaitReady(ctx), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, cache.WaitReady(context.Background()))
+	assert.True(t, cache.Ready())
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	cold := NewCache[string, int](time.Minute)
+	defer cold.StopCleanup()
+	assert.True(t, cold.Ready(), "Expected caches without warmup to be ready")
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// cost returns the weight item is charged against WithMaxCost: the result of
+// the configured weigher, the encoded size with WithByteStorage, or else an
+// estimate from the value's type.
+func (c *Cache[T, V]) cost(key T, item CachedItem[V]) int64 {
+	if c.weigher != nil {
+		value, _ := c.value(item)
+		return c.weigher(key, value)
+	}
+	if item.blob.seq != 0 {
+		return int64(item.blob.n)
+	}
+	return c.autoWeigh(item.Value)
+}
+
+// autoWeigher returns a function estimating the memory held by values of type
+// V: the length of strings and of slices with fixed-size elements plus their
+// header, the size of types without pointers, and the shallow size of
+// anything else. Interface types are weighed by their dynamic type.
+func autoWeigher[V any]() func(V) int64 {
+	t := reflect.TypeFor[V]()
+	size := int64(t.Size())
+	switch {
+	case t.Kind() == reflect.Interface:
+		return func(v V) int64 {
+			rv := reflect.ValueOf(v)
+			if !rv.IsValid() {
+				return size
+			}
+			return size + weighValue(rv)
+		}
+	case t.Kind() == reflect.String:
+		return func(v V) int64 { return size + int64(len(*(*string)(unsafe.Pointer(&v)))) }
+	case t.Kind() == reflect.Slice && fixedSize(t.Elem()):
+		elem := int64(t.Elem().Size())
+		return func(v V) int64 { return size + elem*int64(len(*(*[]struct{})(unsafe.Pointer(&v)))) }
+	default:
+		return func(V) int64 { return size }
+	}
+}
+
+// weighValue is autoWeigher for a value of a type only known at run time.
+func weighValue(v reflect.Value) int64 {
+	t := v.Type()
+	size := int64(t.Size())
+	switch {
+	case t.Kind() == reflect.String:
+		return size + int64(v.Len())
+	case t.Kind() == reflect.Slice && fixedSize(t.Elem()):
+		return size + int64(t.Elem().Size())*int64(v.Len())
+	default:
+		return size
+	}
+}
+
+// fixedSize reports whether values of t hold no pointers, so that their size
+// is known from the type alone.
+func fixedSize(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return fixedSize(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !fixedSize(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// MemoryEstimate approximates the bytes held by the cache's entries: the
+// fixed size of each item and key, plus the bytes behind string and slice
+// keys and values, or the encoded size with WithByteStorage. Memory reached
+// through other pointers, and the store's own overhead, is not counted. It
+// walks the whole cache.
+func (c *Cache[T, V]) MemoryEstimate() int64 {
+	weighKey, weighValue := autoWeigher[T](), autoWeigher[V]()
+	perItem := int64(unsafe.Sizeof(CachedItem[V]{})) - int64(unsafe.Sizeof(*new(V)))
+	var total int64
+	c.items().ForEach(func(key T, item CachedItem[V]) bool {
+		total += perItem + weighKey(key)
+		if item.blob.seq != 0 {
+			total += int64(item.blob.n)
+		} else {
+			total += weighValue(item.Value)
+		}
+		return true
+	})
+	return total
+}
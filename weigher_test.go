@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoWeigher(t *testing.T) {
+	type point struct{ X, Y int32 }
+	assert.Equal(t, int64(16+5), autoWeigher[string]()("hello"))
+	assert.Equal(t, int64(24+3), autoWeigher[[]byte]()([]byte("abc")))
+	assert.Equal(t, int64(24+3*8), autoWeigher[[]point]()(make([]point, 3)))
+	assert.Equal(t, int64(8), autoWeigher[int64]()(1))
+	assert.Equal(t, int64(8), autoWeigher[point]()(point{}))
+	assert.Equal(t, int64(8), autoWeigher[*point]()(&point{}), "Expected pointers to be weighed shallowly")
+	assert.Equal(t, int64(16+16+2), autoWeigher[any]()("hi"))
+	assert.Equal(t, int64(16), autoWeigher[any]()(nil))
+}
+
+func TestCacheMaxCost(t *testing.T) {
+	cache := NewCache[string, string](time.Minute, WithMaxCost[string, string](120))
+	defer cache.StopCleanup()
+
+	cache.Set("a", strings.Repeat("a", 40))
+	cache.Set("b", strings.Repeat("b", 40))
+	_, found := cache.Get("a")
+	assert.True(t, found)
+	cache.Set("c", strings.Repeat("c", 10))
+
+	_, found = cache.Get("b")
+	assert.False(t, found, "Expected the least recently used entry to make room")
+	_, found = cache.Get("a")
+	assert.True(t, found)
+
+	cache.Set("huge", strings.Repeat("h", 200))
+	_, found = cache.Get("huge")
+	assert.False(t, found, "Expected entries above the limit not to be kept")
+	_, found = cache.Get("c")
+	assert.True(t, found, "Expected oversized entries not to displace others")
+}
+
+func TestCacheWeigher(t *testing.T) {
+	cache := NewCache[string, int](time.Minute,
+		WithMaxCost[string, int](10),
+		WithWeigher(func(_ string, v int) int64 { return int64(v) }),
+	)
+	defer cache.StopCleanup()
+
+	cache.Set("a", 6)
+	cache.Set("b", 4)
+	cache.Set("c", 1)
+	_, found := cache.Get("a")
+	assert.False(t, found)
+	_, found = cache.Get("b")
+	assert.True(t, found)
+}
+
+func TestCacheMemoryEstimate(t *testing.T) {
+	cache := NewCache[string, []byte](time.Minute)
+	defer cache.StopCleanup()
+	assert.Zero(t, cache.MemoryEstimate())
+
+	cache.Set("a", make([]byte, 1000))
+	one := cache.MemoryEstimate()
+	assert.Greater(t, one, int64(1000))
+	cache.Set("b", make([]byte, 1000))
+	assert.Equal(t, 2*one, cache.MemoryEstimate(), "Expected equal entries to weigh the same")
+}
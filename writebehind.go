@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type WriteBehindConfig struct {
+	FlushInterval time.Duration
+	BatchSize     int
+	MaxRetries    int
+	RetryBackoff  time.Duration
+}
+
+type writeOp[V any] struct {
+	value V
+	del   bool
+}
+
+// writeBehind queues dirty keys and flushes them to the backend
+// asynchronously. Only the latest operation per key is kept.
+type writeBehind[T comparable, V any] struct {
+	backend  Backend[T, V]
+	cfg      WriteBehindConfig
+	onError  func()
+	mu       sync.Mutex
+	idle     *sync.Cond
+	dirty    map[T]writeOp[V]
+	flushing bool
+	flushMu  sync.Mutex
+	kick     chan struct{}
+}
+
+func newWriteBehind[T comparable, V any](b Backend[T, V], cfg WriteBehindConfig, onError func()) *writeBehind[T, V] {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	w := &writeBehind[T, V]{
+		backend: b,
+		cfg:     cfg,
+		onError: onError,
+		dirty:   make(map[T]writeOp[V]),
+		kick:    make(chan struct{}, 1),
+	}
+	w.idle = sync.NewCond(&w.mu)
+	return w
+}
+
+func (w *writeBehind[T, V]) enqueue(key T, op writeOp[V]) {
+	w.mu.Lock()
+	w.dirty[key] = op
+	full := len(w.dirty) >= w.cfg.BatchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *writeBehind[T, V]) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.kick:
+			w.flush()
+		case <-stop:
+			w.flush()
+			return
+		}
+	}
+}
+
+// flush writes every dirty key to the backend in batches of BatchSize and
+// returns the last error encountered after retries were exhausted.
+func (w *writeBehind[T, V]) flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	var lastErr error
+	for {
+		w.mu.Lock()
+		if len(w.dirty) == 0 {
+			w.flushing = false
+			w.idle.Broadcast()
+			w.mu.Unlock()
+			return lastErr
+		}
+		w.flushing = true
+		batch := make(map[T]writeOp[V], w.cfg.BatchSize)
+		for key, op := range w.dirty {
+			batch[key] = op
+			delete(w.dirty, key)
+			if len(batch) == w.cfg.BatchSize {
+				break
+			}
+		}
+		w.mu.Unlock()
+
+		for key, op := range batch {
+			if err := w.write(key, op); err != nil {
+				lastErr = err
+				w.onError()
+			}
+		}
+	}
+}
+
+func (w *writeBehind[T, V]) write(key T, op writeOp[V]) error {
+	var err error
+	for attempt := 0; attempt <= w.cfg.MaxRetries; attempt++ {
+		if attempt > 0 && w.cfg.RetryBackoff > 0 {
+			time.Sleep(w.cfg.RetryBackoff << (attempt - 1))
+		}
+		if op.del {
+			err = w.backend.Delete(key)
+		} else {
+			err = w.backend.Store(key, op.value)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (w *writeBehind[T, V]) wait() {
+	w.mu.Lock()
+	for len(w.dirty) > 0 || w.flushing {
+		w.idle.Wait()
+	}
+	w.mu.Unlock()
+}
+
+// Flush synchronously writes all pending write-behind operations to the
+// backend. It is a no-op unless write-behind is enabled.
+func (c *Cache[T, V]) Flush() error {
+	if c.writeBehind == nil {
+		return nil
+	}
+	return c.writeBehind.flush()
+}
+
+// Wait applies the writes queued by WithWriteBuffer and blocks until the
+// write-behind queue has been drained by the background flusher.
+func (c *Cache[T, V]) Wait() {
+	c.flushBuffer()
+	if c.writeBehind != nil {
+		c.writeBehind.wait()
+	}
+}
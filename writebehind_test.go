@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheWriteBehindFlush(t *testing.T) {
+	backend := newMapBackend[int, string]()
+	cache := NewCache[int, string](time.Minute, WithWriteBehind[int, string](backend, WriteBehindConfig{
+		FlushInterval: time.Hour,
+	}))
+	defer cache.StopCleanup()
+
+	cache.Set(1, "test1")
+	cache.Set(2, "test2")
+	cache.Delete(2)
+
+	_, ok := backend.get(1)
+	assert.False(t, ok, "Expected write to be deferred")
+
+	assert.NoError(t, cache.Flush())
+	value, ok := backend.get(1)
+	assert.True(t, ok, "Expected Flush to write pending entries")
+	assert.Equal(t, "test1", value)
+	_, ok = backend.get(2)
+	assert.False(t, ok)
+}
+
+func TestCacheWriteBehindBatch(t *testing.T) {
+	backend := newMapBackend[int, string]()
+	cache := NewCache[int, string](time.Minute, WithWriteBehind[int, string](backend, WriteBehindConfig{
+		FlushInterval: time.Hour,
+		BatchSize:     2,
+	}))
+	defer cache.StopCleanup()
+
+	cache.Set(1, "test1")
+	cache.Set(2, "test2")
+	cache.Wait()
+
+	_, ok := backend.get(2)
+	assert.True(t, ok, "Expected full batch to be flushed in the background")
+}
+
+func TestCacheWriteBehindRetry(t *testing.T) {
+	backend := newMapBackend[int, string]()
+	backend.err = errors.New("backend down")
+	cache := NewCache[int, string](time.Minute, WithWriteBehind[int, string](backend, WriteBehindConfig{
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+	}))
+	defer cache.StopCleanup()
+
+	cache.Set(1, "test1")
+	assert.ErrorIs(t, cache.Flush(), backend.err)
+	assert.Equal(t, uint64(1), cache.Stats().BackendErrors)
+
+	value, found := cache.Get(1)
+	assert.True(t, found, "Expected cache to keep the value despite backend failure")
+	assert.Equal(t, "test1", value)
+}
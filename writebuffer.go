@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type bufferedWrite[T comparable, V any] struct {
+	key      T
+	value    V
+	ttl      time.Duration
+	priority Priority
+	del      bool
+}
+
+type ringSlot[T comparable, V any] struct {
+	// seq tells producers and the consumer whose turn the slot is.
+	seq atomic.Uint64
+	w   bufferedWrite[T, V]
+}
+
+// writeBuffer is a bounded lock-free multi-producer queue of writes, after
+// Dmitry Vyukov's bounded queue, applied in order by a single drainer. A
+// writer that finds it full drains it itself and applies its own write
+// synchronously, so a goroutine's writes are always applied in order.
+type writeBuffer[T comparable, V any] struct {
+	slots []ringSlot[T, V]
+	mask  uint64
+	head  atomic.Uint64
+	// tail is only touched with drainMu held.
+	tail uint64
+	// applied counts the queued writes applied so far, so that idle can
+	// tell without taking drainMu that nothing is left to apply.
+	applied atomic.Uint64
+	drainMu sync.Mutex
+	apply   func(bufferedWrite[T, V])
+	wake    chan struct{}
+}
+
+func newWriteBuffer[T comparable, V any](size int) *writeBuffer[T, V] {
+	n := 2
+	for n < size {
+		n <<= 1
+	}
+	b := &writeBuffer[T, V]{
+		slots: make([]ringSlot[T, V], n),
+		mask:  uint64(n - 1),
+		wake:  make(chan struct{}, 1),
+	}
+	for i := range b.slots {
+		b.slots[i].seq.Store(uint64(i))
+	}
+	return b
+}
+
+// put queues w, or applies it synchronously once everything queued before
+// it when the buffer is full.
+func (b *writeBuffer[T, V]) put(w bufferedWrite[T, V]) {
+	if b.push(w) {
+		select {
+		case b.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+	b.drainMu.Lock()
+	defer b.drainMu.Unlock()
+	b.drainLocked()
+	b.apply(w)
+}
+
+func (b *writeBuffer[T, V]) push(w bufferedWrite[T, V]) bool {
+	pos := b.head.Load()
+	for {
+		slot := &b.slots[pos&b.mask]
+		switch seq := slot.seq.Load(); {
+		case seq == pos:
+			if b.head.CompareAndSwap(pos, pos+1) {
+				slot.w = w
+				slot.seq.Store(pos + 1)
+				return true
+			}
+			pos = b.head.Load()
+		case seq < pos:
+			return false
+		default:
+			pos = b.head.Load()
+		}
+	}
+}
+
+// drain applies every published write.
+func (b *writeBuffer[T, V]) drain() {
+	b.drainMu.Lock()
+	b.drainLocked()
+	b.drainMu.Unlock()
+}
+
+func (b *writeBuffer[T, V]) drainLocked() {
+	for {
+		slot := &b.slots[b.tail&b.mask]
+		if slot.seq.Load() != b.tail+1 {
+			return
+		}
+		w := slot.w
+		slot.w = bufferedWrite[T, V]{}
+		slot.seq.Store(b.tail + b.mask + 1)
+		b.tail++
+		b.apply(w)
+		b.applied.Add(1)
+	}
+}
+
+// idle reports whether every write queued so far has been applied.
+func (b *writeBuffer[T, V]) idle() bool {
+	return b.applied.Load() == b.head.Load()
+}
+
+func (b *writeBuffer[T, V]) run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-b.wake:
+			b.drain()
+		case <-stop:
+			b.drain()
+			return
+		}
+	}
+}
+
+// buffered queues a write if the write buffer is enabled. Writes through to
+// a backend are never buffered, so that their errors can be reported, nor
+// are writes after StopCleanup, which nothing would drain.
+func (c *Cache[T, V]) buffered(w bufferedWrite[T, V]) bool {
+	if c.buffer == nil || c.writeThrough {
+		return false
+	}
+	select {
+	case <-c.stopCleanup:
+		return false
+	default:
+	}
+	c.buffer.put(w)
+	return true
+}
+
+func (c *Cache[T, V]) applyBuffered(w bufferedWrite[T, V]) {
+	if w.del {
+		_ = c.applyDelete(w.key, nil)
+	} else {
+		_ = c.applySet(w.key, w.value, w.ttl, w.priority, nil)
+	}
+}
+
+// flushBuffer applies every buffered write.
+func (c *Cache[T, V]) flushBuffer() {
+	if c.buffer != nil && !c.buffer.idle() {
+		c.buffer.drain()
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheWriteBuffer(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithWriteBuffer[string, int](4))
+	defer cache.StopCleanup()
+
+	for i := range 1000 {
+		cache.Set("k", i)
+	}
+	cache.Wait()
+	value, _ := cache.Get("k")
+	assert.Equal(t, 999, value, "Expected writes of one goroutine to be applied in order")
+
+	cache.Set("d", 1)
+	cache.Delete("d")
+	cache.Wait()
+	_, found := cache.Get("d")
+	assert.False(t, found)
+
+	cache.Set("c", 1)
+	cache.Clear()
+	cache.Wait()
+	_, found = cache.Get("c")
+	assert.False(t, found, "Expected Clear to apply pending writes first")
+}
+
+func TestCacheWriteBufferConcurrent(t *testing.T) {
+	cache := NewCache[int, int](time.Minute, WithWriteBuffer[int, int](64))
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				cache.Set(g*1000+i, i)
+			}
+		}()
+	}
+	wg.Wait()
+	cache.StopCleanup()
+	assert.Len(t, cache.Snapshot(), 8000, "Expected StopCleanup to apply every write")
+}
+
+func TestCacheWriteBufferWriteThrough(t *testing.T) {
+	backend := newMapBackend[string, int]()
+	cache := NewCache[string, int](time.Minute, WithWriteBuffer[string, int](4), WithWriteThrough[string, int](backend))
+	defer cache.StopCleanup()
+
+	cache.Set("k", 1)
+	_, found := cache.Get("k")
+	assert.True(t, found, "Expected write-through caches to apply writes immediately")
+}
+
+func BenchmarkCacheSetWriteBuffer(b *testing.B) {
+	cache := NewCache[int, int](time.Minute, WithWriteBuffer[int, int](1<<12))
+	defer cache.StopCleanup()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			cache.Set(i&1023, i)
+			i++
+		}
+	})
+}
+
+func TestCacheWriteBufferVersions(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, WithWriteBuffer[string, int](64))
+	defer cache.StopCleanup()
+
+	cache.Set("k", 1)
+	assert.False(t, cache.SetIfVersion("k", 2, 0), "Expected the pending write to be applied before the version check")
+	cache.Wait()
+	_, version, _ := cache.GetVersioned("k")
+	assert.True(t, cache.SetIfVersion("k", 2, version))
+	value, _, _ := cache.GetVersioned("k")
+	assert.Equal(t, 2, value, "Expected SetIfVersion to bypass the buffer")
+
+	err := cache.Txn(func(tx *Tx[string, int]) error {
+		v, _ := tx.Get("k")
+		cache.Set("k", 10)
+		tx.Set("k", v+1)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrTxnConflict, "Expected a buffered write to conflict with the transaction")
+
+	assert.NoError(t, cache.Txn(func(tx *Tx[string, int]) error {
+		v, _ := tx.Get("k")
+		tx.Set("k", v+1)
+		return nil
+	}))
+	value, _, _ = cache.GetVersioned("k")
+	assert.Equal(t, 11, value, "Expected the commit to be visible without Wait")
+}